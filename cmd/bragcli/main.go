@@ -0,0 +1,12 @@
+// Command bragcli is a command-line client for Bragnet.
+package main
+
+import (
+	"os"
+
+	"github.com/janitrai/bragcli/internal/cmd"
+)
+
+func main() {
+	os.Exit(cmd.Execute())
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// DefaultMaxResponseSize caps how much of a successful response body is
+	// decoded. Large inboxes and feed pages run to a few MiB.
+	DefaultMaxResponseSize int64 = 32 << 20
+
+	// maxErrorBody caps how much of a failed response is kept for the
+	// error message and classification.
+	maxErrorBody = 64 << 10
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the
+// client's MaxResponseSize.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// WithMaxResponseSize sets the largest response body the client will
+// decode, in bytes. n <= 0 restores DefaultMaxResponseSize.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			n = DefaultMaxResponseSize
+		}
+		c.MaxResponseSize = n
+		return nil
+	}
+}
+
+// limitedBody reads at most limit bytes and fails with ErrResponseTooLarge
+// (instead of a silent EOF like io.LimitReader) if there is more.
+type limitedBody struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.n >= l.limit {
+		// Probe for one more byte to tell "exactly at the limit" from "over".
+		var probe [1]byte
+		if n, _ := l.r.Read(probe[:]); n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, io.EOF
+	}
+	if rem := l.limit - l.n; int64(len(p)) > rem {
+		p = p[:rem]
+	}
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	return n, err
+}
+
+// readLimited reads all of r, failing with ErrResponseTooLarge if it
+// holds more than limit bytes.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	b, err := io.ReadAll(&limitedBody{r: r, limit: limit})
+	if errors.Is(err, ErrResponseTooLarge) {
+		return nil, fmt.Errorf("%w: body exceeds %d bytes", ErrResponseTooLarge, limit)
+	}
+	return b, err
+}
+
+// decodeBody streams JSON from r into out without buffering the raw body.
+// An empty body leaves out untouched.
+func decodeBody(r *limitedBody, out any) error {
+	err := json.NewDecoder(r).Decode(out)
+	switch {
+	case err == nil, errors.Is(err, io.EOF) && r.n == 0:
+		return nil
+	case errors.Is(err, ErrResponseTooLarge):
+		return fmt.Errorf("%w: body exceeds %d bytes", ErrResponseTooLarge, r.limit)
+	}
+	return fmt.Errorf("decode response json: %w", err)
+}
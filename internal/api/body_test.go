@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+func newSizeTestClient(t *testing.T, body string, limit int64) *Client {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(ts.Close)
+	c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"},
+		WithBaseURL(ts.URL+"/voyager/api"), WithMaxResponseSize(limit))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+func TestClientDo_ResponseTooLarge(t *testing.T) {
+	body := `{"included":[` + strings.Repeat(`{"a":1},`, 100) + `{"a":1}]}`
+	c := newSizeTestClient(t, body, 64)
+
+	var out map[string]any
+	err := c.Do(context.Background(), http.MethodGet, "/feed", nil, nil, &out)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("err = %v, want ErrResponseTooLarge", err)
+	}
+	if !strings.Contains(err.Error(), "64 bytes") {
+		t.Errorf("error should mention the limit: %v", err)
+	}
+}
+
+func TestClientDo_RecordersRespectLimit(t *testing.T) {
+	body := `{"included":[` + strings.Repeat(`{"a":1},`, 100) + `{"a":1}]}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(ts.Close)
+
+	for name, opt := range map[string]Option{
+		"record": WithRecorder(t.TempDir()),
+		"har":    WithHAR(filepath.Join(t.TempDir(), "out.har"), false),
+	} {
+		c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"},
+			WithBaseURL(ts.URL+"/voyager/api"), WithMaxResponseSize(64), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out map[string]any
+		if err := c.Do(context.Background(), http.MethodGet, "/feed", nil, nil, &out); !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("%s: err = %v, want ErrResponseTooLarge", name, err)
+		}
+	}
+}
+
+func TestClientDo_ResponseExactlyAtLimit(t *testing.T) {
+	body := `{"ok":true}`
+	c := newSizeTestClient(t, body, int64(len(body)))
+
+	var out map[string]any
+	if err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, &out); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if out["ok"] != true {
+		t.Fatalf("out = %v", out)
+	}
+}
+
+func TestClientDo_EmptyBody(t *testing.T) {
+	c := newSizeTestClient(t, "", 0)
+
+	out := map[string]any{"untouched": true}
+	if err := c.Do(context.Background(), http.MethodPost, "/follow", nil, nil, &out); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if out["untouched"] != true {
+		t.Fatalf("out = %v", out)
+	}
+}
+
+func TestClientDo_MalformedJSON(t *testing.T) {
+	c := newSizeTestClient(t, `{"included":[`, 0)
+
+	var out map[string]any
+	err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, &out)
+	if err == nil || errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("err = %v, want a decode error", err)
+	}
+	if !strings.Contains(err.Error(), "decode response json") {
+		t.Errorf("err = %v", err)
+	}
+}
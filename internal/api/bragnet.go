@@ -7,21 +7,24 @@ import (
 	"strings"
 
 	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/restli"
+	"github.com/janitrai/bragcli/internal/urn"
 )
 
 // DefaultSearchQueryID is the default GraphQL query ID for search clusters.
-// Bragnet rotates these periodically; update via config if search returns 500.
+// Bragnet rotates these periodically; newer ones found by DiscoverQueryIDs
+// are tried first (see QueryRegistry).
 const DefaultSearchQueryID = "voyagerSearchDashClusters.ef3d0937fb65bd7812e32e5a85028e79"
 
 type Bragnet struct {
-	c                    *Client
-	SearchQueryID        string
-	ConversationsQueryID string
-	MessagesQueryID      string
+	c *Client
+
+	// Queries holds the GraphQL queryIds to use, with fallbacks.
+	Queries *QueryRegistry
 }
 
 func NewBragnet(c *Client) *Bragnet {
-	return &Bragnet{c: c}
+	return &Bragnet{c: c, Queries: NewQueryRegistry()}
 }
 
 type Me struct {
@@ -45,38 +48,53 @@ func (bn *Bragnet) GetMe(ctx context.Context) (Me, error) {
 	// The /me response uses Bragnet's normalized format:
 	//   data.*miniProfile → URN reference
 	//   included[] → array of resolved entities (miniProfile lives here)
+	n := NewNormalized(raw)
 	mini := findMiniProfile(raw)
+	if ref, _ := n.Data["*miniProfile"].(string); ref != "" {
+		if m, ok := n.Lookup(ref); ok {
+			mini = m
+		}
+	}
+	if mini == nil {
+		return Me{}, fmt.Errorf("parse /me: no miniProfile in response")
+	}
+	var mp miniProfileEntity
+	if err := n.Decode(mini, &mp); err != nil {
+		return Me{}, fmt.Errorf("parse /me: %w", err)
+	}
 
-	publicID := getString(mini, "publicIdentifier")
-	first := getString(mini, "firstName")
-	last := getString(mini, "lastName")
-	occupation := getString(mini, "occupation")
-	miniEntityURN := getString(mini, "entityUrn")
+	miniEntityURN := mp.EntityURN
 	if miniEntityURN == "" {
-		miniEntityURN = getString(mini, "dashEntityUrn")
+		miniEntityURN = mp.DashEntityURN
 	}
 
 	memberID := urnID(miniEntityURN)
 	if memberID == "" {
 		// Try objectUrn: "urn:li:member:123"
-		memberID = urnID(getString(mini, "objectUrn"))
+		memberID = urnID(mp.ObjectURN)
 	}
 	memberURN := ""
 	if memberID != "" {
-		memberURN = "urn:li:member:" + memberID
+		memberURN = urn.Member(memberID).String()
 	}
 
 	// Prefer dashEntityUrn (urn:li:fsd_profile:…) for messaging and other dash APIs.
-	profileURN := getString(mini, "dashEntityUrn")
-	if profileURN == "" && strings.Contains(miniEntityURN, "fsd_profile") {
-		profileURN = miniEntityURN
+	profileURN := mp.DashEntityURN
+	if profileURN == "" {
+		if _, err := urn.ParseAs(miniEntityURN, urn.TypeProfile); err == nil {
+			profileURN = miniEntityURN
+		}
+	}
+
+	if err := bn.checkDrift("/me", n); err != nil {
+		return Me{}, err
 	}
 
 	return Me{
-		PublicIdentifier:     publicID,
-		FirstName:            first,
-		LastName:             last,
-		Occupation:           occupation,
+		PublicIdentifier:     mp.PublicIdentifier,
+		FirstName:            mp.FirstName,
+		LastName:             mp.LastName,
+		Occupation:           mp.Occupation,
 		MiniProfileEntityURN: miniEntityURN,
 		ProfileURN:           profileURN,
 		MemberID:             memberID,
@@ -84,6 +102,31 @@ func (bn *Bragnet) GetMe(ctx context.Context) (Me, error) {
 	}, nil
 }
 
+// miniProfileEntity is the miniProfile returned by /me.
+type miniProfileEntity struct {
+	EntityURN        string `json:"entityUrn"`
+	DashEntityURN    string `json:"dashEntityUrn"`
+	ObjectURN        string `json:"objectUrn"`
+	PublicIdentifier string `json:"publicIdentifier" li:"required"`
+	FirstName        string `json:"firstName" li:"expected"`
+	LastName         string `json:"lastName" li:"expected"`
+	Occupation       string `json:"occupation"`
+}
+
+// profileEntity is com.linkedin.voyager.dash.identity.profile.Profile.
+type profileEntity struct {
+	EntityURN        string `json:"entityUrn"`
+	DashEntityURN    string `json:"dashEntityUrn"`
+	ObjectURN        string `json:"objectUrn"`
+	PublicIdentifier string `json:"publicIdentifier" li:"required"`
+	FirstName        string `json:"firstName" li:"expected"`
+	LastName         string `json:"lastName" li:"expected"`
+	Headline         string `json:"headline" li:"expected"`
+	Summary          string `json:"summary"`
+	GeoLocationName  string `json:"geoLocationName"`
+	LocationName     string `json:"locationName"`
+}
+
 // findMiniProfile extracts the miniProfile object from Bragnet's normalized response.
 // It checks included[] first (normalized format), then falls back to nested paths.
 func findMiniProfile(raw map[string]any) map[string]any {
@@ -173,36 +216,42 @@ func (bn *Bragnet) GetProfile(ctx context.Context, publicIdentifierOrURN string)
 
 	// The dash API returns a normalized response with profile data in included[]
 	prof := findProfileInIncluded(raw)
+	if prof == nil {
+		return Profile{}, fmt.Errorf("parse profile %q: no profile in response", id)
+	}
+	n := NewNormalized(raw)
+	var pe profileEntity
+	if err := n.Decode(prof, &pe); err != nil {
+		return Profile{}, fmt.Errorf("parse profile %q: %w", id, err)
+	}
+	if err := bn.checkDrift("/identity/dash/profiles", n); err != nil {
+		return Profile{}, err
+	}
 
-	profilePublicID := getString(prof, "publicIdentifier")
-	first := getString(prof, "firstName")
-	last := getString(prof, "lastName")
-	headline := getString(prof, "headline")
-	summary := getString(prof, "summary")
-	location := getString(prof, "geoLocationName")
+	location := pe.GeoLocationName
 	if location == "" {
-		location = getString(prof, "locationName")
+		location = pe.LocationName
 	}
 
-	entityURN := getString(prof, "entityUrn")
+	entityURN := pe.EntityURN
 	if entityURN == "" {
-		entityURN = getString(prof, "dashEntityUrn")
+		entityURN = pe.DashEntityURN
 	}
 	memberID := urnID(entityURN)
 	if memberID == "" {
-		memberID = urnID(getString(prof, "objectUrn"))
+		memberID = urnID(pe.ObjectURN)
 	}
 	memberURN := ""
 	if memberID != "" {
-		memberURN = "urn:li:member:" + memberID
+		memberURN = urn.Member(memberID).String()
 	}
 
 	return Profile{
-		PublicIdentifier:     profilePublicID,
-		FirstName:            first,
-		LastName:             last,
-		Headline:             headline,
-		Summary:              summary,
+		PublicIdentifier:     pe.PublicIdentifier,
+		FirstName:            pe.FirstName,
+		LastName:             pe.LastName,
+		Headline:             pe.Headline,
+		Summary:              pe.Summary,
 		LocationName:         location,
 		MiniProfileEntityURN: entityURN,
 		MemberID:             memberID,
@@ -321,6 +370,74 @@ func (bn *Bragnet) ListProfilePosts(ctx context.Context, profileURN string, star
 	return out, nil
 }
 
+// entityResultEntity is a search EntityResultViewModel.
+type entityResultEntity struct {
+	EntityURN         string        `json:"entityUrn"`
+	Title             TextViewModel `json:"title" li:"expected"`
+	PrimarySubtitle   TextViewModel `json:"primarySubtitle"`
+	SecondarySubtitle TextViewModel `json:"secondarySubtitle"`
+	NavigationURL     string        `json:"navigationUrl" li:"expected"`
+}
+
+// searchPageSize is how many results the search GraphQL endpoint returns
+// per page, regardless of the count we ask for.
+const searchPageSize = 10
+
+// PostsPager pages through a profile's posts.
+func (bn *Bragnet) PostsPager(profileURN string, pageSize int) *Pager[FeedUpdate] {
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	return NewOffsetPager(pageSize, func(ctx context.Context, start, count int) ([]FeedUpdate, int, error) {
+		posts, err := bn.ListProfilePosts(ctx, profileURN, start, count)
+		return posts, len(posts), err
+	})
+}
+
+// SearchPeoplePager pages through people search results.
+func (bn *Bragnet) SearchPeoplePager(keywords string) *Pager[SearchItem] {
+	return NewOffsetPager(searchPageSize, func(ctx context.Context, start, count int) ([]SearchItem, int, error) {
+		return bn.searchGraphQL(ctx, keywords, "PEOPLE", start, count)
+	})
+}
+
+// SearchJobsPager pages through job search results.
+func (bn *Bragnet) SearchJobsPager(keywords string) *Pager[SearchItem] {
+	return NewOffsetPager(searchPageSize, func(ctx context.Context, start, count int) ([]SearchItem, int, error) {
+		return bn.searchGraphQL(ctx, keywords, "JOBS", start, count)
+	})
+}
+
+// searchVariables are the GraphQL variables of voyagerSearchDashClusters.
+type searchVariables struct {
+	Start  int         `restli:"start"`
+	Origin string      `restli:"origin"`
+	Query  searchQuery `restli:"query"`
+}
+
+type searchQuery struct {
+	Keywords                 string                 `restli:"keywords"`
+	FlagshipSearchIntent     string                 `restli:"flagshipSearchIntent"`
+	QueryParameters          []searchQueryParameter `restli:"queryParameters"`
+	IncludeFiltersInResponse bool                   `restli:"includeFiltersInResponse"`
+}
+
+type searchQueryParameter struct {
+	Key   string   `restli:"key"`
+	Value []string `restli:"value"`
+}
+
+// graphQLQuery builds the raw query string for a Voyager GraphQL call. The
+// variables are Rest.li-encoded, so the result must be sent with DoRaw and
+// not escaped again.
+func graphQLQuery(queryID string, variables any) (string, error) {
+	vars, err := restli.Marshal(variables)
+	if err != nil {
+		return "", fmt.Errorf("encode graphql variables: %w", err)
+	}
+	return "variables=" + vars + "&queryId=" + queryID, nil
+}
+
 type SearchItem struct {
 	PublicIdentifier  string
 	Title             string
@@ -329,24 +446,21 @@ type SearchItem struct {
 	TargetURN         string
 }
 
-func (bn *Bragnet) searchQueryID() string {
-	if bn.SearchQueryID != "" {
-		return bn.SearchQueryID
-	}
-	return DefaultSearchQueryID
-}
-
 func (bn *Bragnet) SearchPeople(ctx context.Context, keywords string, start, count int) ([]SearchItem, error) {
-	return bn.searchGraphQL(ctx, keywords, "PEOPLE", start, count)
+	items, _, err := bn.searchGraphQL(ctx, keywords, "PEOPLE", start, count)
+	return items, err
 }
 
 func (bn *Bragnet) SearchJobs(ctx context.Context, keywords string, start, count int) ([]SearchItem, error) {
-	return bn.searchGraphQL(ctx, keywords, "JOBS", start, count)
+	items, _, err := bn.searchGraphQL(ctx, keywords, "JOBS", start, count)
+	return items, err
 }
 
-func (bn *Bragnet) searchGraphQL(ctx context.Context, keywords string, resultType string, start, count int) ([]SearchItem, error) {
+// searchGraphQL returns a page of search results and how many results
+// the page held, including any that couldn't be decoded.
+func (bn *Bragnet) searchGraphQL(ctx context.Context, keywords string, resultType string, start, count int) ([]SearchItem, int, error) {
 	if strings.TrimSpace(keywords) == "" {
-		return nil, fmt.Errorf("empty query")
+		return nil, 0, fmt.Errorf("empty query")
 	}
 	if count <= 0 {
 		count = 10
@@ -355,58 +469,67 @@ func (bn *Bragnet) searchGraphQL(ctx context.Context, keywords string, resultTyp
 		start = 0
 	}
 
-	// Build the Bragnet-style variables tuple.
-	// Bragnet uses a custom tuple syntax that must NOT be percent-encoded for parens/commas/colons.
-	// Only the keywords value needs %20 encoding for spaces.
-	escapedKW := strings.ReplaceAll(url.PathEscape(keywords), "+", "%20")
-	variables := fmt.Sprintf(
-		"(start:%d,origin:OTHER,query:(keywords:%s,flagshipSearchIntent:SEARCH_SRP,queryParameters:List((key:resultType,value:List(%s))),includeFiltersInResponse:false))",
-		start, escapedKW, resultType,
-	)
-
-	// Build the raw query string manually to avoid double-encoding the tuple syntax
-	rawQuery := fmt.Sprintf("includeWebMetadata=true&variables=%s&queryId=%s",
-		variables, bn.searchQueryID())
+	vars := searchVariables{
+		Start:  start,
+		Origin: "OTHER",
+		Query: searchQuery{
+			Keywords:             keywords,
+			FlagshipSearchIntent: "SEARCH_SRP",
+			QueryParameters:      []searchQueryParameter{{Key: "resultType", Value: []string{resultType}}},
+		},
+	}
 
 	var raw map[string]any
-	if err := bn.c.DoRaw(ctx, "GET", "/graphql", rawQuery, nil, &raw); err != nil {
-		return nil, err
+	if err := bn.graphQL(ctx, QuerySearchClusters, "/graphql", "includeWebMetadata=true&", vars, &raw); err != nil {
+		return nil, 0, err
 	}
 
 	// Results are in included[] as EntityResultViewModel objects
-	included, _ := raw["included"].([]any)
-	var items []SearchItem
-	for _, el := range included {
-		m, ok := el.(map[string]any)
-		if !ok {
-			continue
-		}
+	n := NewNormalized(raw)
+	var (
+		items   []SearchItem
+		results int
+	)
+	for _, m := range n.Included {
 		t, _ := m["$type"].(string)
 		if !strings.Contains(t, "EntityResultViewModel") {
 			continue
 		}
-
-		title := getNestedText(m, "title")
-		primary := getNestedText(m, "primarySubtitle")
-		secondary := getNestedText(m, "secondarySubtitle")
-		targetURN, _ := m["entityUrn"].(string)
+		results++
+		var er entityResultEntity
+		if err := n.Decode(m, &er); err != nil {
+			continue
+		}
 
 		// Try to extract publicIdentifier from the navigation URL
 		publicID := ""
-		if navURL := getString(m, "navigationUrl"); navURL != "" {
-			publicID = auth.NormalizePublicIdentifier(navURL)
+		if er.NavigationURL != "" {
+			publicID = auth.NormalizePublicIdentifier(er.NavigationURL)
 		}
 
 		items = append(items, SearchItem{
 			PublicIdentifier:  publicID,
-			Title:             title,
-			PrimarySubtitle:   primary,
-			SecondarySubtitle: secondary,
-			TargetURN:         targetURN,
+			Title:             er.Title.Text,
+			PrimarySubtitle:   er.PrimarySubtitle.Text,
+			SecondarySubtitle: er.SecondarySubtitle.Text,
+			TargetURN:         er.EntityURN,
 		})
 	}
 
-	return items, nil
+	if err := bn.checkDrift(string(QuerySearchClusters), n); err != nil {
+		return nil, 0, err
+	}
+	// The paging total, when sent, also covers results that weren't
+	// included as EntityResultViewModels.
+	data := n.Data
+	if inner, ok := data["data"].(map[string]any); ok {
+		data = inner // the live API nests the GraphQL data one level down
+	}
+	clusters, _ := data["searchDashClustersByAll"].(map[string]any)
+	if paging, ok := clusters["paging"].(map[string]any); ok && paging["total"] != nil {
+		results = max(0, min(searchPageSize, int(getInt64(paging, "total"))-start))
+	}
+	return items, results, nil
 }
 
 // getNestedText extracts .text from a field that may be a string or {text: "..."} object.
@@ -431,16 +554,15 @@ func (bn *Bragnet) Follow(ctx context.Context, memberURN string) error {
 	if memberURN == "" {
 		return fmt.Errorf("empty member urn")
 	}
-	if !strings.HasPrefix(memberURN, "urn:li:member:") {
-		return fmt.Errorf("unexpected member urn: %q", memberURN)
+	member, err := urn.ParseAs(memberURN, urn.TypeMember)
+	if err != nil {
+		return fmt.Errorf("unexpected member urn: %w", err)
 	}
-
-	memberID := urnID(memberURN)
-	if memberID == "" {
-		return fmt.Errorf("cannot extract member ID from %q", memberURN)
+	followingInfo, err := urn.ToFollowingInfo(member)
+	if err != nil {
+		return err
 	}
-	followingInfoURN := "urn:li:fs_followingInfo:" + memberID
-	payload := map[string]any{"urn": followingInfoURN}
+	payload := map[string]any{"urn": followingInfo.String()}
 
 	q := url.Values{}
 	q.Set("action", "followByEntityUrn")
@@ -452,8 +574,8 @@ func (bn *Bragnet) Connect(ctx context.Context, profileURN string, note string)
 	if profileURN == "" {
 		return fmt.Errorf("empty profile URN")
 	}
-	if !strings.Contains(profileURN, "fsd_profile") {
-		return fmt.Errorf("expected fsd_profile URN, got: %q", profileURN)
+	if _, err := urn.ParseAs(profileURN, urn.TypeProfile); err != nil {
+		return fmt.Errorf("expected fsd_profile URN: %w", err)
 	}
 
 	payload := map[string]any{
@@ -3,12 +3,16 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/urn"
 )
 
 // ---------------------------------------------------------------------------
@@ -799,6 +803,43 @@ func TestSearchPeople_EmptyQuery(t *testing.T) {
 	}
 }
 
+func TestSearchPeoplePager_PagesByServerTotal(t *testing.T) {
+	// Of the first page's 10 results, only 8 come back as
+	// EntityResultViewModels; the paging total says there are 13.
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		start, shown := 10, 3
+		if strings.Contains(r.URL.RawQuery, "start:0") {
+			start, shown = 0, 8
+		}
+		var included []string
+		for i := 0; i < shown; i++ {
+			included = append(included, fmt.Sprintf(`{"$type": "com.linkedin.voyager.dash.search.EntityResultViewModel",
+				"entityUrn": "urn:li:fsd_profile:P%d", "title": {"text": "Person %d"}, "navigationUrl": "https://www.linkedin.com/in/p%d"}`, start+i, start+i, start+i))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": {"data": {"searchDashClustersByAll": {"paging": {"start": %d, "count": 10, "total": 13}}}}, "included": [%s]}`,
+			start, strings.Join(included, ","))
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"}, WithBaseURL(ts.URL+"/voyager/api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := NewBragnet(c).SearchPeoplePager("person").All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 11 || len(queries) != 2 {
+		t.Fatalf("got %d items in %d requests, want 11 in 2", len(items), len(queries))
+	}
+	if !strings.Contains(queries[1], "start:10") {
+		t.Errorf("second page query = %q, want start:10", queries[1])
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ListProfilePosts (with mock HTTP server)
 // ---------------------------------------------------------------------------
@@ -1059,3 +1100,23 @@ func TestFixtures_ValidJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteActions_RejectMalformedURNs(t *testing.T) {
+	c, _ := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"})
+	bn := NewBragnet(c)
+	ctx := context.Background()
+
+	checks := map[string]error{
+		"Follow profile URN":   bn.Follow(ctx, "urn:li:fsd_profile:AAA"),
+		"Connect member URN":   bn.Connect(ctx, "urn:li:member:123", ""),
+		"Connect tuple URN":    bn.Connect(ctx, "urn:li:fsd_profile:(AAA,BBB)", ""),
+		"Send bad mailbox":     bn.SendMessage(ctx, "urn:li:member:1", "urn:li:msg_conversation:(urn:li:fsd_profile:AAA,t)", "hi"),
+		"Send bad convo":       bn.SendMessage(ctx, "urn:li:fsd_profile:AAA", "urn:li:msg_conversation:t", "hi"),
+		"Create bad recipient": bn.CreateConversationWithMessage(ctx, "urn:li:fsd_profile:AAA", []string{"jane-smith"}, "hi"),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, urn.ErrInvalid) {
+			t.Errorf("%s: err = %v, want urn.ErrInvalid", name, err)
+		}
+	}
+}
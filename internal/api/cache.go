@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTLs are how long successful GET responses are reused, per
+// API path. Paths not listed are never cached. Identity data changes
+// rarely, and commands like `message send` look it up on every run.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"/me":                     24 * time.Hour,
+	"/identity/dash/profiles": 6 * time.Hour,
+}
+
+// Cache stores raw JSON response bodies on disk, one file per request.
+// Entries are keyed by the full URL and the session, so two accounts never
+// see each other's /me.
+type Cache struct {
+	Dir string
+
+	// TTLs maps API paths (as passed to Do, with a leading slash) to how
+	// long their responses stay fresh. A path not in the map, or with a
+	// TTL <= 0, is not cached.
+	TTLs map[string]time.Duration
+
+	// Refresh skips reading entries but still stores new responses, so a
+	// run can bring the cache up to date.
+	Refresh bool
+
+	now func() time.Time
+}
+
+// NewCache returns a cache in dir using ttls (DefaultCacheTTLs if nil).
+func NewCache(dir string, ttls map[string]time.Duration) *Cache {
+	if ttls == nil {
+		ttls = DefaultCacheTTLs
+	}
+	return &Cache{Dir: dir, TTLs: ttls, now: time.Now}
+}
+
+// WithCache makes the client answer cacheable GETs from cache. Hits don't
+// touch the network or the rate limiter.
+func WithCache(cache *Cache) Option {
+	return func(c *Client) error {
+		c.cache = cache
+		return nil
+	}
+}
+
+// cacheEntry is the on-disk format of one cached response.
+type cacheEntry struct {
+	URL      string          `json:"url"`
+	StoredAt time.Time       `json:"stored_at"`
+	Body     json.RawMessage `json:"body"`
+}
+
+const cacheExt = ".json"
+
+// ttl returns the TTL for path, or 0 if it isn't cached.
+func (c *Cache) ttl(path string) time.Duration {
+	return c.TTLs["/"+strings.TrimPrefix(path, "/")]
+}
+
+func (c *Cache) file(rawURL, liAt string) string {
+	h := sha256.New()
+	io.WriteString(h, liAt)
+	h.Write([]byte{0})
+	io.WriteString(h, rawURL)
+	return filepath.Join(c.Dir, hex.EncodeToString(h.Sum(nil))+cacheExt)
+}
+
+// get returns the body stored for file if it is younger than ttl.
+func (c *Cache) get(file string, ttl time.Duration) ([]byte, bool) {
+	if c.Refresh {
+		return nil, false
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, false
+	}
+	if c.now().Sub(e.StoredAt) >= ttl {
+		return nil, false
+	}
+	return e.Body, true
+}
+
+// put stores body for rawURL, atomically.
+func (c *Cache) put(file, rawURL string, body []byte) error {
+	if !json.Valid(body) {
+		return fmt.Errorf("cache %s: body is not JSON", rawURL)
+	}
+	b, err := json.Marshal(cacheEntry{URL: rawURL, StoredAt: c.now().UTC(), Body: body})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create cache entry: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// Clear deletes every cached response and returns how many there were.
+// A missing cache dir is not an error.
+func (c *Cache) Clear() (int, error) {
+	entries, err := os.ReadDir(c.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read cache dir: %w", err)
+	}
+	n := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), cacheExt) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.Dir, e.Name())); err != nil {
+			return n, fmt.Errorf("clear cache: %w", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// readForCache reads a successful response body in full so it can be both
+// stored and decoded.
+func readForCache(rb *limitedBody) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, rb); err != nil {
+		if errors.Is(err, ErrResponseTooLarge) {
+			return nil, fmt.Errorf("%w: body exceeds %d bytes", ErrResponseTooLarge, rb.limit)
+		}
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return buf.Bytes(), nil
+}
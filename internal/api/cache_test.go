@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+// newCacheTestClient returns a client with a cache in a temp dir, a clock
+// the test controls and a server that counts requests.
+func newCacheTestClient(t *testing.T, liAt string, status int, hits *atomic.Int32, now *time.Time) (*Client, *Cache) {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, `{"n":`+strconv.Itoa(int(n))+`}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	cache := NewCache(t.TempDir(), map[string]time.Duration{"/me": time.Hour})
+	cache.now = func() time.Time { return *now }
+	c, err := NewClient(auth.Cookies{LiAt: liAt, JSessionID: "ajax:y"},
+		WithBaseURL(ts.URL+"/voyager/api"), WithCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, cache
+}
+
+func getN(t *testing.T, c *Client, path string) int {
+	t.Helper()
+	var out struct{ N int }
+	if err := c.Do(context.Background(), http.MethodGet, path, nil, nil, &out); err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	return out.N
+}
+
+func TestCache_HitAndExpiry(t *testing.T) {
+	var hits atomic.Int32
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, _ := newCacheTestClient(t, "a", http.StatusOK, &hits, &now)
+
+	if n := getN(t, c, "/me"); n != 1 {
+		t.Fatalf("first GET = %d, want 1", n)
+	}
+	now = now.Add(59 * time.Minute)
+	if n := getN(t, c, "/me"); n != 1 {
+		t.Fatalf("within TTL = %d, want cached 1", n)
+	}
+	now = now.Add(time.Minute)
+	if n := getN(t, c, "/me"); n != 2 {
+		t.Fatalf("after TTL = %d, want fresh 2", n)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("server hits = %d, want 2", hits.Load())
+	}
+}
+
+func TestCache_OnlyConfiguredPaths(t *testing.T) {
+	var hits atomic.Int32
+	now := time.Now()
+	c, _ := newCacheTestClient(t, "a", http.StatusOK, &hits, &now)
+
+	getN(t, c, "/feed/dash/updates")
+	getN(t, c, "/feed/dash/updates")
+	if hits.Load() != 2 {
+		t.Errorf("uncached path: server hits = %d, want 2", hits.Load())
+	}
+}
+
+func TestCache_Refresh(t *testing.T) {
+	var hits atomic.Int32
+	now := time.Now()
+	c, cache := newCacheTestClient(t, "a", http.StatusOK, &hits, &now)
+
+	getN(t, c, "/me")
+	cache.Refresh = true
+	if n := getN(t, c, "/me"); n != 2 {
+		t.Fatalf("refresh GET = %d, want 2", n)
+	}
+	cache.Refresh = false
+	if n := getN(t, c, "/me"); n != 2 {
+		t.Fatalf("after refresh = %d, want the refreshed entry (2)", n)
+	}
+}
+
+func TestCache_KeyedBySession(t *testing.T) {
+	var hits atomic.Int32
+	now := time.Now()
+	c, _ := newCacheTestClient(t, "account-a", http.StatusOK, &hits, &now)
+
+	getN(t, c, "/me")
+	c.Cookies.LiAt = "account-b"
+	if n := getN(t, c, "/me"); n != 2 {
+		t.Fatalf("other session got %d, want its own response", n)
+	}
+}
+
+func TestCache_ErrorsNotStored(t *testing.T) {
+	var hits atomic.Int32
+	now := time.Now()
+	c, cache := newCacheTestClient(t, "a", http.StatusNotFound, &hits, &now)
+
+	for i := 0; i < 2; i++ {
+		if err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, &struct{}{}); err == nil {
+			t.Fatal("expected 404 error")
+		}
+	}
+	if hits.Load() != 2 {
+		t.Errorf("server hits = %d, want 2", hits.Load())
+	}
+	if n, err := cache.Clear(); err != nil || n != 0 {
+		t.Errorf("Clear = %d, %v; want nothing stored", n, err)
+	}
+}
+
+func TestCache_Clear(t *testing.T) {
+	var hits atomic.Int32
+	now := time.Now()
+	c, cache := newCacheTestClient(t, "a", http.StatusOK, &hits, &now)
+
+	getN(t, c, "/me")
+	if n, err := cache.Clear(); err != nil || n != 1 {
+		t.Fatalf("Clear = %d, %v; want 1", n, err)
+	}
+	if n := getN(t, c, "/me"); n != 2 {
+		t.Fatalf("after Clear = %d, want fresh 2", n)
+	}
+
+	missing := NewCache(t.TempDir()+"/nope", nil)
+	if n, err := missing.Clear(); err != nil || n != 0 {
+		t.Errorf("Clear on missing dir = %d, %v", n, err)
+	}
+}
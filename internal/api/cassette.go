@@ -0,0 +1,243 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A cassette is a directory of numbered JSON files, one per HTTP exchange
+// (0001.json, 0002.json, …). Recording appends to the directory; replaying
+// serves the exchanges back in order without touching the network.
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders are blanked out before an exchange is written to disk.
+var sensitiveHeaders = []string{"cookie", "csrf-token", "set-cookie", "authorization"}
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// WithRecorder records every HTTP exchange into dir, with cookies and the
+// csrf-token redacted.
+func WithRecorder(dir string) Option {
+	return func(c *Client) error {
+		if dir == "" {
+			return fmt.Errorf("recorder dir is empty")
+		}
+		c.recordDir = dir
+		return nil
+	}
+}
+
+// WithReplayer serves responses from a cassette in dir instead of the
+// network. Auth cookies are not required while replaying.
+func WithReplayer(dir string) Option {
+	return func(c *Client) error {
+		rt, err := newReplayTransport(dir)
+		if err != nil {
+			return err
+		}
+		c.replay = rt
+		return nil
+	}
+}
+
+func redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range sensitiveHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(k)]; ok {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
+
+// recordTransport writes each exchange that passes through it to dir.
+type recordTransport struct {
+	next    http.RoundTripper
+	dir     string
+	maxBody int64
+
+	mu  sync.Mutex
+	seq int
+}
+
+func newRecordTransport(next http.RoundTripper, dir string, maxBody int64) (*recordTransport, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create cassette dir: %w", err)
+	}
+	existing, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recordTransport{next: next, dir: dir, maxBody: maxBody, seq: len(existing)}, nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		reqBody = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readLimited(resp.Body, t.maxBody)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redactHeader(req.Header),
+			Body:   string(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+	if err := t.write(in); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *recordTransport) write(in Interaction) error {
+	b, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal interaction: %w", err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	name := filepath.Join(t.dir, fmt.Sprintf("%04d.json", t.seq))
+	if err := os.WriteFile(name, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write interaction: %w", err)
+	}
+	return nil
+}
+
+// replayTransport serves recorded exchanges in order.
+type replayTransport struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	next         int
+}
+
+func newReplayTransport(dir string) (*replayTransport, error) {
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("cassette %s is empty", dir)
+	}
+	rt := &replayTransport{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("read cassette: %w", err)
+		}
+		var in Interaction
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Base(f), err)
+		}
+		rt.interactions = append(rt.interactions, in)
+	}
+	return rt, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.next >= len(t.interactions) {
+		return nil, fmt.Errorf("cassette exhausted: no recording for %s %s", req.Method, req.URL.Path)
+	}
+	in := t.interactions[t.next]
+	t.next++
+
+	if !strings.EqualFold(in.Request.Method, req.Method) || !samePath(in.Request.URL, req.URL.Path) {
+		return nil, fmt.Errorf("cassette mismatch at #%d: recorded %s %s, got %s %s",
+			t.next, in.Request.Method, in.Request.URL, req.Method, req.URL.Path)
+	}
+
+	header := in.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+		ContentLength: int64(len(in.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// samePath compares the path of a recorded URL with a live request path.
+// Only the path is compared: queries and bodies carry per-run tokens.
+func samePath(recordedURL, path string) bool {
+	u, err := url.Parse(recordedURL)
+	if err != nil {
+		return false
+	}
+	return u.Path == path
+}
+
+func cassetteFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "[0-9][0-9][0-9][0-9].json"))
+	if err != nil {
+		return nil, fmt.Errorf("list cassette: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
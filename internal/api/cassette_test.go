@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+func TestRecorder_WritesRedactedInteractions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "ajax:rotated"})
+		w.Header().Set("content-type", "application/json")
+		_, _ = io.WriteString(w, getMeFixture)
+	}))
+	defer ts.Close()
+
+	dir := filepath.Join(t.TempDir(), "cassette")
+	c, err := NewClient(
+		auth.Cookies{LiAt: "secret-liat", JSessionID: "ajax:secret"},
+		WithBaseURL(ts.URL+"/voyager/api"),
+		WithRecorder(dir),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := NewBragnet(c).GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe: %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "0001.json"))
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+	rec := string(b)
+	for _, secret := range []string{"secret-liat", "ajax:secret", "ajax:rotated"} {
+		if strings.Contains(rec, secret) {
+			t.Errorf("recording leaks %q", secret)
+		}
+	}
+	if !strings.Contains(rec, "/voyager/api/me") {
+		t.Errorf("recording missing request URL: %s", rec)
+	}
+}
+
+func TestReplayer_ServesRecordedResponses(t *testing.T) {
+	dir := t.TempDir()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/voyager/api/me":
+			_, _ = io.WriteString(w, getMeFixture)
+		case "/voyager/api/identity/dash/profiles":
+			_, _ = io.WriteString(w, getProfileFixture)
+		}
+	}))
+	rec, _ := NewClient(
+		auth.Cookies{LiAt: "x", JSessionID: "ajax:y"},
+		WithBaseURL(ts.URL+"/voyager/api"),
+		WithRecorder(dir),
+	)
+	want, err := NewBragnet(rec).GetMe(context.Background())
+	if err != nil {
+		t.Fatalf("GetMe (record): %v", err)
+	}
+	if _, err := NewBragnet(rec).GetProfile(context.Background(), "john-doe"); err != nil {
+		t.Fatalf("GetProfile (record): %v", err)
+	}
+	ts.Close()
+
+	// Replay needs neither the server nor cookies.
+	c, err := NewClient(auth.Cookies{}, WithReplayer(dir))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	li := NewBragnet(c)
+	got, err := li.GetMe(context.Background())
+	if err != nil {
+		t.Fatalf("GetMe (replay): %v", err)
+	}
+	if got != want {
+		t.Errorf("replayed Me = %+v, want %+v", got, want)
+	}
+	if _, err := li.GetProfile(context.Background(), "john-doe"); err != nil {
+		t.Fatalf("GetProfile (replay): %v", err)
+	}
+	if _, err := li.GetMe(context.Background()); err == nil || !strings.Contains(err.Error(), "exhausted") {
+		t.Errorf("expected exhausted cassette error, got %v", err)
+	}
+}
+
+func TestReplayer_Mismatch(t *testing.T) {
+	dir := t.TempDir()
+	rec := `{"request":{"method":"GET","url":"https://example.com/voyager/api/me"},"response":{"status_code":200,"body":"{}"}}`
+	if err := os.WriteFile(filepath.Join(dir, "0001.json"), []byte(rec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewClient(auth.Cookies{}, WithBaseURL("https://example.com/voyager/api"), WithReplayer(dir))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	err = c.Do(context.Background(), "GET", "/identity/dash/profiles", nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Errorf("expected mismatch error, got %v", err)
+	}
+}
+
+func TestWithReplayer_EmptyDir(t *testing.T) {
+	if _, err := NewClient(auth.Cookies{}, WithReplayer(t.TempDir())); err == nil {
+		t.Fatal("expected error for empty cassette")
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/janitrai/bragcli/internal/auth"
@@ -17,6 +19,11 @@ import (
 const (
 	defaultUserAgent      = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/144.0.0.0 Safari/537.36"
 	defaultAcceptLanguage = "en-US,en;q=0.9"
+
+	// expirySlack is how far li_at's expiry must move before the session
+	// counts as rotated. Bragnet slides it forward on many responses;
+	// saving each of those would rewrite the store every request.
+	expirySlack = 24 * time.Hour
 )
 
 // DefaultBaseURL returns the Voyager API base URL using the configured domain.
@@ -30,9 +37,37 @@ type Client struct {
 
 	Cookies auth.Cookies
 
-	UserAgent string
-	Debug     bool
-	DebugOut  io.Writer
+	// UserAgent and AcceptLanguage are sent with every request. They
+	// should match the browser the session came from.
+	UserAgent      string
+	AcceptLanguage string
+
+	Debug    bool
+	DebugOut io.Writer
+
+	// Retry controls automatic retries of throttled and transient failures.
+	Retry RetryPolicy
+
+	// Limiter, when set, paces requests per endpoint class.
+	Limiter Limiter
+
+	// MaxResponseSize caps the decoded size of a response body in bytes.
+	MaxResponseSize int64
+
+	sleep func(context.Context, time.Duration) error
+
+	recordDir  string
+	replay     *replayTransport
+	harPath    string
+	harSecrets bool
+
+	// cookieMu guards Cookies against concurrent rotation.
+	cookieMu         sync.Mutex
+	onCookiesRotated func(auth.Cookies)
+
+	onSchemaDrift func(SchemaDrift) error
+
+	cache *Cache
 }
 
 type Option func(*Client) error
@@ -67,6 +102,40 @@ func WithDebug(out io.Writer) Option {
 	}
 }
 
+// WithUserAgent sends ua as the User-Agent instead of the built-in Chrome
+// one. Bragnet invalidates a session used with a User-Agent other than
+// the one it was created with, so pass that browser's. Empty keeps the
+// default.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) error {
+		if ua != "" {
+			c.UserAgent = ua
+		}
+		return nil
+	}
+}
+
+// WithAcceptLanguage sends lang as the Accept-Language header. Empty keeps
+// the default.
+func WithAcceptLanguage(lang string) Option {
+	return func(c *Client) error {
+		if lang != "" {
+			c.AcceptLanguage = lang
+		}
+		return nil
+	}
+}
+
+// WithCookieRotation registers fn to be called whenever the server rotates
+// li_at or JSESSIONID via Set-Cookie. The client switches to the new values
+// on its own; fn is for persisting them.
+func WithCookieRotation(fn func(auth.Cookies)) Option {
+	return func(c *Client) error {
+		c.onCookiesRotated = fn
+		return nil
+	}
+}
+
 func NewClient(cookies auth.Cookies, opts ...Option) (*Client, error) {
 	u, err := url.Parse(DefaultBaseURL())
 	if err != nil {
@@ -78,9 +147,14 @@ func NewClient(cookies auth.Cookies, opts ...Option) (*Client, error) {
 		HTTP: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		Cookies:   cookies,
-		UserAgent: defaultUserAgent,
-		DebugOut:  io.Discard,
+		Cookies:        cookies,
+		UserAgent:      defaultUserAgent,
+		AcceptLanguage: defaultAcceptLanguage,
+		DebugOut:       io.Discard,
+		Retry:          DefaultRetryPolicy(),
+		sleep:          sleepCtx,
+
+		MaxResponseSize: DefaultMaxResponseSize,
 	}
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
@@ -93,6 +167,27 @@ func NewClient(cookies auth.Cookies, opts ...Option) (*Client, error) {
 	if c.HTTP == nil {
 		c.HTTP = &http.Client{Timeout: 30 * time.Second}
 	}
+	if c.replay != nil || c.recordDir != "" || c.harPath != "" {
+		// Copy so we don't mutate an *http.Client the caller may share.
+		h := *c.HTTP
+		if c.replay != nil {
+			h.Transport = c.replay
+		} else if c.recordDir != "" {
+			rt, err := newRecordTransport(h.Transport, c.recordDir, c.MaxResponseSize)
+			if err != nil {
+				return nil, err
+			}
+			h.Transport = rt
+		}
+		if c.harPath != "" {
+			rt, err := newHARTransport(h.Transport, c.harPath, c.harSecrets, c.MaxResponseSize)
+			if err != nil {
+				return nil, err
+			}
+			h.Transport = rt
+		}
+		c.HTTP = &h
+	}
 	return c, nil
 }
 
@@ -101,6 +196,12 @@ type HTTPError struct {
 	URL        string
 	StatusCode int
 	Body       string
+
+	// Kind is the sentinel this failure was classified as (ErrNotFound,
+	// ErrSessionExpired, …), or nil.
+	Kind error
+	// RetryAfter is the server's Retry-After hint, if it sent one.
+	RetryAfter time.Duration
 }
 
 func (e *HTTPError) Error() string {
@@ -110,6 +211,8 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("%s %s: HTTP %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
 }
 
+func (e *HTTPError) Unwrap() error { return e.Kind }
+
 // DoRaw is like Do but accepts a pre-built raw query string (not url.Values)
 // to avoid double-encoding Bragnet's tuple syntax.
 func (c *Client) DoRaw(ctx context.Context, method, path string, rawQuery string, body any, out any) error {
@@ -136,7 +239,8 @@ func (c *Client) Do(ctx context.Context, method, path string, query url.Values,
 }
 
 func (c *Client) doInternal(ctx context.Context, method, path string, rawQuery string, body any, out any, headerOverrides map[string]string) error {
-	if c.Cookies.LiAt == "" || c.Cookies.JSessionID == "" {
+	cookies := c.currentCookies()
+	if c.replay == nil && !cookies.Valid() {
 		return fmt.Errorf("missing auth cookies (li_at, JSESSIONID)")
 	}
 
@@ -146,60 +250,133 @@ func (c *Client) doInternal(ctx context.Context, method, path string, rawQuery s
 		u.RawQuery = rawQuery
 	}
 
-	var bodyReader io.Reader
+	var bodyBytes []byte
 	var contentType string
 	if body != nil {
 		switch v := body.(type) {
 		case []byte:
-			bodyReader = bytes.NewReader(v)
+			bodyBytes = v
 		case io.Reader:
-			bodyReader = v
+			// Buffer readers so the body can be replayed on retry.
+			b, err := io.ReadAll(v)
+			if err != nil {
+				return fmt.Errorf("read request body: %w", err)
+			}
+			bodyBytes = b
 		default:
 			b, err := json.Marshal(v)
 			if err != nil {
 				return fmt.Errorf("marshal request json: %w", err)
 			}
-			bodyReader = bytes.NewReader(b)
+			bodyBytes = b
 			contentType = "application/json; charset=utf-8"
 		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
-	if err != nil {
-		return fmt.Errorf("new request: %w", err)
+	var cacheFile string
+	if c.cache != nil && method == http.MethodGet && out != nil && c.replay == nil {
+		if ttl := c.cache.ttl(path); ttl > 0 {
+			cacheFile = c.cache.file(u.String(), cookies.LiAt)
+			if cached, ok := c.cache.get(cacheFile, ttl); ok {
+				if c.Debug {
+					fmt.Fprintf(c.DebugOut, "[li] %s %s (cached)\n", method, u.String())
+				}
+				if err := json.Unmarshal(cached, out); err != nil {
+					return fmt.Errorf("%s %s: decode cached response: %w", method, u.String(), err)
+				}
+				return nil
+			}
+		}
 	}
 
-	req.Header.Set("user-agent", c.UserAgent)
-	req.Header.Set("accept", "application/vnd.linkedin.normalized+json+2.1")
-	req.Header.Set("accept-language", defaultAcceptLanguage)
-	req.Header.Set("x-li-lang", "en_US")
-	req.Header.Set("x-restli-protocol-version", "2.0.0")
-	req.Header.Set("csrf-token", c.Cookies.CSRFToken())
-	req.Header.Set("cookie", c.Cookies.CookieHeader())
-	if contentType != "" && req.Header.Get("content-type") == "" {
-		req.Header.Set("content-type", contentType)
-	}
-	// Apply any per-request header overrides (e.g. messaging endpoints).
-	for k, v := range headerOverrides {
-		req.Header.Set(k, v)
-	}
+	canRetry := c.Retry.allows(ctx, method)
+	class := ClassifyEndpoint(method, path)
+	var resp *http.Response
+	for attempt := 1; ; attempt++ {
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(ctx, class); err != nil {
+				return fmt.Errorf("rate limiter: %w", err)
+			}
+		}
 
-	if c.Debug {
-		fmt.Fprintf(c.DebugOut, "[li] %s %s\n", method, u.String())
-	}
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(bodyBytes)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+		if err != nil {
+			return fmt.Errorf("new request: %w", err)
+		}
 
-	resp, err := c.HTTP.Do(req)
-	if err != nil {
-		return fmt.Errorf("http do: %w", err)
+		req.Header.Set("user-agent", c.UserAgent)
+		req.Header.Set("accept", "application/vnd.linkedin.normalized+json+2.1")
+		req.Header.Set("accept-language", c.AcceptLanguage)
+		req.Header.Set("x-li-lang", "en_US")
+		req.Header.Set("x-restli-protocol-version", "2.0.0")
+		cookies := c.currentCookies()
+		req.Header.Set("csrf-token", cookies.CSRFToken())
+		req.Header.Set("cookie", cookies.CookieHeader())
+		if contentType != "" && req.Header.Get("content-type") == "" {
+			req.Header.Set("content-type", contentType)
+		}
+		// Apply any per-request header overrides (e.g. messaging endpoints).
+		for k, v := range headerOverrides {
+			req.Header.Set(k, v)
+		}
+
+		if c.Debug {
+			fmt.Fprintf(c.DebugOut, "[li] %s %s\n", method, u.String())
+		}
+
+		resp, err = c.HTTP.Do(req)
+		if err != nil {
+			// Cassette errors (mismatch, exhausted) and oversized bodies
+			// read by the recorders are permanent; don't retry them.
+			if canRetry && c.replay == nil && !errors.Is(err, ErrResponseTooLarge) && attempt < c.Retry.MaxAttempts && ctx.Err() == nil {
+				if werr := c.waitRetry(ctx, attempt, c.Retry.backoff(attempt)); werr == nil {
+					continue
+				}
+			}
+			return fmt.Errorf("http do: %w", err)
+		}
+
+		c.captureRotatedCookies(resp)
+
+		if !canRetry || attempt >= c.Retry.MaxAttempts || !retryableStatus(resp.StatusCode) {
+			break
+		}
+		wait := parseRetryAfter(resp.Header.Get("retry-after"), time.Now())
+		if wait == 0 {
+			wait = c.Retry.backoff(attempt)
+		}
+		if c.Retry.MaxDelay > 0 && wait > c.Retry.MaxDelay {
+			// The server wants us gone for longer than we're willing to wait.
+			break
+		}
+		if c.Debug {
+			fmt.Fprintf(c.DebugOut, "[li] -> %d\n", resp.StatusCode)
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+		_ = resp.Body.Close()
+		if err := c.waitRetry(ctx, attempt, wait); err != nil {
+			return err
+		}
 	}
 	defer resp.Body.Close()
 
-	const maxBody = 5 << 20 // 5 MiB
-	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	var respBody []byte
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		if c.Debug {
+			fmt.Fprintf(c.DebugOut, "[li] -> %d (%d bytes)\n", resp.StatusCode, len(respBody))
+		}
+	}
 
-	if c.Debug {
-		fmt.Fprintf(c.DebugOut, "[li] -> %d (%d bytes)\n", resp.StatusCode, len(respBody))
+	var finalPath string
+	if resp.Request != nil && resp.Request.URL != nil {
+		finalPath = resp.Request.URL.Path
 	}
+	kind := classifyResponse(resp.StatusCode, finalPath, respBody)
 
 	if resp.StatusCode == http.StatusTooManyRequests {
 		return &HTTPError{
@@ -207,10 +384,12 @@ func (c *Client) doInternal(ctx context.Context, method, path string, rawQuery s
 			URL:        u.String(),
 			StatusCode: resp.StatusCode,
 			Body:       "rate limited by Bragnet, try again later",
+			Kind:       kind,
+			RetryAfter: parseRetryAfter(resp.Header.Get("retry-after"), time.Now()),
 		}
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || kind != nil {
 		snippet := strings.TrimSpace(string(respBody))
 		if len(snippet) > 2000 {
 			snippet = snippet[:2000] + "…"
@@ -220,17 +399,124 @@ func (c *Client) doInternal(ctx context.Context, method, path string, rawQuery s
 			URL:        u.String(),
 			StatusCode: resp.StatusCode,
 			Body:       snippet,
+			Kind:       kind,
 		}
 	}
 
+	rb := &limitedBody{r: resp.Body, limit: c.MaxResponseSize}
 	if out == nil {
+		// Nothing to decode, so an oversized body isn't an error here.
+		_, _ = io.Copy(io.Discard, rb)
+		c.debugBody(resp, rb)
 		return nil
 	}
-	if len(respBody) == 0 {
+	if cacheFile != "" {
+		b, err := readForCache(rb)
+		c.debugBody(resp, rb)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", method, u.String(), err)
+		}
+		if len(b) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(b, out); err != nil {
+			return fmt.Errorf("%s %s: decode response json: %w", method, u.String(), err)
+		}
+		if err := c.cache.put(cacheFile, u.String(), b); err != nil && c.Debug {
+			fmt.Fprintf(c.DebugOut, "[li] cache: %v\n", err)
+		}
 		return nil
 	}
-	if err := json.Unmarshal(respBody, out); err != nil {
-		return fmt.Errorf("decode response json: %w", err)
+	err := decodeBody(rb, out)
+	c.debugBody(resp, rb)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, u.String(), err)
 	}
 	return nil
 }
+
+func (c *Client) debugBody(resp *http.Response, rb *limitedBody) {
+	if c.Debug {
+		fmt.Fprintf(c.DebugOut, "[li] -> %d (%d bytes)\n", resp.StatusCode, rb.n)
+	}
+}
+
+func (c *Client) currentCookies() auth.Cookies {
+	c.cookieMu.Lock()
+	defer c.cookieMu.Unlock()
+	return c.Cookies
+}
+
+// captureRotatedCookies picks up new li_at/JSESSIONID values from
+// Set-Cookie headers. Deletions (expired or "delete me" values, which
+// Bragnet sends on logout) are ignored so a bad response can't wipe the
+// stored session.
+func (c *Client) captureRotatedCookies(resp *http.Response) {
+	setCookies := resp.Cookies()
+	if len(setCookies) == 0 {
+		return
+	}
+
+	c.cookieMu.Lock()
+	updated := c.Cookies
+	now := time.Now()
+	for _, ck := range setCookies {
+		if ck.Value == "" || ck.Value == "delete me" || ck.MaxAge < 0 ||
+			(!ck.Expires.IsZero() && ck.Expires.Before(now)) {
+			continue
+		}
+		switch ck.Name {
+		case "li_at":
+			// A new li_at comes with its own expiry; the same one may
+			// just have had it extended.
+			if ck.Value != updated.LiAt || expiryMoved(updated.Expires, ck.Expires) {
+				updated.Expires = ck.Expires.UTC()
+			}
+			updated.LiAt = ck.Value
+		case "JSESSIONID":
+			// Compare without quotes: the stored value may or may not carry them.
+			if strings.Trim(ck.Value, `"`) != updated.CSRFToken() {
+				updated.JSessionID = ck.Value
+			}
+		}
+	}
+	changed := updated.LiAt != c.Cookies.LiAt || updated.JSessionID != c.Cookies.JSessionID ||
+		!updated.Expires.Equal(c.Cookies.Expires)
+	c.Cookies = updated
+	c.cookieMu.Unlock()
+
+	if changed {
+		if c.Debug {
+			fmt.Fprintln(c.DebugOut, "[li] session cookies rotated by server")
+		}
+		if c.onCookiesRotated != nil {
+			c.onCookiesRotated(updated)
+		}
+	}
+}
+
+// expiryMoved reports whether a Set-Cookie expiry next differs enough from
+// the known one, old, to be worth keeping. A cookie without one says nothing.
+func expiryMoved(old, next time.Time) bool {
+	if next.IsZero() {
+		return false
+	}
+	if old.IsZero() {
+		return true
+	}
+	d := next.Sub(old)
+	return d > expirySlack || d < -expirySlack
+}
+
+// waitRetry sleeps before retry number attempt, logging it in debug mode.
+func (c *Client) waitRetry(ctx context.Context, attempt int, wait time.Duration) error {
+	if c.Debug {
+		fmt.Fprintf(c.DebugOut, "[li] retrying in %s (attempt %d/%d)\n",
+			wait.Round(time.Millisecond), attempt+1, c.Retry.MaxAttempts)
+	}
+	sleep := c.sleep
+	if sleep == nil {
+		sleep = sleepCtx
+	}
+	return sleep(ctx, wait)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/janitrai/bragcli/internal/auth"
 )
@@ -47,3 +48,342 @@ func TestClientDo_SetsAuthHeaders(t *testing.T) {
 		t.Fatalf("Do: %v", err)
 	}
 }
+
+func TestClientDo_UserAgentAndLanguage(t *testing.T) {
+	var ua, lang string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ua, lang = r.Header.Get("user-agent"), r.Header.Get("accept-language")
+		w.Header().Set("content-type", "application/json")
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		opts     []Option
+		wantUA   string
+		wantLang string
+	}{
+		{nil, defaultUserAgent, defaultAcceptLanguage},
+		{[]Option{WithUserAgent(""), WithAcceptLanguage("")}, defaultUserAgent, defaultAcceptLanguage},
+		{
+			[]Option{WithUserAgent("Mozilla/5.0 (Macintosh) Firefox/140.0"), WithAcceptLanguage("de-DE,de;q=0.9")},
+			"Mozilla/5.0 (Macintosh) Firefox/140.0", "de-DE,de;q=0.9",
+		},
+	}
+	for _, tt := range tests {
+		opts := append([]Option{WithBaseURL(ts.URL + "/voyager/api")}, tt.opts...)
+		c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if ua != tt.wantUA || lang != tt.wantLang {
+			t.Errorf("sent user-agent %q, accept-language %q; want %q, %q", ua, lang, tt.wantUA, tt.wantLang)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Retry policy
+// ---------------------------------------------------------------------------
+
+func newRetryTestClient(t *testing.T, url string, waits *[]time.Duration, opts ...Option) *Client {
+	t.Helper()
+	opts = append([]Option{WithBaseURL(url + "/voyager/api")}, opts...)
+	c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"}, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return c
+}
+
+func TestClientDo_RetriesTransientGET(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := newRetryTestClient(t, ts.URL, &waits)
+
+	var out map[string]any
+	if err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, &out); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if len(waits) != 2 {
+		t.Errorf("waits = %v, want 2 entries", waits)
+	}
+	if out["ok"] != true {
+		t.Errorf("out = %v", out)
+	}
+}
+
+func TestClientDo_HonorsRetryAfter(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := newRetryTestClient(t, ts.URL, &waits)
+
+	if err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, nil); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(waits) != 1 || waits[0] != 7*time.Second {
+		t.Errorf("waits = %v, want [7s]", waits)
+	}
+}
+
+func TestClientDo_RetryAfterBeyondMaxDelayGivesUp(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := newRetryTestClient(t, ts.URL, &waits)
+
+	err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, nil)
+	httpErr, ok := err.(*HTTPError)
+	if !ok || httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want 429 HTTPError", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if len(waits) != 0 {
+		t.Errorf("waits = %v, want none", waits)
+	}
+}
+
+func TestClientDo_DoesNotRetryWritesByDefault(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := newRetryTestClient(t, ts.URL, &waits)
+
+	err := c.Do(context.Background(), http.MethodPost, "/contentcreation/normShares", nil, map[string]any{"a": 1}, nil)
+	if err == nil {
+		t.Fatal("expected error for 502")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestClientDo_RetriesWritesWhenOptedIn(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := newRetryTestClient(t, ts.URL, &waits)
+
+	if err := c.Do(WithWriteRetries(context.Background()), http.MethodPost, "/x", nil, map[string]any{"a": 1}, nil); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("calls = %d, want 2", len(bodies))
+	}
+	if bodies[0] != bodies[1] || bodies[1] != `{"a":1}` {
+		t.Errorf("request body not replayed: %q", bodies)
+	}
+}
+
+func TestClientDo_NoRetryOn500(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := newRetryTestClient(t, ts.URL, &waits)
+
+	if err := c.Do(context.Background(), http.MethodGet, "/graphql", nil, nil, nil); err == nil {
+		t.Fatal("expected error for 500")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		in   string
+		want time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "120", 2 * time.Minute},
+		{"negative", "-5", 0},
+		{"http date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"past date", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"garbage", "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.in, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond}
+	for attempt := 1; attempt <= 4; attempt++ {
+		d := p.backoff(attempt)
+		if d <= 0 || d > p.MaxDelay {
+			t.Errorf("backoff(%d) = %v, want in (0, %v]", attempt, d, p.MaxDelay)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Cookie rotation
+// ---------------------------------------------------------------------------
+
+func TestClientDo_CapturesRotatedCookies(t *testing.T) {
+	expires := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.SetCookie(w, &http.Cookie{Name: "li_at", Value: "rotated", Expires: expires})
+			w.Header().Add("Set-Cookie", `JSESSIONID="ajax:456"; Path=/`)
+			// Unrelated cookies are ignored.
+			http.SetCookie(w, &http.Cookie{Name: "lang", Value: "v=2&lang=en-us"})
+		} else {
+			if got := r.Header.Get("csrf-token"); got != "ajax:456" {
+				t.Errorf("csrf-token after rotation = %q, want ajax:456", got)
+			}
+			if !strings.Contains(r.Header.Get("cookie"), "li_at=rotated") {
+				t.Errorf("cookie after rotation = %q", r.Header.Get("cookie"))
+			}
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer ts.Close()
+
+	var got []auth.Cookies
+	c, err := NewClient(auth.Cookies{LiAt: "liat", JSessionID: "ajax:123"},
+		WithBaseURL(ts.URL+"/voyager/api"),
+		WithCookieRotation(func(ck auth.Cookies) { got = append(got, ck) }),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, nil); err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("rotation callbacks = %d, want 1", len(got))
+	}
+	if got[0].LiAt != "rotated" || got[0].CSRFToken() != "ajax:456" || !got[0].Expires.Equal(expires) {
+		t.Fatalf("rotated cookies = %+v", got[0])
+	}
+	if c.Cookies.LiAt != "rotated" {
+		t.Fatalf("client cookies not updated: %+v", c.Cookies)
+	}
+}
+
+func TestClientDo_IgnoresSlidingExpiry(t *testing.T) {
+	expires := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := expires
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "li_at", Value: "liat", Expires: next})
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer ts.Close()
+
+	var got []auth.Cookies
+	c, err := NewClient(auth.Cookies{LiAt: "liat", JSessionID: "ajax:123", Expires: expires},
+		WithBaseURL(ts.URL+"/voyager/api"),
+		WithCookieRotation(func(ck auth.Cookies) { got = append(got, ck) }),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for _, d := range []time.Duration{0, time.Minute, 2 * time.Hour, 3 * 24 * time.Hour} {
+		next = expires.Add(d)
+		if err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, nil); err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+	}
+
+	if len(got) != 1 || !got[0].Expires.Equal(expires.Add(3*24*time.Hour)) {
+		t.Fatalf("rotation callbacks = %+v, want one for the expiry moved by days", got)
+	}
+}
+
+func TestClientDo_IgnoresCookieDeletion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "li_at=delete me; Expires=Thu, 01 Jan 1970 00:00:00 GMT")
+		w.Header().Add("Set-Cookie", `JSESSIONID="ajax:123"; Path=/`)
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer ts.Close()
+
+	called := false
+	c, err := NewClient(auth.Cookies{LiAt: "liat", JSessionID: "ajax:123"},
+		WithBaseURL(ts.URL+"/voyager/api"),
+		WithCookieRotation(func(auth.Cookies) { called = true }),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, nil); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if called {
+		t.Fatal("rotation callback fired for a deletion / unchanged cookie")
+	}
+	if c.Cookies.LiAt != "liat" {
+		t.Fatalf("li_at = %q, want unchanged", c.Cookies.LiAt)
+	}
+}
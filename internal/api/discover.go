@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultDiscoveryPage is the page whose scripts DiscoverQueryIDs scans.
+// The feed loads the search and messaging bundles up front.
+const DefaultDiscoveryPage = "/feed/"
+
+// maxBundles caps how many scripts one discovery run downloads.
+const maxBundles = 200
+
+var (
+	// scriptRe matches script sources and module preloads in a page.
+	scriptRe = regexp.MustCompile(`<(?:script|link)\b[^>]*?\b(?:src|href)="([^"]+\.js(?:\?[^"]*)?)"`)
+
+	// queryIDRe matches queryIds as they appear in the web client's
+	// bundles: an operation name, a dot and a 32-character hex hash.
+	queryIDRe = regexp.MustCompile(`\b((?:voyager|messenger)[A-Za-z0-9]+)\.([0-9a-f]{32})\b`)
+)
+
+// DiscoverQueryIDs loads page (a path on the site, DefaultDiscoveryPage if
+// empty) with the session cookies, downloads the JavaScript bundles it
+// references and returns every queryId found in them, grouped by operation
+// in order of appearance. Bundles on other hosts (the CDN) are fetched
+// without cookies.
+func (bn *Bragnet) DiscoverQueryIDs(ctx context.Context, page string) (map[Query][]string, error) {
+	if page == "" {
+		page = DefaultDiscoveryPage
+	}
+	pageURL, err := bn.c.siteURL().Parse(page)
+	if err != nil {
+		return nil, fmt.Errorf("parse page url: %w", err)
+	}
+
+	html, err := bn.c.fetchStatic(ctx, pageURL, true)
+	if err != nil {
+		return nil, err
+	}
+	bundles := scriptURLs(html, pageURL)
+	if len(bundles) == 0 {
+		return nil, fmt.Errorf("no scripts found on %s", pageURL)
+	}
+
+	found := make(map[Query][]string)
+	for _, b := range bundles {
+		js, err := bn.c.fetchStatic(ctx, b, b.Host == pageURL.Host)
+		if err != nil {
+			return nil, err
+		}
+		for q, ids := range extractQueryIDs(js) {
+			found[q] = dedupe(append(found[q], ids...))
+		}
+	}
+	return found, nil
+}
+
+// siteURL is the origin the API lives on, e.g. https://www.linkedin.com/.
+func (c *Client) siteURL() *url.URL {
+	return &url.URL{Scheme: c.BaseURL.Scheme, Host: c.BaseURL.Host, Path: "/"}
+}
+
+// fetchStatic GETs a page or script and returns its body, capped at
+// MaxResponseSize.
+func (c *Client) fetchStatic(ctx context.Context, u *url.URL, withCookies bool) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("user-agent", c.UserAgent)
+	req.Header.Set("accept-language", c.AcceptLanguage)
+	if withCookies {
+		req.Header.Set("cookie", c.currentCookies().CookieHeader())
+	}
+
+	if c.Debug {
+		fmt.Fprintf(c.DebugOut, "[li] GET %s\n", u)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var finalPath string
+	if resp.Request != nil && resp.Request.URL != nil {
+		finalPath = resp.Request.URL.Path
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || (withCookies && classifyResponse(resp.StatusCode, finalPath, nil) != nil) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &HTTPError{
+			Method:     http.MethodGet,
+			URL:        u.String(),
+			StatusCode: resp.StatusCode,
+			Kind:       classifyResponse(resp.StatusCode, finalPath, body),
+		}
+	}
+
+	rb := &limitedBody{r: resp.Body, limit: c.MaxResponseSize}
+	b, err := io.ReadAll(rb)
+	c.debugBody(resp, rb)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", u, err)
+	}
+	return b, nil
+}
+
+// scriptURLs returns the distinct script URLs referenced by a page,
+// resolved against base.
+func scriptURLs(html []byte, base *url.URL) []*url.URL {
+	var out []*url.URL
+	seen := make(map[string]bool)
+	for _, m := range scriptRe.FindAllSubmatch(html, -1) {
+		ref := strings.ReplaceAll(string(m[1]), "&amp;", "&")
+		u, err := base.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || seen[u.String()] {
+			continue
+		}
+		seen[u.String()] = true
+		out = append(out, u)
+		if len(out) == maxBundles {
+			break
+		}
+	}
+	return out
+}
+
+// extractQueryIDs finds queryIds in a JavaScript bundle.
+func extractQueryIDs(js []byte) map[Query][]string {
+	out := make(map[Query][]string)
+	for _, m := range queryIDRe.FindAllSubmatch(js, -1) {
+		q := Query(m[1])
+		out[q] = dedupe(append(out[q], string(m[1])+"."+string(m[2])))
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+// The fixtures in testdata/queryids are trimmed copies of a feed page and
+// its bundles. feed.html references the messaging bundle on a CDN host,
+// written as {{CDN}}.
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", "queryids", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestExtractQueryIDs(t *testing.T) {
+	got := extractQueryIDs(readFixture(t, "main-a41b.js"))
+	want := map[Query][]string{
+		QuerySearchClusters: {
+			"voyagerSearchDashClusters.b0928897b71bd00a5a7291755dcd64f0",
+			"voyagerSearchDashClusters.ef3d0937fb65bd7812e32e5a85028e79",
+		},
+		"voyagerSearchDashTypeahead": {"voyagerSearchDashTypeahead.1a2b3c4d5e6f708192a3b4c5d6e7f801"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractQueryIDs = %v, want %v", got, want)
+	}
+
+	if got := extractQueryIDs(readFixture(t, "runtime-8c1d.js")); len(got) != 0 {
+		t.Errorf("runtime bundle: got %v, want none", got)
+	}
+}
+
+func TestScriptURLs(t *testing.T) {
+	base, _ := url.Parse("https://www.example.com/feed/")
+	html := strings.ReplaceAll(string(readFixture(t, "feed.html")), "{{CDN}}", "https://static.example.com")
+
+	var got []string
+	for _, u := range scriptURLs([]byte(html), base) {
+		got = append(got, u.String())
+	}
+	want := []string{
+		"https://static.example.com/aero-v1/sc/h/messaging-3f9a.js",
+		"https://www.example.com/static/js/runtime-8c1d.js?lang=en&v=2",
+		"https://www.example.com/static/js/main-a41b.js",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scriptURLs =\n%v\nwant\n%v", got, want)
+	}
+}
+
+// newDiscoveryServers serves the fixtures: the page and local bundles from
+// site (which requires li_at), the messaging bundle from cdn. cdnCookies
+// records any cookie header the CDN receives.
+func newDiscoveryServers(t *testing.T, cdnCookies *[]string) (site *httptest.Server) {
+	t.Helper()
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c := r.Header.Get("cookie"); c != "" {
+			*cdnCookies = append(*cdnCookies, c)
+		}
+		if r.URL.Path != "/aero-v1/sc/h/messaging-3f9a.js" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(readFixture(t, "messaging-3f9a.js"))
+	}))
+	t.Cleanup(cdn.Close)
+
+	site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/login":
+			_, _ = w.Write([]byte("<html>Sign in</html>"))
+		case r.URL.Path == "/feed/":
+			if c, err := r.Cookie("li_at"); err != nil || c.Value != "good" {
+				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				return
+			}
+			html := strings.ReplaceAll(string(readFixture(t, "feed.html")), "{{CDN}}", cdn.URL)
+			_, _ = w.Write([]byte(html))
+		case strings.HasPrefix(r.URL.Path, "/static/js/"):
+			_, _ = w.Write(readFixture(t, strings.TrimPrefix(r.URL.Path, "/static/js/")))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+func TestDiscoverQueryIDs(t *testing.T) {
+	var cdnCookies []string
+	site := newDiscoveryServers(t, &cdnCookies)
+
+	c, err := NewClient(auth.Cookies{LiAt: "good", JSessionID: "ajax:x"}, WithBaseURL(site.URL+"/voyager/api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := NewBragnet(c).DiscoverQueryIDs(context.Background(), "")
+	if err != nil {
+		t.Fatalf("DiscoverQueryIDs: %v", err)
+	}
+
+	want := map[Query][]string{
+		QuerySearchClusters: {
+			"voyagerSearchDashClusters.b0928897b71bd00a5a7291755dcd64f0",
+			"voyagerSearchDashClusters.ef3d0937fb65bd7812e32e5a85028e79",
+		},
+		QueryConversations: {"messengerConversations.0d5e6781bbee71c3e51c8843c6519f48"},
+		QueryMessages:      {"messengerMessages.5846eeb71c981f11e0134cb6626cc314"},
+	}
+	for q, ids := range want {
+		if !reflect.DeepEqual(found[q], ids) {
+			t.Errorf("%s = %v, want %v", q, found[q], ids)
+		}
+	}
+	if len(cdnCookies) != 0 {
+		t.Errorf("session cookies sent to the CDN: %v", cdnCookies)
+	}
+}
+
+func TestDiscoverQueryIDs_SignedOut(t *testing.T) {
+	var cdnCookies []string
+	site := newDiscoveryServers(t, &cdnCookies)
+
+	c, err := NewClient(auth.Cookies{LiAt: "expired", JSessionID: "ajax:x"}, WithBaseURL(site.URL+"/voyager/api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewBragnet(c).DiscoverQueryIDs(context.Background(), "")
+	if !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("err = %v, want ErrSessionExpired", err)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaDrift lists the expected fields and $types missing from one
+// endpoint's response. The parsers still return what they could decode,
+// typically with blank names or text where the missing fields were.
+type SchemaDrift struct {
+	Endpoint string   // API path or GraphQL operation
+	Missing  []string // "Type.json.path" or "$type=…"
+}
+
+func (d SchemaDrift) String() string {
+	return d.Endpoint + ": " + strings.Join(d.Missing, ", ")
+}
+
+// SchemaDriftError is returned instead of partial results when the drift
+// handler rejects a response. It matches ErrSchemaDrift.
+type SchemaDriftError struct {
+	SchemaDrift
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("%s: missing %s", e.Endpoint, strings.Join(e.Missing, ", "))
+}
+
+func (e *SchemaDriftError) Unwrap() error { return ErrSchemaDrift }
+
+// WithSchemaDriftHandler sets a function called whenever a response is
+// missing expected fields. Returning an error (usually a
+// *SchemaDriftError) makes the API call fail with it; returning nil keeps
+// the partial result. Without a handler drift is ignored.
+func WithSchemaDriftHandler(fn func(SchemaDrift) error) Option {
+	return func(c *Client) error {
+		c.onSchemaDrift = fn
+		return nil
+	}
+}
+
+// checkDrift passes the drift noted in n to the client's handler.
+func (bn *Bragnet) checkDrift(endpoint string, n *Normalized) error {
+	missing := n.Missing()
+	if len(missing) == 0 || bn.c.onSchemaDrift == nil {
+		return nil
+	}
+	return bn.c.onSchemaDrift(SchemaDrift{Endpoint: endpoint, Missing: missing})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+// driftedProfileFixture is a profile whose name fields were renamed.
+const driftedProfileFixture = `{"data":{},"included":[{
+	"$type":"com.linkedin.voyager.dash.identity.profile.Profile",
+	"entityUrn":"urn:li:fsd_profile:ACoAAJANE002",
+	"publicIdentifier":"jane-smith",
+	"givenName":"Jane","familyName":"Smith","headline":"VP of Engineering"
+}]}`
+
+func newDriftClient(t *testing.T, handler func(SchemaDrift) error) *Bragnet {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_, _ = io.WriteString(w, driftedProfileFixture)
+	}))
+	t.Cleanup(ts.Close)
+
+	opts := []Option{WithBaseURL(ts.URL + "/voyager/api")}
+	if handler != nil {
+		opts = append(opts, WithSchemaDriftHandler(handler))
+	}
+	c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBragnet(c)
+}
+
+func TestSchemaDrift_ReportedWithPartialResult(t *testing.T) {
+	var got []SchemaDrift
+	bn := newDriftClient(t, func(d SchemaDrift) error {
+		got = append(got, d)
+		return nil
+	})
+
+	p, err := bn.GetProfile(context.Background(), "jane-smith")
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if p.PublicIdentifier != "jane-smith" || p.Headline != "VP of Engineering" {
+		t.Errorf("partial profile = %+v", p)
+	}
+	want := []SchemaDrift{{
+		Endpoint: "/identity/dash/profiles",
+		Missing:  []string{"Profile.firstName", "Profile.lastName"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("drift = %+v, want %+v", got, want)
+	}
+}
+
+func TestSchemaDrift_HandlerErrorFailsCall(t *testing.T) {
+	bn := newDriftClient(t, func(d SchemaDrift) error {
+		return &SchemaDriftError{SchemaDrift: d}
+	})
+
+	_, err := bn.GetProfile(context.Background(), "jane-smith")
+	if !errors.Is(err, ErrSchemaDrift) {
+		t.Fatalf("err = %v, want ErrSchemaDrift", err)
+	}
+}
+
+func TestSchemaDrift_IgnoredWithoutHandler(t *testing.T) {
+	bn := newDriftClient(t, nil)
+	if _, err := bn.GetProfile(context.Background(), "jane-smith"); err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+}
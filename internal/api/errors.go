@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for the failure modes callers act on. Every *HTTPError
+// unwraps to at most one of them, so check with errors.Is; use errors.As
+// with *HTTPError for the status code, body and RetryAfter.
+var (
+	// ErrSessionExpired means li_at is no longer accepted (401, or a
+	// redirect to the login page / auth wall).
+	ErrSessionExpired = errors.New("bragnet session expired")
+
+	// ErrCSRFMismatch means the csrf-token header didn't match JSESSIONID,
+	// usually because the cookie was rotated in the browser.
+	ErrCSRFMismatch = errors.New("bragnet csrf check failed")
+
+	// ErrChallenge means Bragnet wants an interactive security check
+	// (captcha, PIN) before it serves more requests.
+	ErrChallenge = errors.New("bragnet security challenge required")
+
+	// ErrNotFound means the requested entity doesn't exist (or isn't
+	// visible to this account).
+	ErrNotFound = errors.New("not found")
+
+	// ErrRateLimited means Bragnet answered 429 and retries were exhausted.
+	// HTTPError.RetryAfter carries the server's hint, if any.
+	ErrRateLimited = errors.New("rate limited by bragnet")
+
+	// ErrSchemaDrift means a response no longer has the shape the parsers
+	// expect: a required field is missing, or (in strict mode) an expected
+	// one. See SchemaDriftError and MissingFieldsError.
+	ErrSchemaDrift = errors.New("bragnet response schema changed")
+)
+
+// statusChallenge is the non-standard status Bragnet's edge returns when it
+// suspects automation.
+const statusChallenge = 999
+
+// classifyResponse picks the sentinel for a failed response. finalPath is
+// the path of the last request after redirects; a redirect to the login
+// page or a checkpoint is how Bragnet reports some auth failures with a
+// 200 HTML page.
+func classifyResponse(status int, finalPath string, body []byte) error {
+	switch {
+	case strings.HasPrefix(finalPath, "/checkpoint/"):
+		return ErrChallenge
+	case strings.HasPrefix(finalPath, "/authwall"),
+		strings.HasPrefix(finalPath, "/login"),
+		strings.HasPrefix(finalPath, "/uas/login"):
+		return ErrSessionExpired
+	}
+
+	switch status {
+	case http.StatusUnauthorized:
+		return ErrSessionExpired
+	case http.StatusForbidden:
+		if strings.Contains(string(body), "CSRF check failed") {
+			return ErrCSRFMismatch
+		}
+	case http.StatusNotFound, http.StatusGone:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusChallenge:
+		return ErrChallenge
+	}
+	return nil
+}
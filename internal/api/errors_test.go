@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+func TestClientDo_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    error
+	}{
+		{
+			name: "401 is an expired session",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			want: ErrSessionExpired,
+		},
+		{
+			name: "403 csrf",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = io.WriteString(w, "CSRF check failed.")
+			},
+			want: ErrCSRFMismatch,
+		},
+		{
+			name: "404",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: ErrNotFound,
+		},
+		{
+			name: "999 challenge",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(statusChallenge)
+			},
+			want: ErrChallenge,
+		},
+		{
+			name: "redirect to checkpoint",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/checkpoint/challenge/abc" {
+					_, _ = io.WriteString(w, "<html>verify</html>")
+					return
+				}
+				http.Redirect(w, r, "/checkpoint/challenge/abc", http.StatusFound)
+			},
+			want: ErrChallenge,
+		},
+		{
+			name: "redirect to login",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/login" {
+					_, _ = io.WriteString(w, "<html>sign in</html>")
+					return
+				}
+				http.Redirect(w, r, "/login?session_redirect=x", http.StatusFound)
+			},
+			want: ErrSessionExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"},
+				WithBaseURL(ts.URL+"/voyager/api"), WithRetryPolicy(NoRetry))
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			var out map[string]any
+			err = c.Do(context.Background(), http.MethodGet, "/me", nil, nil, &out)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want errors.Is %v", err, tt.want)
+			}
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("expected *HTTPError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestClientDo_RateLimitedCarriesRetryAfter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	c := newRetryTestClient(t, ts.URL, &waits)
+	err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.RetryAfter != 2*time.Minute {
+		t.Fatalf("RetryAfter = %v, want 2m", httpErr.RetryAfter)
+	}
+}
+
+func TestClientDo_OtherErrorsUnclassified(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"}, WithBaseURL(ts.URL+"/voyager/api"))
+	err := c.Do(context.Background(), http.MethodGet, "/me", nil, nil, nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected *HTTPError, got %T: %v", err, err)
+	}
+	if httpErr.Kind != nil {
+		t.Fatalf("Kind = %v, want nil", httpErr.Kind)
+	}
+}
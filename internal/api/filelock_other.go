@@ -0,0 +1,38 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// staleLockAge is how old a lock file may get before we assume its owner
+// died without cleaning up.
+const staleLockAge = 30 * time.Second
+
+// lockFile takes an exclusive lock on path by creating it with O_EXCL,
+// polling until it succeeds. The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(2 * staleLockAge)
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("open lock file: %w", err)
+		}
+		if info, serr := os.Stat(path); serr == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("lock %s: timed out", path)
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+}
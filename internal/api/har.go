@@ -0,0 +1,271 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HAR 1.2 (http://www.softwareishard.com/blog/har-12-spec/), trimmed to the
+// fields Chrome DevTools needs to import an archive.
+
+type harLog struct {
+	Log harBody `json:"log"`
+}
+
+type harBody struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// WithHAR writes an HTTP Archive of every exchange to path, which can be
+// imported into Chrome DevTools' Network panel. Cookies and the csrf-token
+// are redacted unless keepSecrets is set.
+func WithHAR(path string, keepSecrets bool) Option {
+	return func(c *Client) error {
+		if path == "" {
+			return fmt.Errorf("har path is empty")
+		}
+		c.harPath = path
+		c.harSecrets = keepSecrets
+		return nil
+	}
+}
+
+// harTransport records exchanges and rewrites the archive after each one,
+// so the file stays valid even if the process is interrupted.
+type harTransport struct {
+	next        http.RoundTripper
+	path        string
+	keepSecrets bool
+	maxBody     int64
+	now         func() time.Time
+	// errOut gets a warning when the archive can't be rewritten. The
+	// exchange already happened, so it isn't failed over that.
+	errOut io.Writer
+
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+func newHARTransport(next http.RoundTripper, path string, keepSecrets bool, maxBody int64) (*harTransport, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create har dir: %w", err)
+		}
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	t := &harTransport{next: next, path: path, keepSecrets: keepSecrets, maxBody: maxBody, now: time.Now, errOut: os.Stderr}
+	// Start with an empty archive so a run that makes no requests still
+	// leaves a valid file behind.
+	if err := t.flush(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		reqBody = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	start := t.now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	waited := t.now().Sub(start)
+	respBody, err := readLimited(resp.Body, t.maxBody)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	total := t.now().Sub(start)
+
+	reqHeader, respHeader := req.Header, resp.Header
+	if !t.keepSecrets {
+		reqHeader, respHeader = redactHeader(reqHeader), redactHeader(respHeader)
+	}
+
+	entry := harEntry{
+		StartedDateTime: start.UTC().Format(time.RFC3339Nano),
+		Time:            ms(total),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(reqHeader),
+			QueryString: harQuery(req),
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(respHeader),
+			Content: harContent{
+				Size:     len(respBody),
+				MimeType: resp.Header.Get("content-type"),
+				Text:     string(respBody),
+			},
+			RedirectURL: resp.Header.Get("location"),
+			HeadersSize: -1,
+			BodySize:    len(respBody),
+		},
+		Timings: harTimings{Wait: ms(waited), Receive: ms(total - waited)},
+	}
+	if reqBody != nil {
+		entry.Request.PostData = &harPostData{MimeType: req.Header.Get("content-type"), Text: string(reqBody)}
+	}
+
+	t.mu.Lock()
+	t.entries = append(t.entries, entry)
+	err = t.flushLocked()
+	t.mu.Unlock()
+	if err != nil {
+		// A completed write must not look failed, or it may be retried.
+		fmt.Fprintf(t.errOut, "warning: %v\n", err)
+	}
+	return resp, nil
+}
+
+func (t *harTransport) flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.flushLocked()
+}
+
+func (t *harTransport) flushLocked() error {
+	entries := t.entries
+	if entries == nil {
+		entries = []harEntry{}
+	}
+	b, err := json.MarshalIndent(harLog{Log: harBody{
+		Version: "1.2",
+		Creator: harCreator{Name: "bragcli", Version: "dev"},
+		Entries: entries,
+	}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal har: %w", err)
+	}
+	if err := os.WriteFile(t.path, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write har: %w", err)
+	}
+	return nil
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			out = append(out, harNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func harQuery(req *http.Request) []harNameValue {
+	out := []harNameValue{}
+	q := req.URL.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range q[k] {
+			out = append(out, harNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
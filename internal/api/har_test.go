@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+func runHARClient(t *testing.T, keepSecrets bool) harLog {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("set-cookie", "li_at=rotated")
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "out", "session.har")
+	c, err := NewClient(auth.Cookies{LiAt: "secret-liat", JSessionID: "ajax:secret"},
+		WithBaseURL(ts.URL+"/voyager/api"), WithHAR(path, keepSecrets))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	q := map[string][]string{"q": {"people"}}
+	if err := c.Do(context.Background(), http.MethodGet, "/search", q, nil, nil); err != nil {
+		t.Fatalf("Do GET: %v", err)
+	}
+	if err := c.Do(context.Background(), http.MethodPost, "/follow", nil, map[string]any{"a": 1}, nil); err != nil {
+		t.Fatalf("Do POST: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read har: %v", err)
+	}
+	var har harLog
+	if err := json.Unmarshal(b, &har); err != nil {
+		t.Fatalf("parse har: %v", err)
+	}
+	return har
+}
+
+func headerValue(hs []harNameValue, name string) string {
+	for _, h := range hs {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+func TestHAR_RecordsRedactedEntries(t *testing.T) {
+	har := runHARClient(t, false)
+
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 2 {
+		t.Fatalf("version=%q entries=%d", har.Log.Version, len(har.Log.Entries))
+	}
+	get, post := har.Log.Entries[0], har.Log.Entries[1]
+	if get.Request.Method != "GET" || len(get.Request.QueryString) != 1 || get.Request.QueryString[0].Value != "people" {
+		t.Errorf("GET request = %+v", get.Request)
+	}
+	if get.Response.Status != 200 || get.Response.Content.Text != `{"ok":true}` {
+		t.Errorf("GET response = %+v", get.Response)
+	}
+	if post.Request.PostData == nil || post.Request.PostData.Text != `{"a":1}` {
+		t.Errorf("POST postData = %+v", post.Request.PostData)
+	}
+	for _, name := range []string{"cookie", "csrf-token"} {
+		if got := headerValue(get.Request.Headers, name); got != redacted {
+			t.Errorf("request %s = %q, want redacted", name, got)
+		}
+	}
+	if got := headerValue(get.Response.Headers, "set-cookie"); got != redacted {
+		t.Errorf("response set-cookie = %q, want redacted", got)
+	}
+}
+
+func TestHAR_KeepSecrets(t *testing.T) {
+	har := runHARClient(t, true)
+	if got := headerValue(har.Log.Entries[0].Request.Headers, "csrf-token"); got != "ajax:secret" {
+		t.Errorf("csrf-token = %q, want kept", got)
+	}
+}
+
+func TestHAR_WriteErrorKeepsResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "session.har")
+	rt, err := newHARTransport(nil, path, false, DefaultMaxResponseSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warn strings.Builder
+	rt.errOut = &warn
+	// A directory in its place makes rewriting the archive fail.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/follow", strings.NewReader(`{}`))
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v, want the response despite the write error", err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	if string(b) != `{"ok":true}` {
+		t.Errorf("body = %q", b)
+	}
+	if !strings.Contains(warn.String(), "write har") {
+		t.Errorf("warning = %q, want the write error", warn.String())
+	}
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/urn"
+)
+
+// Identity is one member under all the identifiers the API uses for them.
+// Follow needs MemberURN; Connect and messaging need ProfileURN.
+type Identity struct {
+	PublicIdentifier string `json:"public_identifier"`
+	FirstName        string `json:"first_name,omitempty"`
+	LastName         string `json:"last_name,omitempty"`
+	ProfileURN       string `json:"profile_urn"` // urn:li:fsd_profile:…
+	MemberURN        string `json:"member_urn"`  // urn:li:member:…
+}
+
+// FullName returns "First Last", trimmed.
+func (id Identity) FullName() string {
+	return strings.TrimSpace(id.FirstName + " " + id.LastName)
+}
+
+// Resolver turns whatever a user typed for a member (@handle, profile URL,
+// member URN, fsd_profile or fs_miniProfile URN, or a messaging
+// participant URN) into an Identity. Mappings it learns are kept in Cache,
+// when set, so later runs resolve them without a request.
+type Resolver struct {
+	bn    *Bragnet
+	Cache *IdentityCache
+}
+
+// NewResolver returns a resolver using bn for lookups and cache (which may
+// be nil) for known mappings.
+func NewResolver(bn *Bragnet, cache *IdentityCache) *Resolver {
+	return &Resolver{bn: bn, Cache: cache}
+}
+
+// Resolve looks up the member identified by s.
+func (r *Resolver) Resolve(ctx context.Context, s string) (Identity, error) {
+	key, lookup, err := identityKey(s)
+	if err != nil {
+		return Identity{}, err
+	}
+	if id, ok := r.Cache.lookup(key); ok {
+		return id, nil
+	}
+	p, err := r.profile(ctx, s, key, lookup)
+	if err != nil {
+		return Identity{}, err
+	}
+	return profileIdentity(p), nil
+}
+
+// Profile fetches the full profile of the member identified by s, which may
+// take any of the forms Resolve accepts. The identity is cached on the way.
+func (r *Resolver) Profile(ctx context.Context, s string) (Profile, error) {
+	key, lookup, err := identityKey(s)
+	if err != nil {
+		return Profile{}, err
+	}
+	return r.profile(ctx, s, key, lookup)
+}
+
+func (r *Resolver) profile(ctx context.Context, s, key, lookup string) (Profile, error) {
+	p, err := r.bn.GetProfile(ctx, lookup)
+	if err != nil {
+		return Profile{}, err
+	}
+	id := profileIdentity(p)
+	if id.ProfileURN == "" {
+		return Profile{}, fmt.Errorf("resolve %q: no fsd_profile URN in profile", s)
+	}
+	r.Cache.store(id, key)
+	return p, nil
+}
+
+// Self returns the logged-in member's identity.
+func (r *Resolver) Self(ctx context.Context) (Identity, error) {
+	me, err := r.bn.GetMe(ctx)
+	if err != nil {
+		return Identity{}, fmt.Errorf("get current user: %w", err)
+	}
+	id := Identity{
+		PublicIdentifier: me.PublicIdentifier,
+		FirstName:        me.FirstName,
+		LastName:         me.LastName,
+		ProfileURN:       me.ProfileURN,
+		MemberURN:        me.MemberURN,
+	}
+	if id.ProfileURN != "" {
+		r.Cache.store(id)
+		return id, nil
+	}
+
+	// Older /me payloads lack dashEntityUrn; the profile has it.
+	if me.PublicIdentifier == "" {
+		return Identity{}, fmt.Errorf("could not determine your profile URN (no publicIdentifier from /me)")
+	}
+	full, err := r.Resolve(ctx, me.PublicIdentifier)
+	if err != nil {
+		return Identity{}, fmt.Errorf("get own profile: %w", err)
+	}
+	return full, nil
+}
+
+func profileIdentity(p Profile) Identity {
+	id := Identity{
+		PublicIdentifier: p.PublicIdentifier,
+		FirstName:        p.FirstName,
+		LastName:         p.LastName,
+		MemberURN:        p.MemberURN,
+	}
+	if _, err := urn.ParseAs(p.MiniProfileEntityURN, urn.TypeProfile); err == nil {
+		id.ProfileURN = p.MiniProfileEntityURN
+	}
+	return id
+}
+
+// identityKey normalizes user input to a cache key and the value to pass
+// to the profile lookup.
+func identityKey(s string) (key, lookup string, err error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "urn:") {
+		handle := auth.NormalizePublicIdentifier(s)
+		if handle == "" {
+			return "", "", fmt.Errorf("empty profile identifier")
+		}
+		return "in:" + strings.ToLower(handle), handle, nil
+	}
+
+	u, err := urn.Parse(s)
+	if err == nil {
+		switch u.Type {
+		case urn.TypeProfile, urn.TypeMiniProfile:
+			if u.Validate() == nil {
+				return s, u.ID(), nil
+			}
+		default:
+			// Messaging participant URNs embed the member's fsd_profile
+			// URN. Member URNs carry its id too, unless theirs is the
+			// numeric one, which the profile lookup doesn't take.
+			p, err := urn.ToProfile(u)
+			if err == nil {
+				return s, p.ID(), nil
+			}
+			if u.Type == urn.TypeMember && u.Validate() == nil {
+				return "", "", fmt.Errorf("unsupported identifier %q: a numeric member id can't be looked up; use the username or fsd_profile URN", s)
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unsupported identifier %q: want a username, profile URL, or member, fsd_profile or messaging participant URN", s)
+}
+
+// ---------------------------------------------------------------------------
+// Identity cache
+// ---------------------------------------------------------------------------
+
+// DefaultIdentityTTL is how long a cached mapping is trusted. URNs never
+// change, but members can change their public identifier.
+const DefaultIdentityTTL = 30 * 24 * time.Hour
+
+// IdentityCache is a JSON file mapping identifiers (public identifiers,
+// URNs, and the raw inputs they were resolved from) to identities. A nil
+// *IdentityCache caches nothing.
+type IdentityCache struct {
+	Path string
+	TTL  time.Duration
+
+	// Refresh skips lookups but still records what gets resolved.
+	Refresh bool
+
+	now func() time.Time
+}
+
+// NewIdentityCache returns a cache stored at path.
+func NewIdentityCache(path string) *IdentityCache {
+	return &IdentityCache{Path: path, TTL: DefaultIdentityTTL, now: time.Now}
+}
+
+type cachedIdentity struct {
+	Identity
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+func (c *IdentityCache) lookup(key string) (Identity, bool) {
+	if c == nil || c.Refresh {
+		return Identity{}, false
+	}
+	entries, err := c.load()
+	if err != nil {
+		return Identity{}, false
+	}
+	e, ok := entries[key]
+	if !ok || c.now().Sub(e.ResolvedAt) >= c.TTL {
+		return Identity{}, false
+	}
+	return e.Identity, true
+}
+
+// store records id under its public identifier and URNs, plus any extra
+// keys. Failures are ignored: the cache only saves requests.
+func (c *IdentityCache) store(id Identity, extra ...string) {
+	if c == nil {
+		return
+	}
+	_ = c.update(func(entries map[string]cachedIdentity) {
+		e := cachedIdentity{Identity: id, ResolvedAt: c.now().UTC()}
+		keys := append([]string{id.ProfileURN, id.MemberURN}, extra...)
+		if id.PublicIdentifier != "" {
+			keys = append(keys, "in:"+strings.ToLower(id.PublicIdentifier))
+		}
+		for _, k := range keys {
+			if k != "" {
+				entries[k] = e
+			}
+		}
+	})
+}
+
+// Clear deletes the cache file.
+func (c *IdentityCache) Clear() error {
+	if err := os.Remove(c.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("clear identity cache: %w", err)
+	}
+	return nil
+}
+
+func (c *IdentityCache) load() (map[string]cachedIdentity, error) {
+	entries := make(map[string]cachedIdentity)
+	b, err := os.ReadFile(c.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("read identity cache: %w", err)
+	}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		// A corrupt cache is rebuilt from scratch.
+		return make(map[string]cachedIdentity), nil
+	}
+	return entries, nil
+}
+
+func (c *IdentityCache) update(fn func(map[string]cachedIdentity)) error {
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o700); err != nil {
+		return fmt.Errorf("create identity cache dir: %w", err)
+	}
+	unlock, err := lockFile(c.Path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	fn(entries)
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal identity cache: %w", err)
+	}
+	// Written in place: the lock already serialises access.
+	if err := os.WriteFile(c.Path, b, 0o600); err != nil {
+		return fmt.Errorf("write identity cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+func TestIdentityKey(t *testing.T) {
+	tests := []struct {
+		in, key, lookup string
+	}{
+		{"@Jane-Smith", "in:jane-smith", "Jane-Smith"},
+		{auth.BaseURL() + "/in/jane-smith/", "in:jane-smith", "jane-smith"},
+		{"urn:li:fsd_profile:ACoAAAXYZ123", "urn:li:fsd_profile:ACoAAAXYZ123", "ACoAAAXYZ123"},
+		{"urn:li:fs_miniProfile:ACoAAAXYZ123", "urn:li:fs_miniProfile:ACoAAAXYZ123", "ACoAAAXYZ123"},
+		{"urn:li:member:ACoAAAXYZ123", "urn:li:member:ACoAAAXYZ123", "ACoAAAXYZ123"},
+		{
+			"urn:li:msg_participant:(urn:li:fsd_profile:ACoAAME,urn:li:fsd_profile:ACoAAAXYZ123)",
+			"urn:li:msg_participant:(urn:li:fsd_profile:ACoAAME,urn:li:fsd_profile:ACoAAAXYZ123)",
+			"ACoAAAXYZ123",
+		},
+		{"urn:li:msg_messagingParticipant:urn:li:fsd_profile:ACoAAAXYZ123", "urn:li:msg_messagingParticipant:urn:li:fsd_profile:ACoAAAXYZ123", "ACoAAAXYZ123"},
+	}
+	for _, tt := range tests {
+		key, lookup, err := identityKey(tt.in)
+		if err != nil {
+			t.Errorf("identityKey(%q): %v", tt.in, err)
+			continue
+		}
+		if key != tt.key || lookup != tt.lookup {
+			t.Errorf("identityKey(%q) = %q, %q; want %q, %q", tt.in, key, lookup, tt.key, tt.lookup)
+		}
+	}
+
+	for _, bad := range []string{"", "  @ ", "urn:li:organization:42", "urn:li:member:", "urn:li:member:54321"} {
+		if _, _, err := identityKey(bad); err == nil {
+			t.Errorf("identityKey(%q): want error", bad)
+		}
+	}
+}
+
+// newResolverTestServer serves getProfileFixture for any profile lookup and
+// counts the requests.
+func newResolverTestServer(t *testing.T, hits *int) *Bragnet {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hits++
+		w.Header().Set("content-type", "application/json")
+		_, _ = io.WriteString(w, getProfileFixture)
+	}))
+	t.Cleanup(ts.Close)
+
+	c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"}, WithBaseURL(ts.URL+"/voyager/api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBragnet(c)
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	var hits int
+	r := NewResolver(newResolverTestServer(t, &hits), nil)
+
+	id, err := r.Resolve(context.Background(), "urn:li:fsd_profile:ACoAAAXYZ123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Identity{
+		PublicIdentifier: "jane-smith",
+		FirstName:        "Jane",
+		LastName:         "Smith",
+		ProfileURN:       "urn:li:fsd_profile:ACoAAAXYZ123",
+		MemberURN:        "urn:li:member:ACoAAAXYZ123",
+	}
+	if id != want {
+		t.Errorf("Resolve = %+v, want %+v", id, want)
+	}
+	if id.FullName() != "Jane Smith" {
+		t.Errorf("FullName = %q", id.FullName())
+	}
+}
+
+func TestResolver_Cache(t *testing.T) {
+	var hits int
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewIdentityCache(filepath.Join(t.TempDir(), "identities.json"))
+	cache.now = func() time.Time { return now }
+	r := NewResolver(newResolverTestServer(t, &hits), cache)
+	ctx := context.Background()
+
+	if _, err := r.Resolve(ctx, "@jane-smith"); err != nil {
+		t.Fatal(err)
+	}
+	// Every identifier learned from the profile is now cached.
+	for _, s := range []string{"jane-smith", "JANE-SMITH", "urn:li:fsd_profile:ACoAAAXYZ123", "urn:li:member:ACoAAAXYZ123"} {
+		if _, err := r.Resolve(ctx, s); err != nil {
+			t.Fatalf("Resolve(%q): %v", s, err)
+		}
+	}
+	if hits != 1 {
+		t.Fatalf("server hits = %d, want 1", hits)
+	}
+
+	cache.Refresh = true
+	if _, err := r.Resolve(ctx, "jane-smith"); err != nil {
+		t.Fatal(err)
+	}
+	cache.Refresh = false
+	if hits != 2 {
+		t.Fatalf("refresh: server hits = %d, want 2", hits)
+	}
+
+	now = now.Add(DefaultIdentityTTL)
+	if _, err := r.Resolve(ctx, "jane-smith"); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 {
+		t.Fatalf("expired: server hits = %d, want 3", hits)
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cache.Path); !os.IsNotExist(err) {
+		t.Errorf("cache file still exists after Clear: %v", err)
+	}
+	if err := cache.Clear(); err != nil {
+		t.Errorf("Clear on missing file: %v", err)
+	}
+}
+
+func TestIdentityCache_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identities.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewIdentityCache(path)
+	if _, ok := cache.lookup("in:jane-smith"); ok {
+		t.Fatal("lookup in corrupt cache succeeded")
+	}
+	cache.store(Identity{PublicIdentifier: "jane-smith", ProfileURN: "urn:li:fsd_profile:A"})
+	if id, ok := cache.lookup("in:jane-smith"); !ok || id.ProfileURN != "urn:li:fsd_profile:A" {
+		t.Errorf("after rewrite: %+v, %v", id, ok)
+	}
+	b, _ := os.ReadFile(path)
+	if !strings.Contains(string(b), `"resolved_at"`) {
+		t.Errorf("cache file = %s", b)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/janitrai/bragcli/internal/urn"
 )
 
 const (
@@ -55,39 +56,32 @@ type Message struct {
 }
 
 // ---------------------------------------------------------------------------
-// URL encoding helpers
+// GraphQL variables
 // ---------------------------------------------------------------------------
 
-// encodeURNValue percent-encodes the special characters inside a URN value
-// for use within Bragnet's tuple-syntax variables. The structural tuple
-// characters (outer parens, colons, commas) remain literal in the query
-// string, but URN values embedded inside must be encoded.
-func encodeURNValue(urn string) string {
-	r := strings.NewReplacer(
-		":", "%3A",
-		"(", "%28",
-		")", "%29",
-		",", "%2C",
-	)
-	return r.Replace(urn)
+// conversationsVariables are the variables of messengerConversations.
+type conversationsVariables struct {
+	Query      conversationsQuery `restli:"query"`
+	Count      int                `restli:"count"`
+	MailboxURN string             `restli:"mailboxUrn"`
+	NextCursor string             `restli:"nextCursor,omitempty"`
 }
 
-// ---------------------------------------------------------------------------
-// Query ID helpers
-// ---------------------------------------------------------------------------
+type conversationsQuery struct {
+	PredicateUnions []predicateUnion `restli:"predicateUnions"`
+}
 
-func (bn *Bragnet) conversationsQueryID() string {
-	if bn.ConversationsQueryID != "" {
-		return bn.ConversationsQueryID
-	}
-	return DefaultConversationsQueryID
+type predicateUnion struct {
+	ConversationCategoryPredicate categoryPredicate `restli:"conversationCategoryPredicate"`
 }
 
-func (bn *Bragnet) messagesQueryID() string {
-	if bn.MessagesQueryID != "" {
-		return bn.MessagesQueryID
-	}
-	return DefaultMessagesQueryID
+type categoryPredicate struct {
+	Category string `restli:"category"`
+}
+
+// messagesVariables are the variables of messengerMessages.
+type messagesVariables struct {
+	ConversationURN string `restli:"conversationUrn"`
 }
 
 // ---------------------------------------------------------------------------
@@ -97,47 +91,86 @@ func (bn *Bragnet) messagesQueryID() string {
 // ListConversations fetches the user's inbox conversations.
 // profileURN must be the user's own urn:li:fsd_profile:… URN.
 func (bn *Bragnet) ListConversations(ctx context.Context, profileURN string, count int) ([]Conversation, error) {
-	if strings.TrimSpace(profileURN) == "" {
-		return nil, fmt.Errorf("empty profile URN")
+	convos, _, err := bn.ListConversationsPage(ctx, profileURN, "", count)
+	return convos, err
+}
+
+// ListConversationsPage fetches one page of inbox conversations starting
+// at cursor ("" for the newest). It also returns the cursor for the next
+// page, which is empty when the inbox is exhausted.
+func (bn *Bragnet) ListConversationsPage(ctx context.Context, profileURN, cursor string, count int) ([]Conversation, string, error) {
+	if err := checkURN("profile URN", profileURN, urn.TypeProfile); err != nil {
+		return nil, "", err
 	}
 	if count <= 0 {
 		count = 20
 	}
 
-	encodedURN := encodeURNValue(profileURN)
-	variables := fmt.Sprintf(
-		"(query:(predicateUnions:List((conversationCategoryPredicate:(category:INBOX)))),count:%d,mailboxUrn:%s)",
-		count, encodedURN,
-	)
-
-	rawQuery := fmt.Sprintf("variables=%s&queryId=%s", variables, bn.conversationsQueryID())
+	vars := conversationsVariables{
+		Query: conversationsQuery{PredicateUnions: []predicateUnion{
+			{ConversationCategoryPredicate: categoryPredicate{Category: "INBOX"}},
+		}},
+		Count:      count,
+		MailboxURN: profileURN,
+		NextCursor: cursor,
+	}
 
 	var raw map[string]any
-	if err := bn.c.DoRaw(ctx, "GET", messagingGraphQLPath, rawQuery, nil, &raw); err != nil {
-		return nil, err
+	if err := bn.graphQL(ctx, QueryConversations, messagingGraphQLPath, "", vars, &raw); err != nil {
+		return nil, "", err
+	}
+
+	// The cursor lives in the collection metadata under data.
+	next := findFirstString(raw["data"], "nextCursor")
+	n := NewNormalized(raw)
+	convos := parseConversations(n)
+	if err := bn.checkDrift(string(QueryConversations), n); err != nil {
+		return nil, "", err
 	}
+	return convos, next, nil
+}
 
-	return ParseConversations(raw), nil
+// ConversationsPager pages through the inbox using messaging cursors.
+func (bn *Bragnet) ConversationsPager(profileURN string, pageSize int) *Pager[Conversation] {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	return NewCursorPager(pageSize, func(ctx context.Context, cursor string, count int) ([]Conversation, string, error) {
+		return bn.ListConversationsPage(ctx, profileURN, cursor, count)
+	})
 }
 
 // GetMessages fetches messages in a conversation.
 func (bn *Bragnet) GetMessages(ctx context.Context, conversationURN string, count int) ([]Message, error) {
-	if strings.TrimSpace(conversationURN) == "" {
-		return nil, fmt.Errorf("empty conversation URN")
+	if err := checkURN("conversation URN", conversationURN, urn.TypeConversation); err != nil {
+		return nil, err
 	}
 	_ = count // the default endpoint returns recent messages; count is handled server-side
 
-	encodedURN := encodeURNValue(conversationURN)
-	variables := fmt.Sprintf("(conversationUrn:%s)", encodedURN)
-
-	rawQuery := fmt.Sprintf("variables=%s&queryId=%s", variables, bn.messagesQueryID())
+	vars := messagesVariables{ConversationURN: conversationURN}
 
 	var raw map[string]any
-	if err := bn.c.DoRaw(ctx, "GET", messagingGraphQLPath, rawQuery, nil, &raw); err != nil {
+	if err := bn.graphQL(ctx, QueryMessages, messagingGraphQLPath, "", vars, &raw); err != nil {
 		return nil, err
 	}
 
-	return ParseMessages(raw), nil
+	n := NewNormalized(raw)
+	msgs := parseMessages(n)
+	if err := bn.checkDrift(string(QueryMessages), n); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// checkURN validates s as a URN of type t; what names it in errors.
+func checkURN(what, s string, t urn.Type) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("empty %s", what)
+	}
+	if _, err := urn.ParseAs(s, t); err != nil {
+		return fmt.Errorf("bad %s: %w", what, err)
+	}
+	return nil
 }
 
 // generateTrackingID generates a 16-byte random tracking ID encoded as a
@@ -159,6 +192,12 @@ func (bn *Bragnet) SendMessage(ctx context.Context, mailboxURN, conversationURN,
 	if strings.TrimSpace(text) == "" {
 		return fmt.Errorf("empty message text")
 	}
+	if err := checkURN("mailbox URN", mailboxURN, urn.TypeProfile); err != nil {
+		return err
+	}
+	if err := checkURN("conversation URN", conversationURN, urn.TypeConversation); err != nil {
+		return err
+	}
 
 	payload := map[string]any{
 		"message": map[string]any{
@@ -191,6 +230,14 @@ func (bn *Bragnet) CreateConversationWithMessage(ctx context.Context, mailboxURN
 	if len(recipientURNs) == 0 {
 		return fmt.Errorf("no recipients")
 	}
+	if err := checkURN("mailbox URN", mailboxURN, urn.TypeProfile); err != nil {
+		return err
+	}
+	for _, r := range recipientURNs {
+		if err := checkURN("recipient URN", r, urn.TypeProfile); err != nil {
+			return err
+		}
+	}
 
 	payload := map[string]any{
 		"message": map[string]any{
@@ -215,88 +262,66 @@ func (bn *Bragnet) CreateConversationWithMessage(ctx context.Context, mailboxURN
 // Response parsing (exported for testing)
 // ---------------------------------------------------------------------------
 
+// Messaging entity $types in included[].
+const (
+	typeMessage      = "com.linkedin.messenger.Message"
+	typeConversation = "com.linkedin.messenger.Conversation"
+)
+
+// participantEntity is com.linkedin.messenger.MessagingParticipant.
+type participantEntity struct {
+	EntityURN       string `json:"entityUrn" li:"required"`
+	HostIdentityURN string `json:"hostIdentityUrn" li:"expected"`
+	ParticipantType struct {
+		Member struct {
+			FirstName TextViewModel `json:"firstName" li:"expected"`
+			LastName  TextViewModel `json:"lastName" li:"expected"`
+		} `json:"member"`
+	} `json:"participantType" li:"expected"`
+}
+
+// messageEntity is com.linkedin.messenger.Message.
+type messageEntity struct {
+	EntityURN   string                 `json:"entityUrn" li:"required"`
+	Body        TextViewModel          `json:"body" li:"expected"`
+	Sender      Ref[participantEntity] `json:"*sender" li:"expected"`
+	DeliveredAt int64                  `json:"deliveredAt" li:"expected"`
+}
+
+// conversationEntity is com.linkedin.messenger.Conversation.
+type conversationEntity struct {
+	EntityURN    string                   `json:"entityUrn"`
+	Participants []Ref[participantEntity] `json:"*conversationParticipants" li:"expected"`
+	LastMessage  Ref[messageEntity]       `json:"*lastMessage"`
+}
+
 // ParseConversations extracts conversations from a Bragnet messaging GraphQL response.
 func ParseConversations(raw map[string]any) []Conversation {
-	included, _ := raw["included"].([]any)
-	if len(included) == 0 {
-		return nil
-	}
-
-	// Phase 1: index participants by entityURN.
-	participantsByURN := make(map[string]Participant)
-	for _, item := range included {
-		m, ok := item.(map[string]any)
-		if !ok {
-			continue
-		}
-		t, _ := m["$type"].(string)
-		if t != "com.linkedin.messenger.MessagingParticipant" {
-			continue
-		}
-		p := parseParticipant(m)
-		if p.EntityURN != "" {
-			participantsByURN[p.EntityURN] = p
-		}
-	}
+	return parseConversations(NewNormalized(raw))
+}
 
-	// Phase 2: index messages by entityURN.
-	messagesByURN := make(map[string]Message)
-	for _, item := range included {
-		m, ok := item.(map[string]any)
-		if !ok {
-			continue
-		}
-		t, _ := m["$type"].(string)
-		if t != "com.linkedin.messenger.Message" {
-			continue
-		}
-		msg := parseMessage(m, participantsByURN)
-		if msg.EntityURN != "" {
-			messagesByURN[msg.EntityURN] = msg
-		}
+func parseConversations(n *Normalized) []Conversation {
+	if len(n.Included) == 0 {
+		return nil
 	}
+	n.expectType(typeConversation)
 
-	// Phase 3: build conversations.
 	var convos []Conversation
-	for _, item := range included {
-		m, ok := item.(map[string]any)
-		if !ok {
-			continue
-		}
-		t, _ := m["$type"].(string)
-		if t != "com.linkedin.messenger.Conversation" {
+	for _, m := range n.OfType(typeConversation) {
+		var ce conversationEntity
+		if err := n.Decode(m, &ce); err != nil {
 			continue
 		}
-
-		entityURN := getString(m, "entityUrn")
-		c := Conversation{EntityURN: entityURN}
-
-		// Resolve participants (try both *-prefixed and non-prefixed keys).
-		for _, key := range []string{"*conversationParticipants", "conversationParticipants"} {
-			if refs, ok := m[key].([]any); ok {
-				for _, ref := range refs {
-					if s, ok := ref.(string); ok {
-						if p, found := participantsByURN[s]; found {
-							c.Participants = append(c.Participants, p)
-						}
-					}
-				}
-				if len(c.Participants) > 0 {
-					break
-				}
+		c := Conversation{EntityURN: ce.EntityURN}
+		for _, ref := range ce.Participants {
+			if ref.Resolved() {
+				c.Participants = append(c.Participants, ref.Value.participant())
 			}
 		}
-
-		// Resolve last message.
-		for _, key := range []string{"*lastMessage", "lastMessage"} {
-			if ref, ok := m[key].(string); ok && ref != "" {
-				if msg, found := messagesByURN[ref]; found {
-					c.LastMessage = &msg
-					break
-				}
-			}
+		if ce.LastMessage.Resolved() {
+			msg := ce.LastMessage.Value.message()
+			c.LastMessage = &msg
 		}
-
 		convos = append(convos, c)
 	}
 
@@ -317,39 +342,22 @@ func ParseConversations(raw map[string]any) []Conversation {
 
 // ParseMessages extracts messages from a Bragnet messaging GraphQL response.
 func ParseMessages(raw map[string]any) []Message {
-	included, _ := raw["included"].([]any)
-	if len(included) == 0 {
-		return nil
-	}
+	return parseMessages(NewNormalized(raw))
+}
 
-	// Index participants for sender name resolution.
-	participantsByURN := make(map[string]Participant)
-	for _, item := range included {
-		m, ok := item.(map[string]any)
-		if !ok {
-			continue
-		}
-		t, _ := m["$type"].(string)
-		if t != "com.linkedin.messenger.MessagingParticipant" {
-			continue
-		}
-		p := parseParticipant(m)
-		if p.EntityURN != "" {
-			participantsByURN[p.EntityURN] = p
-		}
+func parseMessages(n *Normalized) []Message {
+	if len(n.Included) == 0 {
+		return nil
 	}
+	n.expectType(typeMessage)
 
 	var msgs []Message
-	for _, item := range included {
-		m, ok := item.(map[string]any)
-		if !ok {
+	for _, m := range n.OfType(typeMessage) {
+		var me messageEntity
+		if err := n.Decode(m, &me); err != nil {
 			continue
 		}
-		t, _ := m["$type"].(string)
-		if t != "com.linkedin.messenger.Message" {
-			continue
-		}
-		msgs = append(msgs, parseMessage(m, participantsByURN))
+		msgs = append(msgs, me.message())
 	}
 
 	// Sort by deliveredAt ascending (chronological reading order).
@@ -364,50 +372,25 @@ func ParseMessages(raw map[string]any) []Message {
 // Internal parsing helpers
 // ---------------------------------------------------------------------------
 
-func parseParticipant(m map[string]any) Participant {
-	p := Participant{
-		EntityURN: getString(m, "entityUrn"),
+func (p *participantEntity) participant() Participant {
+	return Participant{
+		EntityURN:  p.EntityURN,
+		ProfileURN: p.HostIdentityURN,
+		FirstName:  p.ParticipantType.Member.FirstName.Text,
+		LastName:   p.ParticipantType.Member.LastName.Text,
 	}
-
-	// hostIdentityUrn → fsd_profile URN
-	p.ProfileURN = getString(m, "hostIdentityUrn")
-
-	// Name: participantType → member → firstName/lastName → text
-	if pt, ok := m["participantType"].(map[string]any); ok {
-		if member, ok := pt["member"].(map[string]any); ok {
-			if fn, ok := member["firstName"].(map[string]any); ok {
-				p.FirstName, _ = fn["text"].(string)
-			}
-			if ln, ok := member["lastName"].(map[string]any); ok {
-				p.LastName, _ = ln["text"].(string)
-			}
-		}
-	}
-
-	return p
 }
 
-func parseMessage(m map[string]any, participants map[string]Participant) Message {
+func (m *messageEntity) message() Message {
 	msg := Message{
-		EntityURN:   getString(m, "entityUrn"),
-		DeliveredAt: getInt64(m, "deliveredAt"),
-	}
-
-	if body, ok := m["body"].(map[string]any); ok {
-		msg.BodyText, _ = body["text"].(string)
+		EntityURN:   m.EntityURN,
+		BodyText:    m.Body.Text,
+		SenderURN:   m.Sender.URN,
+		DeliveredAt: m.DeliveredAt,
 	}
-
-	// Sender reference: try *sender then sender.
-	msg.SenderURN = getString(m, "*sender")
-	if msg.SenderURN == "" {
-		msg.SenderURN, _ = m["sender"].(string)
+	if m.Sender.Resolved() {
+		msg.SenderName = m.Sender.Value.participant().FullName()
 	}
-
-	// Resolve sender name.
-	if p, ok := participants[msg.SenderURN]; ok {
-		msg.SenderName = p.FullName()
-	}
-
 	return msg
 }
 
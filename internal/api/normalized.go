@@ -0,0 +1,378 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Bragnet's normalized responses ({data, included}) store every entity once
+// in included[], keyed by entityUrn. Other entities point at it through
+// fields whose name starts with "*" and whose value is the URN (or a list of
+// URNs). Normalized indexes a response so typed structs can be decoded with
+// those references followed.
+//
+// Typed structs use plain json tags. Two extra conventions apply:
+//
+//   - A field of type Ref[T] (or []Ref[T]) tagged `json:"*name"` holds a
+//     reference; after decoding, Ref.Value points at the resolved entity.
+//   - A field tagged `li:"required"` must be non-zero, otherwise Decode
+//     returns a *MissingFieldsError.
+//   - A field tagged `li:"expected"` should be present in every response.
+//     When its key is absent Decode still succeeds, but the path is noted
+//     as schema drift (see Missing).
+
+// maxRefDepth bounds reference resolution so cyclic graphs terminate.
+const maxRefDepth = 4
+
+// Normalized is an indexed {data, included} response.
+type Normalized struct {
+	Data     map[string]any
+	Included []map[string]any
+
+	byURN map[string]map[string]any
+
+	// missing collects drift found so far: absent expected and required
+	// fields ("Type.path") and absent expected $types ("$type=…").
+	missing []string
+}
+
+// Missing returns the schema drift noted while decoding, sorted and
+// without duplicates.
+func (n *Normalized) Missing() []string {
+	out := append([]string(nil), n.missing...)
+	sort.Strings(out)
+	return dedupe(out)
+}
+
+// expectType notes drift when included[] has entities but none of type t,
+// which is what a renamed $type looks like.
+func (n *Normalized) expectType(t string) {
+	if len(n.Included) > 0 && len(n.OfType(t)) == 0 {
+		n.missing = append(n.missing, "$type="+t)
+	}
+}
+
+func (n *Normalized) noteMissing(entity map[string]any, paths []string) {
+	typ := "entity"
+	if t, _ := entity["$type"].(string); t != "" {
+		typ = t[strings.LastIndex(t, ".")+1:]
+	}
+	for _, p := range paths {
+		n.missing = append(n.missing, typ+"."+p)
+	}
+}
+
+// NewNormalized indexes a decoded normalized response.
+func NewNormalized(raw map[string]any) *Normalized {
+	n := &Normalized{byURN: make(map[string]map[string]any)}
+	n.Data, _ = raw["data"].(map[string]any)
+	items, _ := raw["included"].([]any)
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		n.Included = append(n.Included, m)
+		if urn, _ := m["entityUrn"].(string); urn != "" {
+			n.byURN[urn] = m
+		}
+	}
+	return n
+}
+
+// Lookup returns the included entity with the given entityUrn.
+func (n *Normalized) Lookup(urn string) (map[string]any, bool) {
+	m, ok := n.byURN[urn]
+	return m, ok
+}
+
+// OfType returns the included entities whose $type is exactly one of types,
+// in response order.
+func (n *Normalized) OfType(types ...string) []map[string]any {
+	var out []map[string]any
+	for _, m := range n.Included {
+		t, _ := m["$type"].(string)
+		for _, want := range types {
+			if t == want {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Decode unmarshals entity into out (a pointer to a struct), resolves its
+// Ref fields against the index and checks `li:"required"` fields.
+func (n *Normalized) Decode(entity map[string]any, out any) error {
+	return n.decode(entity, out, 0)
+}
+
+func (n *Normalized) decode(entity map[string]any, out any, depth int) error {
+	if entity == nil {
+		return fmt.Errorf("decode %T: nil entity", out)
+	}
+	aliased := aliasReferences(entity)
+	b, err := json.Marshal(aliased)
+	if err != nil {
+		return fmt.Errorf("decode %T: %w", out, err)
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("decode %s: %w", entityLabel(entity), err)
+	}
+	if depth < maxRefDepth {
+		if err := n.resolveRefs(reflect.ValueOf(out), depth); err != nil {
+			return err
+		}
+	}
+	n.noteMissing(entity, absentFields(aliased, reflect.TypeOf(out), ""))
+	if missing := missingFields(reflect.ValueOf(out), ""); len(missing) > 0 {
+		n.noteMissing(entity, missing)
+		return &MissingFieldsError{Entity: entityLabel(entity), Fields: missing}
+	}
+	return nil
+}
+
+// aliasReferences copies unprefixed reference fields ("sender":
+// "urn:li:…") to their "*"-prefixed name, since some endpoints omit the
+// star. Existing "*" fields win.
+func aliasReferences(entity map[string]any) map[string]any {
+	var out map[string]any
+	for k, v := range entity {
+		if strings.HasPrefix(k, "*") || k == "entityUrn" || !isURNValue(v) {
+			continue
+		}
+		if _, ok := entity["*"+k]; ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]any, len(entity)+1)
+			for kk, vv := range entity {
+				out[kk] = vv
+			}
+		}
+		out["*"+k] = v
+	}
+	if out == nil {
+		return entity
+	}
+	return out
+}
+
+func isURNValue(v any) bool {
+	switch t := v.(type) {
+	case string:
+		return strings.HasPrefix(t, "urn:")
+	case []any:
+		if len(t) == 0 {
+			return false
+		}
+		for _, e := range t {
+			if s, ok := e.(string); !ok || !strings.HasPrefix(s, "urn:") {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func entityLabel(entity map[string]any) string {
+	t, _ := entity["$type"].(string)
+	urn, _ := entity["entityUrn"].(string)
+	switch {
+	case t != "" && urn != "":
+		return t + " " + urn
+	case t != "":
+		return t
+	case urn != "":
+		return urn
+	}
+	return "entity"
+}
+
+// refResolver is implemented by *Ref[T].
+type refResolver interface {
+	resolve(n *Normalized, depth int) error
+}
+
+var refResolverType = reflect.TypeOf((*refResolver)(nil)).Elem()
+
+func (n *Normalized) resolveRefs(v reflect.Value, depth int) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return n.resolveRefs(v.Elem(), depth)
+	case reflect.Struct:
+		if v.CanAddr() && v.Addr().Type().Implements(refResolverType) {
+			return v.Addr().Interface().(refResolver).resolve(n, depth)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := n.resolveRefs(v.Field(i), depth); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := n.resolveRefs(v.Index(i), depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Ref is a reference to another entity in included[]. It unmarshals from
+// the URN string; Value is filled in by Normalized.Decode when the target
+// is present in the response.
+type Ref[T any] struct {
+	URN   string
+	Value *T
+}
+
+func (r *Ref[T]) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &r.URN)
+}
+
+func (r Ref[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.URN)
+}
+
+// Resolved reports whether the referenced entity was found.
+func (r Ref[T]) Resolved() bool { return r.Value != nil }
+
+func (r *Ref[T]) resolve(n *Normalized, depth int) error {
+	if r.URN == "" {
+		return nil
+	}
+	entity, ok := n.Lookup(r.URN)
+	if !ok {
+		return nil
+	}
+	v := new(T)
+	if err := n.decode(entity, v, depth+1); err != nil {
+		return err
+	}
+	r.Value = v
+	return nil
+}
+
+// MissingFieldsError reports required fields absent from an entity.
+type MissingFieldsError struct {
+	Entity string
+	Fields []string
+}
+
+func (e *MissingFieldsError) Error() string {
+	return fmt.Sprintf("%s: missing required field(s) %s", e.Entity, strings.Join(e.Fields, ", "))
+}
+
+// Unwrap makes a missing required field match ErrSchemaDrift.
+func (e *MissingFieldsError) Unwrap() error { return ErrSchemaDrift }
+
+// missingFields lists the json paths of `li:"required"` fields that are zero.
+// Referenced entities are validated when they are decoded, not here.
+func missingFields(v reflect.Value, prefix string) []string {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(refResolverType) {
+		return nil
+	}
+	var out []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		fv := v.Field(i)
+		if f.Tag.Get("li") == "required" && isZeroField(fv) {
+			out = append(out, path)
+			continue
+		}
+		if fv.Kind() == reflect.Struct || fv.Kind() == reflect.Pointer {
+			out = append(out, missingFields(fv, path)...)
+		}
+	}
+	return out
+}
+
+// absentFields lists the json paths of `li:"expected"` fields whose key is
+// missing from entity. Nested structs are checked only when their own key
+// is present; referenced entities are checked when they are decoded.
+func absentFields(entity map[string]any, t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || reflect.PointerTo(t).Implements(refResolverType) {
+		return nil
+	}
+	var out []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		v, ok := entity[name]
+		if f.Tag.Get("li") == "expected" && (!ok || v == nil) {
+			out = append(out, path)
+			continue
+		}
+		if sub, isMap := v.(map[string]any); isMap {
+			out = append(out, absentFields(sub, f.Type, path)...)
+		}
+	}
+	return out
+}
+
+func isZeroField(v reflect.Value) bool {
+	if v.CanAddr() && v.Addr().Type().Implements(refResolverType) {
+		return v.FieldByName("URN").String() == ""
+	}
+	return v.IsZero()
+}
+
+// TextViewModel is Bragnet's {"text": "…"} wrapper. It also accepts a bare
+// string, which some endpoints return instead.
+type TextViewModel struct {
+	Text string `json:"text"`
+}
+
+func (t *TextViewModel) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &t.Text)
+	}
+	type plain TextViewModel
+	return json.Unmarshal(b, (*plain)(t))
+}
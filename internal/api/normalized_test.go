@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustNormalized(t *testing.T, fixture string) *Normalized {
+	t.Helper()
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(fixture), &raw); err != nil {
+		t.Fatalf("bad fixture JSON: %v", err)
+	}
+	return NewNormalized(raw)
+}
+
+func TestNormalized_LookupAndOfType(t *testing.T) {
+	n := mustNormalized(t, conversationsFixture)
+
+	if _, ok := n.Lookup("urn:li:msg_message:(urn:li:fsd_profile:AAA,msg001)"); !ok {
+		t.Error("Lookup did not find message by entityUrn")
+	}
+	if _, ok := n.Lookup("urn:li:msg_message:nope"); ok {
+		t.Error("Lookup found a nonexistent URN")
+	}
+	if got := len(n.OfType(typeConversation)); got != 2 {
+		t.Errorf("OfType(Conversation) = %d entities, want 2", got)
+	}
+	if got := len(n.OfType(typeConversation, typeMessage)); got != 4 {
+		t.Errorf("OfType(Conversation, Message) = %d entities, want 4", got)
+	}
+}
+
+func TestNormalized_DecodeFollowsReferences(t *testing.T) {
+	n := mustNormalized(t, conversationsFixture)
+	entity, _ := n.Lookup("urn:li:msg_conversation:(urn:li:fsd_profile:AAA,thread001)")
+
+	var ce conversationEntity
+	if err := n.Decode(entity, &ce); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(ce.Participants) != 2 {
+		t.Fatalf("participants = %d, want 2", len(ce.Participants))
+	}
+	for i, p := range ce.Participants {
+		if !p.Resolved() {
+			t.Errorf("participant %d (%s) not resolved", i, p.URN)
+		}
+	}
+	if !ce.LastMessage.Resolved() {
+		t.Fatal("lastMessage not resolved")
+	}
+	// Nested reference: conversation → lastMessage → sender.
+	sender := ce.LastMessage.Value.Sender
+	if !sender.Resolved() {
+		t.Fatal("lastMessage.sender not resolved")
+	}
+	if got := sender.Value.ParticipantType.Member.FirstName.Text; got != "Jane" {
+		t.Errorf("sender first name = %q, want Jane", got)
+	}
+}
+
+func TestNormalized_DecodeUnresolvedReferenceKeepsURN(t *testing.T) {
+	n := mustNormalized(t, `{"included":[
+		{"$type":"com.linkedin.messenger.Message","entityUrn":"urn:li:msg_message:m1","*sender":"urn:li:msg_participant:gone"}
+	]}`)
+	entity, _ := n.Lookup("urn:li:msg_message:m1")
+
+	var me messageEntity
+	if err := n.Decode(entity, &me); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if me.Sender.Resolved() {
+		t.Error("sender should not resolve")
+	}
+	if me.Sender.URN != "urn:li:msg_participant:gone" {
+		t.Errorf("Sender.URN = %q", me.Sender.URN)
+	}
+}
+
+func TestNormalized_DecodeRequiredFieldMissing(t *testing.T) {
+	n := mustNormalized(t, `{"included":[
+		{"$type":"com.linkedin.messenger.Message","body":{"text":"no urn"}}
+	]}`)
+
+	var me messageEntity
+	err := n.Decode(n.Included[0], &me)
+	var missing *MissingFieldsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("err = %v, want *MissingFieldsError", err)
+	}
+	if len(missing.Fields) != 1 || missing.Fields[0] != "entityUrn" {
+		t.Errorf("Fields = %v, want [entityUrn]", missing.Fields)
+	}
+	if missing.Entity != typeMessage {
+		t.Errorf("Entity = %q, want %q", missing.Entity, typeMessage)
+	}
+}
+
+func TestNormalized_DecodeNestedRequiredPath(t *testing.T) {
+	type inner struct {
+		Text string `json:"text" li:"required"`
+	}
+	type outer struct {
+		Headline inner `json:"headline"`
+	}
+	n := mustNormalized(t, `{"included":[{"entityUrn":"urn:li:x:1","headline":{}}]}`)
+
+	var o outer
+	err := n.Decode(n.Included[0], &o)
+	var missing *MissingFieldsError
+	if !errors.As(err, &missing) || len(missing.Fields) != 1 || missing.Fields[0] != "headline.text" {
+		t.Fatalf("err = %v, want missing headline.text", err)
+	}
+}
+
+func TestNormalized_ExpectedFieldsNoteDrift(t *testing.T) {
+	n := mustNormalized(t, `{"included":[
+		{"$type":"com.linkedin.messenger.Message","entityUrn":"urn:li:msg_message:m1",
+		 "messageBody":{"text":"renamed"},"*sender":"urn:li:msg_participant:p1","deliveredAt":1},
+		{"$type":"com.linkedin.messenger.MessagingParticipant","entityUrn":"urn:li:msg_participant:p1",
+		 "hostIdentityUrn":"urn:li:fsd_profile:AAA","participantType":{"member":{"givenName":{"text":"Jane"},"lastName":{"text":"Smith"}}}},
+		{"$type":"com.linkedin.messenger.MessagingParticipant","entityUrn":"urn:li:msg_participant:org",
+		 "hostIdentityUrn":"urn:li:fsd_company:1","participantType":{"organization":{"name":{"text":"ACME"}}}}
+	]}`)
+
+	var me messageEntity
+	if err := n.Decode(n.Included[0], &me); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if me.Sender.Value.ParticipantType.Member.LastName.Text != "Smith" {
+		t.Errorf("partial decode lost lastName: %+v", me.Sender.Value)
+	}
+	var org participantEntity
+	if err := n.Decode(n.Included[2], &org); err != nil {
+		t.Fatalf("Decode org: %v", err)
+	}
+
+	want := []string{"Message.body", "MessagingParticipant.participantType.member.firstName"}
+	if got := n.Missing(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing = %v, want %v", got, want)
+	}
+}
+
+func TestNormalized_ExpectType(t *testing.T) {
+	n := mustNormalized(t, `{"included":[{"$type":"com.linkedin.messenger.ConversationV2","entityUrn":"urn:li:c:1"}]}`)
+	if got := ParseConversations(map[string]any{}); got != nil {
+		t.Fatalf("empty response: %v", got)
+	}
+	parseConversations(n)
+	if got := n.Missing(); len(got) != 1 || got[0] != "$type="+typeConversation {
+		t.Errorf("Missing = %v, want the conversation $type", got)
+	}
+
+	empty := mustNormalized(t, `{"included":[]}`)
+	parseConversations(empty)
+	if got := empty.Missing(); len(got) != 0 {
+		t.Errorf("empty inbox noted drift: %v", got)
+	}
+}
+
+func TestNormalized_CyclicReferencesTerminate(t *testing.T) {
+	type node struct {
+		EntityURN string    `json:"entityUrn"`
+		Next      Ref[node] `json:"*next"`
+	}
+	n := mustNormalized(t, `{"included":[
+		{"entityUrn":"urn:li:n:a","*next":"urn:li:n:b"},
+		{"entityUrn":"urn:li:n:b","*next":"urn:li:n:a"}
+	]}`)
+
+	var a node
+	if err := n.Decode(n.Included[0], &a); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !a.Next.Resolved() || a.Next.Value.EntityURN != "urn:li:n:b" {
+		t.Errorf("a.next = %+v", a.Next)
+	}
+}
+
+func TestTextViewModel_AcceptsStringOrObject(t *testing.T) {
+	var v struct {
+		A TextViewModel `json:"a"`
+		B TextViewModel `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":{"text":"obj"},"b":"plain"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A.Text != "obj" || v.B.Text != "plain" {
+		t.Errorf("got %+v", v)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pager walks a paginated list endpoint page by page. It handles both
+// start/count offset paging (search, feed) and opaque cursor paging
+// (messaging). Typical use:
+//
+//	p := bn.SearchPeoplePager("golang")
+//	for p.More() {
+//		items, err := p.Next(ctx)
+//		…
+//	}
+type Pager[T any] struct {
+	// Max stops the pager once this many items have been returned in
+	// total (0 means no limit). The final page is trimmed to fit.
+	Max int
+
+	pageSize int
+	offset   func(ctx context.Context, start, count int) ([]T, int, error)
+	cursor   func(ctx context.Context, cursor string, count int) ([]T, string, error)
+
+	start int
+	next  string
+	seen  int
+	done  bool
+}
+
+// NewOffsetPager pages through an endpoint addressed by start/count. fetch
+// returns a page's items and n, the number of results the server sent for
+// it, counting those that were filtered out or failed to decode. n moves
+// start forward, and a short or empty page by n ends the results.
+func NewOffsetPager[T any](pageSize int, fetch func(ctx context.Context, start, count int) (items []T, n int, err error)) *Pager[T] {
+	return &Pager[T]{pageSize: pageSize, offset: fetch}
+}
+
+// NewCursorPager pages through an endpoint that hands back an opaque
+// cursor for the next page. The end of results is an empty cursor or an
+// empty page.
+func NewCursorPager[T any](pageSize int, fetch func(ctx context.Context, cursor string, count int) ([]T, string, error)) *Pager[T] {
+	return &Pager[T]{pageSize: pageSize, cursor: fetch}
+}
+
+// More reports whether another call to Next may return items.
+func (p *Pager[T]) More() bool {
+	return !p.done && (p.Max <= 0 || p.seen < p.Max)
+}
+
+// Next fetches the next page. It returns an empty slice once the pager is
+// exhausted.
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	items, err := p.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.Max > 0 && p.seen+len(items) >= p.Max {
+		items = items[:p.Max-p.seen]
+		p.done = true
+	}
+	p.seen += len(items)
+	return items, nil
+}
+
+func (p *Pager[T]) fetch(ctx context.Context) ([]T, error) {
+	if !p.More() {
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		items []T
+		err   error
+	)
+	if p.offset != nil {
+		var n int
+		items, n, err = p.offset(ctx, p.start, p.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		p.start += n
+		if n <= 0 || (p.pageSize > 0 && n < p.pageSize) {
+			p.done = true
+		}
+	} else {
+		var next string
+		items, next, err = p.cursor(ctx, p.next, p.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 || next == "" || next == p.next {
+			p.done = true
+		}
+		p.next = next
+	}
+	return items, nil
+}
+
+// Skip discards the next n pages. Offset pagers jump straight to the
+// right start index; cursor pagers have to walk the pages.
+func (p *Pager[T]) Skip(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	if p.offset != nil {
+		if p.pageSize <= 0 {
+			return fmt.Errorf("cannot skip pages without a page size")
+		}
+		p.start += n * p.pageSize
+		return nil
+	}
+	for i := 0; i < n && !p.done; i++ {
+		if _, err := p.fetch(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// All drains the pager and returns every item, honoring Max.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var out []T
+	for p.More() {
+		items, err := p.Next(ctx)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, items...)
+	}
+	return out, nil
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+// offsetSource serves ints 0..total-1 in start/count pages.
+func offsetSource(total int, calls *int) func(context.Context, int, int) ([]int, int, error) {
+	return func(_ context.Context, start, count int) ([]int, int, error) {
+		*calls++
+		var out []int
+		for i := start; i < start+count && i < total; i++ {
+			out = append(out, i)
+		}
+		return out, len(out), nil
+	}
+}
+
+func TestOffsetPager_StopsOnShortPage(t *testing.T) {
+	var calls int
+	p := NewOffsetPager(10, offsetSource(25, &calls))
+
+	all, err := p.All(context.Background())
+	if err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	if len(all) != 25 || all[24] != 24 {
+		t.Errorf("got %d items, want 25", len(all))
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if p.More() {
+		t.Error("More() = true after exhaustion")
+	}
+}
+
+func TestOffsetPager_StopsOnEmptyPage(t *testing.T) {
+	var calls int
+	p := NewOffsetPager(5, offsetSource(10, &calls))
+
+	all, _ := p.All(context.Background())
+	if len(all) != 10 {
+		t.Errorf("got %d items, want 10", len(all))
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3 (last one empty)", calls)
+	}
+}
+
+func TestOffsetPager_CountsFilteredResults(t *testing.T) {
+	// Every third result is dropped by the caller's filter; paging must
+	// still move by whole pages and not stop at the first short one.
+	var starts []int
+	p := NewOffsetPager(10, func(ctx context.Context, start, count int) ([]int, int, error) {
+		starts = append(starts, start)
+		all, n, err := offsetSource(25, new(int))(ctx, start, count)
+		var kept []int
+		for _, v := range all {
+			if v%3 != 0 {
+				kept = append(kept, v)
+			}
+		}
+		return kept, n, err
+	})
+
+	all, err := p.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 16 || all[len(all)-1] != 23 {
+		t.Errorf("got %v, want the 16 kept items up to 23", all)
+	}
+	if fmt.Sprint(starts) != "[0 10 20]" {
+		t.Errorf("starts = %v, want [0 10 20]", starts)
+	}
+}
+
+func TestPager_MaxTrimsFinalPage(t *testing.T) {
+	var calls int
+	p := NewOffsetPager(10, offsetSource(100, &calls))
+	p.Max = 15
+
+	all, _ := p.All(context.Background())
+	if len(all) != 15 {
+		t.Errorf("got %d items, want 15", len(all))
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestOffsetPager_SkipJumpsStart(t *testing.T) {
+	var calls int
+	p := NewOffsetPager(10, offsetSource(100, &calls))
+	if err := p.Skip(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	items, _ := p.Next(context.Background())
+	if len(items) == 0 || items[0] != 20 {
+		t.Errorf("first item after Skip(2) = %v, want 20", items)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (skip is arithmetic)", calls)
+	}
+}
+
+func TestCursorPager(t *testing.T) {
+	pages := map[string]struct {
+		items []string
+		next  string
+	}{
+		"":   {[]string{"a", "b"}, "c1"},
+		"c1": {[]string{"c", "d"}, "c2"},
+		"c2": {[]string{"e"}, ""},
+	}
+	var cursors []string
+	p := NewCursorPager(2, func(_ context.Context, cursor string, count int) ([]string, string, error) {
+		cursors = append(cursors, cursor)
+		pg := pages[cursor]
+		return pg.items, pg.next, nil
+	})
+
+	all, err := p.All(context.Background())
+	if err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	if strings.Join(all, "") != "abcde" {
+		t.Errorf("items = %v", all)
+	}
+	if strings.Join(cursors, ",") != ",c1,c2" {
+		t.Errorf("cursors = %q", cursors)
+	}
+}
+
+func TestPager_RespectsContextCancellation(t *testing.T) {
+	var calls int
+	p := NewOffsetPager(10, offsetSource(100, &calls))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := p.Next(ctx); err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+	if calls != 0 {
+		t.Errorf("calls = %d, want 0", calls)
+	}
+}
+
+func TestPager_PropagatesErrors(t *testing.T) {
+	p := NewOffsetPager(10, func(context.Context, int, int) ([]int, int, error) {
+		return nil, 0, fmt.Errorf("boom")
+	})
+	if _, err := p.All(context.Background()); err == nil || err.Error() != "boom" {
+		t.Errorf("err = %v, want boom", err)
+	}
+}
+
+func TestConversationsPager_FollowsNextCursor(t *testing.T) {
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		if len(queries) == 1 {
+			// First page: same conversations fixture plus a cursor.
+			body := strings.Replace(conversationsFixture, `"included"`,
+				`"data": {"messengerConversationsBySyncToken": {"metadata": {"nextCursor": "abc=="}}}, "included"`, 1)
+			_, _ = io.WriteString(w, body)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data": {}, "included": []}`)
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(
+		auth.Cookies{LiAt: "test", JSessionID: "ajax:test"},
+		WithBaseURL(ts.URL+"/voyager/api"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	convos, err := NewBragnet(c).ConversationsPager("urn:li:fsd_profile:AAA", 2).All(context.Background())
+	if err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	if len(convos) != 2 {
+		t.Errorf("got %d conversations, want 2", len(convos))
+	}
+	if len(queries) != 2 {
+		t.Fatalf("requests = %d, want 2", len(queries))
+	}
+	if strings.Contains(queries[0], "nextCursor") {
+		t.Errorf("first request should not carry a cursor: %s", queries[0])
+	}
+	if !strings.Contains(queries[1], "nextCursor:abc%3D%3D") {
+		t.Errorf("second request missing encoded cursor: %s", queries[1])
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Query names a GraphQL operation. It is the part of a queryId before the
+// dot, e.g. "voyagerSearchDashClusters" in
+// "voyagerSearchDashClusters.ef3d0937fb65bd7812e32e5a85028e79"; the hash
+// after it changes whenever Bragnet redeploys the web client.
+type Query string
+
+const (
+	QuerySearchClusters Query = "voyagerSearchDashClusters"
+	QueryConversations  Query = "messengerConversations"
+	QueryMessages       Query = "messengerMessages"
+)
+
+// KnownQueries lists the operations bragcli uses, in a stable order.
+var KnownQueries = []Query{QuerySearchClusters, QueryConversations, QueryMessages}
+
+// defaultQueryIDs are the queryIds bragcli was released with. They are the
+// last resort after any ids from config.
+var defaultQueryIDs = map[Query][]string{
+	QuerySearchClusters: {DefaultSearchQueryID},
+	QueryConversations:  {DefaultConversationsQueryID},
+	QueryMessages:       {DefaultMessagesQueryID},
+}
+
+// ErrStaleQueryID means every known queryId for an operation was rejected,
+// which is how Bragnet answers after it rotates them.
+var ErrStaleQueryID = errors.New("graphql queryId rejected")
+
+// QueryRegistry maps operations to the queryIds to try, in order. When one
+// is rejected the next is tried, and an id that works moves to the front so
+// later calls (the next page, say) skip the stale ones.
+type QueryRegistry struct {
+	mu  sync.Mutex
+	ids map[Query][]string
+}
+
+// NewQueryRegistry returns a registry holding only the built-in defaults.
+func NewQueryRegistry() *QueryRegistry {
+	r := &QueryRegistry{ids: make(map[Query][]string, len(defaultQueryIDs))}
+	for q, ids := range defaultQueryIDs {
+		r.ids[q] = append([]string(nil), ids...)
+	}
+	return r
+}
+
+// Prefer puts ids ahead of the ones already registered for q. Empty and
+// duplicate ids are dropped.
+func (r *QueryRegistry) Prefer(q Query, ids ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids[q] = dedupe(append(append([]string(nil), ids...), r.ids[q]...))
+}
+
+// IDs returns the queryIds for q in the order they will be tried.
+func (r *QueryRegistry) IDs(q Query) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ids[q]...)
+}
+
+func dedupe(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := ids[:0]
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
+// graphQL runs a GraphQL GET against path, trying each registered queryId
+// for q until one isn't rejected. prefix is prepended to the query string
+// (e.g. "includeWebMetadata=true&").
+func (bn *Bragnet) graphQL(ctx context.Context, q Query, path, prefix string, variables any, out any) error {
+	ids := bn.Queries.IDs(q)
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: no queryId for %s", ErrStaleQueryID, q)
+	}
+	var err error
+	for _, id := range ids {
+		var query string
+		query, err = graphQLQuery(id, variables)
+		if err != nil {
+			return err
+		}
+		err = bn.c.DoRaw(ctx, http.MethodGet, path, prefix+query, nil, out)
+		if !rejectedQueryID(err) {
+			if err == nil {
+				bn.Queries.Prefer(q, id)
+			}
+			return err
+		}
+	}
+	return fmt.Errorf("%w: tried %d for %s: %w", ErrStaleQueryID, len(ids), q, err)
+}
+
+// rejectedQueryID reports whether err looks like a stale queryId: an
+// unclassified 400 or 500.
+func rejectedQueryID(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Kind != nil {
+		return false
+	}
+	return httpErr.StatusCode == http.StatusBadRequest || httpErr.StatusCode == http.StatusInternalServerError
+}
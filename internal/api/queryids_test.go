@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+func TestQueryRegistry_Defaults(t *testing.T) {
+	r := NewQueryRegistry()
+	for _, q := range KnownQueries {
+		if ids := r.IDs(q); len(ids) != 1 {
+			t.Errorf("%s: got %v, want one default", q, ids)
+		}
+	}
+	if got := r.IDs("voyagerUnknown"); len(got) != 0 {
+		t.Errorf("unknown query: got %v", got)
+	}
+}
+
+func TestQueryRegistry_Prefer(t *testing.T) {
+	r := NewQueryRegistry()
+	r.Prefer(QueryMessages, "messengerMessages.b", "", DefaultMessagesQueryID)
+	r.Prefer(QueryMessages, "messengerMessages.a", "messengerMessages.b")
+
+	want := []string{"messengerMessages.a", "messengerMessages.b", DefaultMessagesQueryID}
+	if got := r.IDs(QueryMessages); !reflect.DeepEqual(got, want) {
+		t.Errorf("IDs = %v, want %v", got, want)
+	}
+}
+
+// newQueryIDServer answers 200 only for the accepted queryId and status
+// for any other, recording the ids it saw.
+func newQueryIDServer(t *testing.T, accepted string, status int, seen *[]string) *Bragnet {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("queryId")
+		*seen = append(*seen, id)
+		if id != accepted {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		_, _ = io.WriteString(w, `{"data":{},"included":[]}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	c, err := NewClient(auth.Cookies{LiAt: "x", JSessionID: "ajax:y"}, WithBaseURL(ts.URL+"/voyager/api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBragnet(c)
+}
+
+func TestGraphQL_FallsBackOnRejectedQueryID(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusInternalServerError} {
+		var seen []string
+		bn := newQueryIDServer(t, DefaultMessagesQueryID, status, &seen)
+		bn.Queries.Prefer(QueryMessages, "messengerMessages.stale")
+
+		if _, err := bn.GetMessages(context.Background(), "urn:li:msg_conversation:(urn:li:fsd_profile:AAA,1)", 20); err != nil {
+			t.Fatalf("status %d: GetMessages: %v", status, err)
+		}
+		if _, err := bn.GetMessages(context.Background(), "urn:li:msg_conversation:(urn:li:fsd_profile:AAA,1)", 20); err != nil {
+			t.Fatalf("status %d: second GetMessages: %v", status, err)
+		}
+		// The working id is promoted, so the second call skips the stale one.
+		want := []string{"messengerMessages.stale", DefaultMessagesQueryID, DefaultMessagesQueryID}
+		if !reflect.DeepEqual(seen, want) {
+			t.Errorf("status %d: queryIds tried = %v, want %v", status, seen, want)
+		}
+	}
+}
+
+func TestGraphQL_AllRejected(t *testing.T) {
+	var seen []string
+	bn := newQueryIDServer(t, "none", http.StatusInternalServerError, &seen)
+	bn.Queries.Prefer(QuerySearchClusters, "voyagerSearchDashClusters.stale")
+
+	_, err := bn.SearchPeople(context.Background(), "go", 0, 10)
+	if !errors.Is(err, ErrStaleQueryID) {
+		t.Fatalf("err = %v, want ErrStaleQueryID", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("err = %v, want the last *HTTPError wrapped", err)
+	}
+	if len(seen) != 2 {
+		t.Errorf("tried %v, want both ids", seen)
+	}
+}
+
+func TestGraphQL_NoFallbackOnAuthFailure(t *testing.T) {
+	var seen []string
+	bn := newQueryIDServer(t, "none", http.StatusUnauthorized, &seen)
+	bn.Queries.Prefer(QueryConversations, "messengerConversations.other")
+
+	_, err := bn.ListConversations(context.Background(), "urn:li:fsd_profile:AAA", 20)
+	if !errors.Is(err, ErrSessionExpired) || errors.Is(err, ErrStaleQueryID) {
+		t.Fatalf("err = %v, want only ErrSessionExpired", err)
+	}
+	if len(seen) != 1 {
+		t.Errorf("tried %v, want one request", seen)
+	}
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EndpointClass groups endpoints that share a rate budget.
+type EndpointClass string
+
+const (
+	ClassRead       EndpointClass = "read"
+	ClassSearch     EndpointClass = "search"
+	ClassMessaging  EndpointClass = "messaging"
+	ClassInvitation EndpointClass = "invitation"
+	ClassWrite      EndpointClass = "write"
+)
+
+// ClassifyEndpoint maps a request to the rate budget it draws from.
+func ClassifyEndpoint(method, path string) EndpointClass {
+	path = strings.TrimPrefix(path, "/")
+	read := strings.EqualFold(method, http.MethodGet) || strings.EqualFold(method, http.MethodHead)
+	switch {
+	case strings.HasPrefix(path, "voyagerRelationshipsDashMemberRelationships"):
+		return ClassInvitation
+	case strings.HasPrefix(path, "voyagerMessaging") && !read:
+		return ClassMessaging
+	case path == "graphql" && read:
+		return ClassSearch
+	case read:
+		return ClassRead
+	default:
+		return ClassWrite
+	}
+}
+
+// Rate is a token-bucket budget: up to Burst requests at once, refilled at
+// PerMinute tokens per minute.
+type Rate struct {
+	Burst     int
+	PerMinute float64
+}
+
+// DefaultRates returns conservative budgets that stay well under the point
+// where Bragnet starts answering 429.
+func DefaultRates() map[EndpointClass]Rate {
+	return map[EndpointClass]Rate{
+		ClassRead:       {Burst: 20, PerMinute: 30},
+		ClassSearch:     {Burst: 5, PerMinute: 6},
+		ClassMessaging:  {Burst: 5, PerMinute: 4},
+		ClassInvitation: {Burst: 3, PerMinute: 1},
+		ClassWrite:      {Burst: 5, PerMinute: 6},
+	}
+}
+
+// Limiter paces requests before they are sent.
+type Limiter interface {
+	Wait(ctx context.Context, class EndpointClass) error
+}
+
+// WithRateLimiter installs a limiter consulted before every request attempt.
+func WithRateLimiter(l Limiter) Option {
+	return func(c *Client) error {
+		c.Limiter = l
+		return nil
+	}
+}
+
+// FileLimiter is a token-bucket limiter whose state lives in a JSON file
+// guarded by an exclusive file lock, so that concurrent bragcli processes
+// draw from the same budget.
+type FileLimiter struct {
+	Path  string
+	Rates map[EndpointClass]Rate
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// NewFileLimiter returns a limiter storing its state at path. Classes
+// missing from rates fall back to DefaultRates.
+func NewFileLimiter(path string, rates map[EndpointClass]Rate) *FileLimiter {
+	merged := DefaultRates()
+	for k, v := range rates {
+		merged[k] = v
+	}
+	return &FileLimiter{
+		Path:  path,
+		Rates: merged,
+		now:   time.Now,
+		sleep: sleepCtx,
+	}
+}
+
+type bucketState struct {
+	Tokens    float64   `json:"tokens"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Wait blocks until a token for class is available or ctx is done.
+func (l *FileLimiter) Wait(ctx context.Context, class EndpointClass) error {
+	rate, ok := l.Rates[class]
+	if !ok || rate.PerMinute <= 0 || rate.Burst <= 0 {
+		return nil
+	}
+	for {
+		wait, err := l.take(class, rate)
+		if err != nil {
+			return err
+		}
+		if wait == 0 {
+			return nil
+		}
+		if err := l.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// take refills the bucket and consumes a token if one is available. It
+// returns how long to wait before trying again when the bucket is empty.
+func (l *FileLimiter) take(class EndpointClass, rate Rate) (time.Duration, error) {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0o700); err != nil {
+		return 0, fmt.Errorf("create rate limit dir: %w", err)
+	}
+	unlock, err := lockFile(l.Path + ".lock")
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	state, err := l.load()
+	if err != nil {
+		return 0, err
+	}
+
+	now := l.now()
+	b, ok := state[class]
+	if !ok {
+		b = bucketState{Tokens: float64(rate.Burst), UpdatedAt: now}
+	}
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens += elapsed.Minutes() * rate.PerMinute
+	}
+	b.Tokens = math.Min(b.Tokens, float64(rate.Burst))
+	b.UpdatedAt = now
+
+	var wait time.Duration
+	if b.Tokens >= 1 {
+		b.Tokens--
+	} else {
+		wait = time.Duration((1 - b.Tokens) / rate.PerMinute * float64(time.Minute))
+		if wait <= 0 {
+			wait = time.Millisecond
+		}
+	}
+	state[class] = b
+
+	if err := l.store(state); err != nil {
+		return 0, err
+	}
+	return wait, nil
+}
+
+func (l *FileLimiter) load() (map[EndpointClass]bucketState, error) {
+	state := make(map[EndpointClass]bucketState)
+	b, err := os.ReadFile(l.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("read rate limit state: %w", err)
+	}
+	if len(b) == 0 {
+		return state, nil
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		// A corrupt state file only costs us one fresh burst; don't fail the request.
+		return make(map[EndpointClass]bucketState), nil
+	}
+	return state, nil
+}
+
+func (l *FileLimiter) store(state map[EndpointClass]bucketState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal rate limit state: %w", err)
+	}
+	// Written in place: the lock already serialises access.
+	if err := os.WriteFile(l.Path, b, 0o600); err != nil {
+		return fmt.Errorf("write rate limit state: %w", err)
+	}
+	return nil
+}
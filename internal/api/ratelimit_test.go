@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+func TestClassifyEndpoint(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   EndpointClass
+	}{
+		{"GET", "/me", ClassRead},
+		{"GET", "/identity/dash/profiles", ClassRead},
+		{"GET", "/graphql", ClassSearch},
+		{"GET", "voyagerMessagingGraphQL/graphql", ClassRead},
+		{"POST", "voyagerMessagingDashMessengerMessages", ClassMessaging},
+		{"POST", "/voyagerRelationshipsDashMemberRelationships", ClassInvitation},
+		{"POST", "/feed/dash/follows", ClassWrite},
+		{"POST", "/contentcreation/normShares", ClassWrite},
+	}
+	for _, tt := range tests {
+		if got := ClassifyEndpoint(tt.method, tt.path); got != tt.want {
+			t.Errorf("ClassifyEndpoint(%s, %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+// newTestLimiter returns a limiter with a controllable clock whose sleeps
+// advance that clock instead of blocking.
+func newTestLimiter(path string, now *time.Time, slept *[]time.Duration) *FileLimiter {
+	l := NewFileLimiter(path, map[EndpointClass]Rate{ClassSearch: {Burst: 2, PerMinute: 6}})
+	l.now = func() time.Time { return *now }
+	l.sleep = func(_ context.Context, d time.Duration) error {
+		*slept = append(*slept, d)
+		*now = now.Add(d)
+		return nil
+	}
+	return l
+}
+
+func TestFileLimiter_BurstThenWait(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	l := newTestLimiter(path, &now, &slept)
+
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(context.Background(), ClassSearch); err != nil {
+			t.Fatalf("Wait #%d: %v", i, err)
+		}
+	}
+	if len(slept) != 0 {
+		t.Fatalf("burst should not sleep, slept %v", slept)
+	}
+
+	if err := l.Wait(context.Background(), ClassSearch); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if len(slept) != 1 || slept[0] != 10*time.Second {
+		t.Errorf("slept = %v, want [10s] at 6/min", slept)
+	}
+}
+
+func TestFileLimiter_SharedAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var sleptA, sleptB []time.Duration
+	a := newTestLimiter(path, &now, &sleptA)
+	b := newTestLimiter(path, &now, &sleptB)
+
+	_ = a.Wait(context.Background(), ClassSearch)
+	_ = a.Wait(context.Background(), ClassSearch)
+	if err := b.Wait(context.Background(), ClassSearch); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if len(sleptB) != 1 {
+		t.Errorf("second limiter should see the drained bucket, slept %v", sleptB)
+	}
+}
+
+func TestFileLimiter_ClassesAreIndependent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	l := newTestLimiter(path, &now, &slept)
+
+	_ = l.Wait(context.Background(), ClassSearch)
+	_ = l.Wait(context.Background(), ClassSearch)
+	if err := l.Wait(context.Background(), ClassRead); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if len(slept) != 0 {
+		t.Errorf("read budget should be untouched by searches, slept %v", slept)
+	}
+}
+
+func TestFileLimiter_ContextCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	l := NewFileLimiter(path, map[EndpointClass]Rate{ClassInvitation: {Burst: 1, PerMinute: 0.001}})
+	_ = l.Wait(context.Background(), ClassInvitation)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx, ClassInvitation); err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+}
+
+type recordingLimiter struct{ classes []EndpointClass }
+
+func (r *recordingLimiter) Wait(_ context.Context, class EndpointClass) error {
+	r.classes = append(r.classes, class)
+	return nil
+}
+
+func TestClientDo_ConsultsLimiter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	rl := &recordingLimiter{}
+	c, err := NewClient(
+		auth.Cookies{LiAt: "x", JSessionID: "ajax:y"},
+		WithBaseURL(ts.URL+"/voyager/api"),
+		WithRateLimiter(rl),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DoRaw(context.Background(), "GET", "/graphql", "queryId=x", nil, nil); err != nil {
+		t.Fatalf("DoRaw: %v", err)
+	}
+	if len(rl.classes) != 1 || rl.classes[0] != ClassSearch {
+		t.Errorf("limiter classes = %v, want [search]", rl.classes)
+	}
+}
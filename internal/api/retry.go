@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how the client retries throttled and transient
+// failures. Only idempotent requests (GET/HEAD) are retried unless the
+// call opts in with WithWriteRetries, because replaying a write like
+// CreatePost or SendMessage can duplicate it on Bragnet's side.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values <= 1 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles per attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff. A Retry-After longer than MaxDelay is
+	// not waited out: the request fails immediately instead.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used by NewClient.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// NoRetry is a policy that never retries.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy replaces the client's retry policy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) error {
+		c.Retry = p
+		return nil
+	}
+}
+
+type writeRetriesKey struct{}
+
+// WithWriteRetries returns a context under which write requests (POST,
+// PUT, …) made with it are retried like reads. Use it around a single
+// call whose duplicate would be harmless, e.g. following someone twice:
+//
+//	li.Follow(api.WithWriteRetries(ctx), memberURN)
+func WithWriteRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writeRetriesKey{}, true)
+}
+
+// allows reports whether a request with method, made with ctx, may be
+// retried under this policy.
+func (p RetryPolicy) allows(ctx context.Context, method string) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead:
+		return true
+	default:
+		retry, _ := ctx.Value(writeRetriesKey{}).(bool)
+		return retry
+	}
+}
+
+// backoff returns the full-jitter exponential delay before retry number
+// attempt (1-based).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+	d := base << (attempt - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) + 1
+}
+
+// retryableStatus reports whether an HTTP status is worth retrying.
+// 500 is deliberately excluded: Bragnet returns it for stale GraphQL
+// queryIds, which no amount of waiting will fix.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given either as delay
+// seconds or as an HTTP date. It returns 0 when absent or malformed.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
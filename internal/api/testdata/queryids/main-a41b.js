@@ -0,0 +1,6 @@
+define("voyager-web/search/routes",["exports","@ember/routing"],function(e,t){"use strict";
+const n={searchClusters:{queryId:"voyagerSearchDashClusters.b0928897b71bd00a5a7291755dcd64f0",method:"GET"},
+typeahead:{queryId:"voyagerSearchDashTypeahead.1a2b3c4d5e6f708192a3b4c5d6e7f801"},
+legacyClusters:{queryId:"voyagerSearchDashClusters.ef3d0937fb65bd7812e32e5a85028e79"}};
+e.default=n;e.SEARCH_ID="voyagerSearchDashClusters.b0928897b71bd00a5a7291755dcd64f0";
+e.notAnId="voyagerSearchDashClusters.deadbeef";e.alsoNot="com.example.voyagerFoo.0123456789abcdef0123456789abcdefgg"});
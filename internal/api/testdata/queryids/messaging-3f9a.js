@@ -0,0 +1,2 @@
+const t={conversations:"messengerConversations.0d5e6781bbee71c3e51c8843c6519f48",messagesByConversation:"messengerMessages.5846eeb71c981f11e0134cb6626cc314",
+syncToken:"messengerConversationsBySyncToken.a1b2c3d4e5f60718293a4b5c6d7e8f90"};export{t as default};
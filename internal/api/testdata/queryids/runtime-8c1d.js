@@ -0,0 +1 @@
+(()=>{"use strict";var e={},t={};function r(n){var o=t[n];if(void 0!==o)return o.exports;var i=t[n]={exports:{}};return e[n](i,i.exports,r),i.exports}r.p="/static/js/";r.u=e=>"chunk-"+e+".js";})();
@@ -19,6 +19,11 @@ const (
 	// EnvDomain overrides the default target domain.
 	EnvDomain     = "BRAGNET_DOMAIN"
 	defaultDomain = "www.linkedin.com"
+
+	// EnvLiAt and EnvJSessionID give a session for one run, e.g. in CI,
+	// instead of a saved one.
+	EnvLiAt       = "BRAGNET_LI_AT"
+	EnvJSessionID = "BRAGNET_JSESSIONID"
 )
 
 // Domain returns the configured target domain.
@@ -37,6 +42,27 @@ func BaseURL() string {
 type Cookies struct {
 	LiAt       string
 	JSessionID string
+
+	// Expires is when li_at expires, or zero if that isn't known. It isn't
+	// sent, and credential stores don't keep it (see
+	// config.AuthConfig.ExpiresAt).
+	Expires time.Time
+}
+
+// CookiesFromEnv returns the session in $BRAGNET_LI_AT and
+// $BRAGNET_JSESSIONID. ok is false when neither is set; setting only one
+// is an error.
+func CookiesFromEnv() (c Cookies, ok bool, err error) {
+	c = Cookies{LiAt: os.Getenv(EnvLiAt), JSessionID: os.Getenv(EnvJSessionID)}
+	switch {
+	case c.LiAt == "" && c.JSessionID == "":
+		return Cookies{}, false, nil
+	case c.LiAt == "":
+		return Cookies{}, true, fmt.Errorf("$%s is set but $%s is not", EnvJSessionID, EnvLiAt)
+	case c.JSessionID == "":
+		return Cookies{}, true, fmt.Errorf("$%s is set but $%s is not", EnvLiAt, EnvJSessionID)
+	}
+	return c, true, nil
 }
 
 func (c Cookies) Valid() bool {
@@ -118,13 +144,56 @@ func OpenBrowser(rawURL string) error {
 	}
 }
 
+// Browser is what a session is tied to besides its cookies: requests
+// using it should present the same User-Agent and Accept-Language as the
+// browser that created it.
+type Browser struct {
+	UserAgent      string
+	AcceptLanguage string
+}
+
+// AcceptLanguage builds the Accept-Language header Chrome sends for the
+// preferred languages langs (navigator.languages), e.g.
+// ["en-US", "en", "de"] gives "en-US,en;q=0.9,de;q=0.8".
+func AcceptLanguage(langs []string) string {
+	var b strings.Builder
+	q := 10
+	for _, l := range langs {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l)
+		if q < 10 {
+			fmt.Fprintf(&b, ";q=0.%d", q)
+		}
+		q = max(q-1, 1)
+	}
+	return b.String()
+}
+
 type ChromeLoginOptions struct {
 	Timeout  time.Duration
 	Headless bool
 	LoginURL string
+
+	// ProfileDir is a Chrome user data dir to use and keep, so the login
+	// survives for later headless runs. Empty means a throwaway one.
+	ProfileDir string
+
+	// UserAgent replaces Chrome's own, so a headless run (which reports
+	// "HeadlessChrome") presents the User-Agent the session was created
+	// with.
+	UserAgent string
 }
 
-func LoginWithChrome(ctx context.Context, opts ChromeLoginOptions) (Cookies, error) {
+// LoginWithChrome opens Chrome on the login page and waits for the session
+// cookies to appear, returning them along with the browser's User-Agent
+// and languages.
+func LoginWithChrome(ctx context.Context, opts ChromeLoginOptions) (Cookies, Browser, error) {
 	if opts.Timeout <= 0 {
 		opts.Timeout = 10 * time.Minute
 	}
@@ -132,11 +201,22 @@ func LoginWithChrome(ctx context.Context, opts ChromeLoginOptions) (Cookies, err
 		opts.LoginURL = BaseURL() + "/login"
 	}
 
-	userDataDir, err := os.MkdirTemp("", "li-chrome-*")
+	cookieURL, err := url.Parse(opts.LoginURL)
 	if err != nil {
-		return Cookies{}, fmt.Errorf("create temp chrome profile: %w", err)
+		return Cookies{}, Browser{}, fmt.Errorf("parse login url: %w", err)
+	}
+	cookieURL.Path, cookieURL.RawQuery = "/", ""
+
+	userDataDir := opts.ProfileDir
+	if userDataDir == "" {
+		userDataDir, err = os.MkdirTemp("", "li-chrome-*")
+		if err != nil {
+			return Cookies{}, Browser{}, fmt.Errorf("create temp chrome profile: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(userDataDir) }()
+	} else if err := os.MkdirAll(userDataDir, 0o700); err != nil {
+		return Cookies{}, Browser{}, fmt.Errorf("create chrome profile: %w", err)
 	}
-	defer func() { _ = os.RemoveAll(userDataDir) }()
 
 	allocOpts := append([]chromedp.ExecAllocatorOption{}, chromedp.DefaultExecAllocatorOptions[:]...)
 	allocOpts = append(allocOpts,
@@ -146,6 +226,9 @@ func LoginWithChrome(ctx context.Context, opts ChromeLoginOptions) (Cookies, err
 		chromedp.Flag("no-first-run", true),
 		chromedp.Flag("no-default-browser-check", true),
 	)
+	if opts.UserAgent != "" {
+		allocOpts = append(allocOpts, chromedp.UserAgent(opts.UserAgent))
+	}
 	if runtime.GOOS == "linux" {
 		// Commonly required in container-like environments.
 		allocOpts = append(allocOpts, chromedp.Flag("no-sandbox", true))
@@ -160,11 +243,17 @@ func LoginWithChrome(ctx context.Context, opts ChromeLoginOptions) (Cookies, err
 	timeoutCtx, cancelTimeout := context.WithTimeout(bctx, opts.Timeout)
 	defer cancelTimeout()
 
+	var (
+		browser Browser
+		langs   []string
+	)
 	if err := chromedp.Run(timeoutCtx,
 		network.Enable(),
 		chromedp.Navigate(opts.LoginURL),
+		chromedp.Evaluate(`navigator.userAgent`, &browser.UserAgent),
+		chromedp.Evaluate(`navigator.languages`, &langs),
 	); err != nil {
-		return Cookies{}, fmt.Errorf("launch chrome: %w", err)
+		return Cookies{}, Browser{}, fmt.Errorf("launch chrome: %w", err)
 	}
 
 	ticker := time.NewTicker(2 * time.Second)
@@ -172,18 +261,22 @@ func LoginWithChrome(ctx context.Context, opts ChromeLoginOptions) (Cookies, err
 
 	for {
 		var out Cookies
-		cookies, err := network.GetCookies().WithUrls([]string{BaseURL() + "/"}).Do(timeoutCtx)
+		cookies, err := network.GetCookies().WithUrls([]string{cookieURL.String()}).Do(timeoutCtx)
 		if err == nil {
 			for _, ck := range cookies {
 				switch ck.Name {
 				case "li_at":
 					out.LiAt = ck.Value
+					if !ck.Session && ck.Expires > 0 {
+						out.Expires = time.Unix(int64(ck.Expires), 0).UTC()
+					}
 				case "JSESSIONID":
 					out.JSessionID = ck.Value
 				}
 			}
 			if out.Valid() {
-				return out, nil
+				browser.AcceptLanguage = AcceptLanguage(langs)
+				return out, browser, nil
 			}
 		}
 
@@ -191,7 +284,7 @@ func LoginWithChrome(ctx context.Context, opts ChromeLoginOptions) (Cookies, err
 		case <-ticker.C:
 			continue
 		case <-timeoutCtx.Done():
-			return Cookies{}, fmt.Errorf("timed out waiting for auth cookies (li_at, JSESSIONID)")
+			return Cookies{}, Browser{}, fmt.Errorf("timed out waiting for auth cookies (li_at, JSESSIONID)")
 		}
 	}
 }
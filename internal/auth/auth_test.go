@@ -155,3 +155,44 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		langs []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"en-US"}, "en-US"},
+		{[]string{"en-US", "en", "de"}, "en-US,en;q=0.9,de;q=0.8"},
+		{[]string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}, "a,b;q=0.9,c;q=0.8,d;q=0.7,e;q=0.6,f;q=0.5,g;q=0.4,h;q=0.3,i;q=0.2,j;q=0.1,k;q=0.1,l;q=0.1"},
+	}
+	for _, tt := range tests {
+		if got := AcceptLanguage(tt.langs); got != tt.want {
+			t.Errorf("AcceptLanguage(%q) = %q, want %q", tt.langs, got, tt.want)
+		}
+	}
+}
+
+func TestCookiesFromEnv(t *testing.T) {
+	tests := []struct {
+		liAt, jsession string
+		wantOK         bool
+		wantErr        bool
+	}{
+		{"", "", false, false},
+		{"a", `"ajax:1"`, true, false},
+		{"a", "", true, true},
+		{"", "ajax:1", true, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(EnvLiAt, tt.liAt)
+		t.Setenv(EnvJSessionID, tt.jsession)
+		c, ok, err := CookiesFromEnv()
+		if ok != tt.wantOK || (err != nil) != tt.wantErr {
+			t.Errorf("li_at %q, JSESSIONID %q: ok = %v, err = %v", tt.liAt, tt.jsession, ok, err)
+		}
+		if err == nil && ok && (c.LiAt != tt.liAt || c.JSessionID != tt.jsession) {
+			t.Errorf("cookies = %+v", c)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/janitrai/bragcli/internal/sqlite"
+)
+
+// Cookie file formats read by ImportCookies and written by ExportCookies.
+const (
+	FormatNetscape = "netscape" // cookies.txt, as written by curl and browser extensions
+	FormatFirefox  = "firefox"  // a Firefox profile's cookies.sqlite
+	FormatChromium = "chromium" // a Chrome/Chromium/Edge profile's Cookies database
+)
+
+// ErrNoCookies means a cookie file has no usable session for the domain.
+var ErrNoCookies = errors.New("no li_at and JSESSIONID cookies")
+
+// fileCookie is a cookie as read from one of the formats.
+type fileCookie struct {
+	host    string // leading dot for domain cookies
+	name    string
+	value   string
+	expires time.Time // zero for session cookies
+	// encrypted is set for Chromium cookies whose value is encrypted with
+	// an OS keyring key, which bragcli can't read.
+	encrypted bool
+	// partitioned is set for cookies kept apart from the regular jar: a
+	// Firefox container or a Chromium partitioned (CHIPS) cookie.
+	partitioned bool
+}
+
+// ImportCookies reads the session cookies for domain from the cookie file
+// at path and reports which format it was in. Firefox and Chromium
+// databases can be read while the browser is running.
+func ImportCookies(path, domain string) (Cookies, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Cookies{}, "", err
+	}
+	head := make([]byte, 16)
+	n, _ := io.ReadFull(f, head)
+	_ = f.Close()
+
+	var (
+		format  string
+		cookies []fileCookie
+	)
+	if sqlite.IsDatabase(head[:n]) {
+		db, err := sqlite.Open(path)
+		if err != nil {
+			return Cookies{}, "", fmt.Errorf("read %s: %w", path, err)
+		}
+		switch {
+		case db.HasTable("moz_cookies"):
+			format = FormatFirefox
+			cookies, err = readFirefox(db)
+		case db.HasTable("cookies"):
+			format = FormatChromium
+			cookies, err = readChromium(db)
+		default:
+			return Cookies{}, "", fmt.Errorf("%s is a SQLite database but not a Firefox or Chromium cookie store", path)
+		}
+		if err != nil {
+			return Cookies{}, format, fmt.Errorf("read %s: %w", path, err)
+		}
+	} else {
+		format = FormatNetscape
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return Cookies{}, format, err
+		}
+		cookies = readNetscape(b)
+	}
+
+	c, err := pickSession(cookies, domain, time.Now())
+	if err != nil {
+		return Cookies{}, format, fmt.Errorf("%s (%s): %w", path, format, err)
+	}
+	return c, format, nil
+}
+
+// ReadCookies reads a session from r: either a Cookie header
+// ("li_at=…; JSESSIONID=…", with pairs split by semicolons or newlines) or
+// a cookies.txt, whose cookies for domain are used.
+func ReadCookies(r io.Reader, domain string) (Cookies, error) {
+	b, err := io.ReadAll(io.LimitReader(r, 1<<20))
+	if err != nil {
+		return Cookies{}, err
+	}
+	if bytes.ContainsRune(b, '\t') {
+		return pickSession(readNetscape(b), domain, time.Now())
+	}
+	s := strings.TrimSpace(string(b))
+	if len(s) >= len("cookie:") && strings.EqualFold(s[:len("cookie:")], "cookie:") {
+		s = s[len("cookie:"):]
+	}
+	c := parseSecret(strings.NewReplacer("\r\n", ";", "\n", ";").Replace(s))
+	if !c.Valid() {
+		return Cookies{}, fmt.Errorf("%w in the Cookie header", ErrNoCookies)
+	}
+	return c, nil
+}
+
+// ExportCookies writes c for domain in the named format. Only
+// FormatNetscape can be written. Both cookies get c.Expires, or are
+// session cookies if it isn't known.
+func ExportCookies(w io.Writer, format, domain string, c Cookies) error {
+	if format != FormatNetscape {
+		return fmt.Errorf("unsupported cookie format %q (want %s)", format, FormatNetscape)
+	}
+	host := "." + domain
+	var expires int64
+	if !c.Expires.IsZero() {
+		expires = c.Expires.Unix()
+	}
+	_, err := fmt.Fprintf(w, "# Netscape HTTP Cookie File\n"+
+		"# A live session for %s. Anyone with this file can use it.\n\n"+
+		"#HttpOnly_%s\tTRUE\t/\tTRUE\t%d\tli_at\t%s\n"+
+		"%s\tTRUE\t/\tTRUE\t%d\tJSESSIONID\t%s\n",
+		domain, host, expires, c.LiAt, host, expires, c.JSessionIDCookieValue())
+	return err
+}
+
+// pickSession chooses li_at and JSESSIONID from cookies: unexpired, set
+// for domain, outside any container or partition if possible, and from
+// the most specific host.
+func pickSession(cookies []fileCookie, domain string, now time.Time) (Cookies, error) {
+	domain = strings.ToLower(domain)
+	best := make(map[string]fileCookie)
+	encrypted := false
+	for _, ck := range cookies {
+		if ck.name != "li_at" && ck.name != "JSESSIONID" {
+			continue
+		}
+		if !hostMatches(ck.host, domain) || (!ck.expires.IsZero() && ck.expires.Before(now)) {
+			continue
+		}
+		if ck.encrypted {
+			encrypted = true
+			continue
+		}
+		if cur, ok := best[ck.name]; !ok || betterCookie(ck, cur) {
+			best[ck.name] = ck
+		}
+	}
+
+	c := Cookies{
+		LiAt:       best["li_at"].value,
+		JSessionID: best["JSESSIONID"].value,
+		Expires:    best["li_at"].expires.UTC(),
+	}
+	switch {
+	case c.Valid():
+		return c, nil
+	case encrypted:
+		return Cookies{}, fmt.Errorf("%w for %s: the browser encrypted them with its OS keyring key, which bragcli can't read; export cookies.txt from the browser instead", ErrNoCookies, domain)
+	}
+	return Cookies{}, fmt.Errorf("%w for %s", ErrNoCookies, domain)
+}
+
+func betterCookie(a, b fileCookie) bool {
+	if a.partitioned != b.partitioned {
+		return !a.partitioned
+	}
+	if len(a.host) != len(b.host) {
+		return len(a.host) > len(b.host)
+	}
+	return a.expires.After(b.expires)
+}
+
+// hostMatches reports whether a cookie for host is sent to domain: a
+// domain cookie (leading dot) to the domain and its subdomains, a host
+// cookie only to that host.
+func hostMatches(host, domain string) bool {
+	host = strings.ToLower(host)
+	if d, ok := strings.CutPrefix(host, "."); ok {
+		return domain == d || strings.HasSuffix(domain, "."+d)
+	}
+	return domain == host
+}
+
+// ---------------------------------------------------------------------------
+// Formats
+// ---------------------------------------------------------------------------
+
+// readNetscape parses a cookies.txt: one cookie per line with tab-separated
+// domain, include-subdomains flag, path, secure flag, expiry (Unix
+// seconds, 0 for a session cookie), name and value. Lines starting with
+// "#HttpOnly_" are cookies; other "#" lines are comments.
+func readNetscape(b []byte) []fileCookie {
+	var out []fileCookie
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.SplitN(line, "\t", 7)
+		if len(f) != 7 {
+			continue
+		}
+		ck := fileCookie{host: f[0], name: f[5], value: f[6]}
+		if strings.EqualFold(f[1], "TRUE") && !strings.HasPrefix(ck.host, ".") {
+			ck.host = "." + ck.host
+		}
+		if secs, err := strconv.ParseInt(f[4], 10, 64); err == nil && secs > 0 {
+			ck.expires = time.Unix(secs, 0)
+		}
+		out = append(out, ck)
+	}
+	return out
+}
+
+// readFirefox reads moz_cookies. expiry is in seconds, or milliseconds in
+// recent versions.
+func readFirefox(db *sqlite.DB) ([]fileCookie, error) {
+	var out []fileCookie
+	err := db.Scan("moz_cookies", func(r sqlite.Row) error {
+		ck := fileCookie{
+			host:        r.String("host"),
+			name:        r.String("name"),
+			value:       r.String("value"),
+			partitioned: r.String("originAttributes") != "",
+		}
+		if exp := r.Int("expiry"); exp > 1e11 {
+			ck.expires = time.UnixMilli(exp)
+		} else if exp > 0 {
+			ck.expires = time.Unix(exp, 0)
+		}
+		out = append(out, ck)
+		return nil
+	})
+	return out, err
+}
+
+// chromiumEpochOffset is the number of microseconds from 1601-01-01,
+// where Chromium's timestamps start, to the Unix epoch.
+const chromiumEpochOffset = 11644473600 * 1e6
+
+// readChromium reads the cookies table. Values are in value when stored
+// in the clear; otherwise value is empty and encrypted_value holds them,
+// prefixed with a version tag like "v10".
+func readChromium(db *sqlite.DB) ([]fileCookie, error) {
+	var out []fileCookie
+	err := db.Scan("cookies", func(r sqlite.Row) error {
+		ck := fileCookie{
+			host:        r.String("host_key"),
+			name:        r.String("name"),
+			value:       r.String("value"),
+			partitioned: r.String("top_frame_site_key") != "",
+		}
+		if ck.value == "" {
+			if enc, _ := r["encrypted_value"].([]byte); len(enc) > 0 {
+				ck.encrypted = true
+			}
+		}
+		if us := r.Int("expires_utc"); us > 0 {
+			ck.expires = time.UnixMicro(us - chromiumEpochOffset)
+		}
+		out = append(out, ck)
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The .sqlite files in testdata are written by the sqlite3 CLI from the
+// .sql files beside them.
+
+func TestImportCookies(t *testing.T) {
+	tests := []struct {
+		file   string
+		format string
+		want   Cookies
+	}{
+		{"cookies.txt", FormatNetscape, Cookies{LiAt: "txt-li-at", JSessionID: `"ajax:txt"`}},
+		{"firefox-cookies.sqlite", FormatFirefox, Cookies{LiAt: "ff-li-at", JSessionID: `"ajax:ff"`}},
+		{"chromium-cookies.sqlite", FormatChromium, Cookies{LiAt: "cr-li-at", JSessionID: `"ajax:cr"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, format, err := ImportCookies(filepath.Join("testdata", tt.file), "www.example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tt.format || got.LiAt != tt.want.LiAt || got.JSessionID != tt.want.JSessionID {
+				t.Errorf("got %+v (%s), want %+v (%s)", got, format, tt.want, tt.format)
+			}
+			if want := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC); !got.Expires.Equal(want) {
+				t.Errorf("expires = %v, want %v", got.Expires, want)
+			}
+		})
+	}
+}
+
+func TestImportCookies_Errors(t *testing.T) {
+	_, _, err := ImportCookies(filepath.Join("testdata", "chromium-cookies.sqlite"), "www.encrypted.example")
+	if !errors.Is(err, ErrNoCookies) || !strings.Contains(err.Error(), "encrypted") {
+		t.Errorf("encrypted cookies: err = %v", err)
+	}
+	_, _, err = ImportCookies(filepath.Join("testdata", "cookies.txt"), "www.example.net")
+	if !errors.Is(err, ErrNoCookies) {
+		t.Errorf("other domain: err = %v, want ErrNoCookies", err)
+	}
+	if _, _, err := ImportCookies(filepath.Join("testdata", "missing.txt"), "www.example.com"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: err = %v", err)
+	}
+}
+
+func TestReadCookies(t *testing.T) {
+	txt, err := os.ReadFile(filepath.Join("testdata", "cookies.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, in string
+		want     Cookies
+	}{
+		{"header", `li_at=abc; JSESSIONID="ajax:123"`, Cookies{LiAt: "abc", JSessionID: `"ajax:123"`}},
+		{"with name and lines", "Cookie: lang=en\r\nJSESSIONID=ajax:123\nli_at=abc\n", Cookies{LiAt: "abc", JSessionID: "ajax:123"}},
+		{"cookies.txt", string(txt), Cookies{LiAt: "txt-li-at", JSessionID: `"ajax:txt"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCookies(strings.NewReader(tt.in), "www.example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.LiAt != tt.want.LiAt || got.JSessionID != tt.want.JSessionID {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if _, err := ReadCookies(strings.NewReader("li_at=abc\n"), "www.example.com"); !errors.Is(err, ErrNoCookies) {
+		t.Errorf("li_at only: err = %v, want ErrNoCookies", err)
+	}
+}
+
+func TestExportCookies(t *testing.T) {
+	c := Cookies{LiAt: "abc", JSessionID: "ajax:123", Expires: time.Date(2100, 1, 2, 3, 4, 5, 0, time.UTC)}
+	var buf bytes.Buffer
+	if err := ExportCookies(&buf, FormatNetscape, "www.example.com", c); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := ImportCookies(path, "www.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LiAt != c.LiAt || got.CSRFToken() != c.CSRFToken() || !got.Expires.Equal(c.Expires) {
+		t.Errorf("round trip = %+v, want %+v", got, c)
+	}
+
+	// Without a known expiry both are session cookies.
+	buf.Reset()
+	if err := ExportCookies(&buf, FormatNetscape, "www.example.com", Cookies{LiAt: "abc", JSessionID: "ajax:123"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "\tTRUE\t0\t"); n != 2 {
+		t.Errorf("session cookie lines = %d, want 2:\n%s", n, buf.String())
+	}
+
+	if err := ExportCookies(&buf, "json", "www.example.com", c); err == nil {
+		t.Error("unknown format: no error")
+	}
+}
+
+func TestHostMatches(t *testing.T) {
+	tests := []struct {
+		host, domain string
+		want         bool
+	}{
+		{".example.com", "www.example.com", true},
+		{".example.com", "example.com", true},
+		{".www.example.com", "www.example.com", true},
+		{"www.example.com", "www.example.com", true},
+		{"example.com", "www.example.com", false},
+		{".ample.com", "www.example.com", false},
+		{".Example.COM", "www.example.com", true},
+	}
+	for _, tt := range tests {
+		if got := hostMatches(tt.host, tt.domain); got != tt.want {
+			t.Errorf("hostMatches(%q, %q) = %v", tt.host, tt.domain, got)
+		}
+	}
+}
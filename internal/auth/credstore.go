@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/janitrai/bragcli/internal/config"
+)
+
+// CredentialStore keeps the session cookies of each account. Which one is
+// used is a config setting; the rest of the config never holds secrets
+// unless the store is PlaintextStore.
+type CredentialStore interface {
+	// Get returns the cookies saved for account, or an error wrapping
+	// ErrNoCredentials if there are none.
+	Get(account string) (Cookies, error)
+	// Put saves c for account, replacing what was there.
+	Put(account string, c Cookies) error
+	// Delete forgets account's cookies. Deleting nothing is not an error.
+	Delete(account string) error
+}
+
+// Credential store kinds, as named by the config's credential_store.
+const (
+	StorePlaintext = "plaintext"
+	StoreEncrypted = "encrypted"
+	StoreHelper    = "helper"
+)
+
+// ErrNoCredentials means the store has nothing saved for an account.
+var ErrNoCredentials = errors.New("no saved credentials")
+
+// ---------------------------------------------------------------------------
+// Plaintext
+// ---------------------------------------------------------------------------
+
+// PlaintextStore keeps cookies in the accounts of the config file itself,
+// readable by anyone who can read the file (it is written 0600).
+type PlaintextStore struct {
+	ConfigPath string
+}
+
+func (s PlaintextStore) String() string { return s.ConfigPath }
+
+func (s PlaintextStore) Get(account string) (Cookies, error) {
+	cfg, err := s.load(account)
+	if err != nil {
+		return Cookies{}, err
+	}
+	if !cfg.Auth.LoggedIn() {
+		return Cookies{}, fmt.Errorf("%w for account %q", ErrNoCredentials, account)
+	}
+	return Cookies{LiAt: cfg.Auth.LiAt, JSessionID: cfg.Auth.JSessionID}, nil
+}
+
+func (s PlaintextStore) Put(account string, c Cookies) error {
+	cfg, err := config.Load(s.ConfigPath)
+	if err != nil {
+		return err
+	}
+	cfg.AddAccount(account)
+	if err := cfg.Select(account); err != nil {
+		return err
+	}
+	cfg.Auth.LiAt = c.LiAt
+	cfg.Auth.JSessionID = c.JSessionID
+	return config.Save(s.ConfigPath, cfg)
+}
+
+func (s PlaintextStore) Delete(account string) error {
+	cfg, err := s.load(account)
+	if errors.Is(err, config.ErrUnknownAccount) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cfg.Auth.LiAt = ""
+	cfg.Auth.JSessionID = ""
+	return config.Save(s.ConfigPath, cfg)
+}
+
+// load re-reads the config so cookies written by another process (after a
+// rotation, say) are seen.
+func (s PlaintextStore) load(account string) (config.Config, error) {
+	cfg, err := config.Load(s.ConfigPath)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if err := cfg.Select(account); err != nil {
+		return config.Config{}, err
+	}
+	return cfg, nil
+}
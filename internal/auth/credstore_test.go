@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/config"
+)
+
+var testCookies = Cookies{LiAt: "secret-li-at", JSessionID: `"ajax:123"`}
+
+// testStore runs the behavior every CredentialStore shares.
+func testStore(t *testing.T, s CredentialStore) {
+	t.Helper()
+	if _, err := s.Get("work"); !errors.Is(err, ErrNoCredentials) && !errors.Is(err, config.ErrUnknownAccount) {
+		t.Fatalf("Get on empty store: err = %v, want ErrNoCredentials", err)
+	}
+	if err := s.Put("work", testCookies); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := s.Get("work")
+	if err != nil || got != testCookies {
+		t.Fatalf("Get = %+v, %v; want %+v", got, err, testCookies)
+	}
+	if err := s.Delete("work"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get("work"); err == nil {
+		t.Fatal("Get after Delete succeeded")
+	}
+	if err := s.Delete("work"); err != nil {
+		t.Fatalf("Delete twice: %v", err)
+	}
+}
+
+func TestPlaintextStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	testStore(t, PlaintextStore{ConfigPath: path})
+}
+
+func TestEncryptedStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	var asked []bool
+	s := &EncryptedStore{
+		Path: path,
+		Passphrase: func(confirm bool) (string, error) {
+			asked = append(asked, confirm)
+			return "hunter2", nil
+		},
+		n: 1 << 10,
+	}
+	testStore(t, s)
+	if len(asked) != 1 || !asked[0] {
+		t.Errorf("passphrase asked %v, want once with confirm", asked)
+	}
+
+	if err := s.Put("work", testCookies); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), testCookies.LiAt) {
+		t.Error("credentials file contains li_at in the clear")
+	}
+
+	wrong := &EncryptedStore{Path: path, Passphrase: func(bool) (string, error) { return "nope", nil }}
+	if _, err := wrong.Get("work"); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("Get with wrong passphrase: err = %v, want ErrBadPassphrase", err)
+	}
+	again := &EncryptedStore{Path: path, Passphrase: func(bool) (string, error) { return "hunter2", nil }}
+	if got, err := again.Get("work"); err != nil || got != testCookies {
+		t.Errorf("Get with new store = %+v, %v", got, err)
+	}
+}
+
+func TestHelperStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script needs sh")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	saved := filepath.Join(dir, "saved")
+	body := `#!/bin/sh
+case "$1" in
+store) cat > "` + saved + `" ;;
+get) cat "` + saved + `" 2>/dev/null || true ;;
+erase) rm -f "` + saved + `" ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(body), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	s := HelperStore{Helper: "!sh " + script, Host: "www.example.com"}
+	if err := s.Put("work", testCookies); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"protocol=https\n", "host=www.example.com\n", "username=work\n", "password=li_at=secret-li-at; "} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("helper got %q, missing %q", b, want)
+		}
+	}
+	if err := s.Delete("work"); err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+
+	if _, err := (HelperStore{Helper: "!exit 1"}).Get("work"); err == nil {
+		t.Error("failing helper: no error")
+	}
+}
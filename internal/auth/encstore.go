@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// EnvPassphrase unlocks an encrypted credential store without a prompt.
+const EnvPassphrase = "BRAGCLI_PASSPHRASE"
+
+// ErrBadPassphrase means an encrypted store could not be decrypted.
+var ErrBadPassphrase = errors.New("wrong passphrase or corrupt credential file")
+
+// scrypt parameters for new files. They are stored in each file, so they
+// can be raised later without breaking old ones.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	keyLen       = 32
+	saltLen      = 16
+	encFormatVer = 1
+)
+
+// EncryptedStore keeps cookies for all accounts in one file, encrypted
+// with AES-256-GCM under a key derived from a passphrase with scrypt. Each
+// write uses a fresh salt and nonce.
+type EncryptedStore struct {
+	Path string
+
+	// Passphrase is asked for the passphrase the first time the store is
+	// used. confirm is true when the file doesn't exist yet, so a prompt
+	// can ask twice.
+	Passphrase func(confirm bool) (string, error)
+
+	passphrase string
+	n          int // scrypt N; 0 means scryptN
+}
+
+func (s *EncryptedStore) String() string { return "encrypted file " + s.Path }
+
+// encFile is the on-disk format.
+type encFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// storedCookies is the plaintext: cookies per account.
+type storedCookies struct {
+	LiAt       string `json:"li_at"`
+	JSessionID string `json:"jsessionid"`
+}
+
+func (s *EncryptedStore) Get(account string) (Cookies, error) {
+	all, err := s.read()
+	if err != nil {
+		return Cookies{}, err
+	}
+	c, ok := all[account]
+	if !ok {
+		return Cookies{}, fmt.Errorf("%w for account %q", ErrNoCredentials, account)
+	}
+	return Cookies{LiAt: c.LiAt, JSessionID: c.JSessionID}, nil
+}
+
+func (s *EncryptedStore) Put(account string, c Cookies) error {
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all[account] = storedCookies{LiAt: c.LiAt, JSessionID: c.JSessionID}
+	return s.write(all)
+}
+
+func (s *EncryptedStore) Delete(account string) error {
+	if _, err := os.Stat(s.Path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[account]; !ok {
+		return nil
+	}
+	delete(all, account)
+	return s.write(all)
+}
+
+// unlock returns the passphrase, asking for it once per process.
+func (s *EncryptedStore) unlock(confirm bool) (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+	if s.Passphrase == nil {
+		return "", fmt.Errorf("no passphrase for %s (set %s)", s.Path, EnvPassphrase)
+	}
+	p, err := s.Passphrase(confirm)
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", fmt.Errorf("empty passphrase")
+	}
+	s.passphrase = p
+	return p, nil
+}
+
+// read decrypts the file. A missing file is an empty store.
+func (s *EncryptedStore) read() (map[string]storedCookies, error) {
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]storedCookies), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read credentials: %w", err)
+	}
+	var f encFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", s.Path, err)
+	}
+	if f.Version != encFormatVer || f.KDF != "scrypt" {
+		return nil, fmt.Errorf("%s: unsupported format (version %d, kdf %q)", s.Path, f.Version, f.KDF)
+	}
+	pass, err := s.unlock(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(pass, f.Salt, f.N, f.R, f.P)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		s.passphrase = ""
+		return nil, fmt.Errorf("%s: %w", s.Path, ErrBadPassphrase)
+	}
+	all := make(map[string]storedCookies)
+	if err := json.Unmarshal(plain, &all); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, ErrBadPassphrase)
+	}
+	return all, nil
+}
+
+func (s *EncryptedStore) write(all map[string]storedCookies) error {
+	_, statErr := os.Stat(s.Path)
+	pass, err := s.unlock(errors.Is(statErr, os.ErrNotExist))
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+
+	f := encFile{Version: encFormatVer, KDF: "scrypt", N: s.n, R: scryptR, P: scryptP}
+	if f.N == 0 {
+		f.N = scryptN
+	}
+	f.Salt = make([]byte, saltLen)
+	if _, err := rand.Read(f.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(pass, f.Salt, f.N, f.R, f.P)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Ciphertext = gcm.Seal(nil, f.Nonce, plain, nil)
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, append(b, '\n'))
+}
+
+func newGCM(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes b to path with mode 0600 via a temp file.
+func writeFileAtomic(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create credentials dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp.*")
+	if err != nil {
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
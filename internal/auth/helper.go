@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// HelperStore hands cookies to an external credential helper speaking
+// git's credential helper protocol, so the helpers git already ships
+// (osxkeychain, libsecret, manager, cache) can hold them.
+//
+// Helper is resolved the way git resolves credential.helper: "!cmd" runs
+// cmd through the shell, a path runs that program, and a bare name like
+// "osxkeychain" runs git-credential-osxkeychain. The action (get, store,
+// erase) is appended as an argument, and the credential goes over stdin as
+// key=value lines:
+//
+//	protocol=https
+//	host=<domain>
+//	username=<account>
+//	password=li_at=…; JSESSIONID=…
+//
+// Both cookies travel in the password, since helpers store one secret per
+// username and host.
+type HelperStore struct {
+	Helper string
+	// Host is the domain the cookies belong to (Domain() if empty).
+	Host string
+}
+
+func (s HelperStore) String() string { return "credential helper " + s.Helper }
+
+func (s HelperStore) Get(account string) (Cookies, error) {
+	out, err := s.run("get", s.attrs(account, ""))
+	if err != nil {
+		return Cookies{}, err
+	}
+	secret := out["password"]
+	if secret == "" {
+		return Cookies{}, fmt.Errorf("%w for account %q in %s", ErrNoCredentials, account, s)
+	}
+	c := parseSecret(secret)
+	if !c.Valid() {
+		return Cookies{}, fmt.Errorf("%s returned a password without li_at and JSESSIONID for account %q", s, account)
+	}
+	return c, nil
+}
+
+func (s HelperStore) Put(account string, c Cookies) error {
+	_, err := s.run("store", s.attrs(account, formatSecret(c)))
+	return err
+}
+
+func (s HelperStore) Delete(account string) error {
+	_, err := s.run("erase", s.attrs(account, ""))
+	return err
+}
+
+func (s HelperStore) attrs(account, password string) []string {
+	host := s.Host
+	if host == "" {
+		host = Domain()
+	}
+	a := []string{"protocol=https", "host=" + host, "username=" + account}
+	if password != "" {
+		a = append(a, "password="+password)
+	}
+	return a
+}
+
+// run invokes the helper with action and returns the attributes it
+// printed.
+func (s HelperStore) run(action string, attrs []string) (map[string]string, error) {
+	cmd, err := s.command(action)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = strings.NewReader(strings.Join(attrs, "\n") + "\n\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("%s %s: %w: %s", s, action, err, msg)
+		}
+		return nil, fmt.Errorf("%s %s: %w", s, action, err)
+	}
+
+	out := make(map[string]string)
+	sc := bufio.NewScanner(&stdout)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			break
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			out[k] = v
+		}
+	}
+	return out, sc.Err()
+}
+
+func (s HelperStore) command(action string) (*exec.Cmd, error) {
+	h := strings.TrimSpace(s.Helper)
+	switch {
+	case h == "":
+		return nil, fmt.Errorf("no credential helper configured")
+	case strings.HasPrefix(h, "!"):
+		if runtime.GOOS == "windows" {
+			return exec.Command("cmd", "/C", h[1:]+" "+action), nil
+		}
+		return exec.Command("sh", "-c", h[1:]+" "+action), nil
+	case strings.ContainsRune(h, os.PathSeparator) || strings.ContainsRune(h, '/'):
+		return exec.Command(h, action), nil
+	default:
+		return exec.Command("git-credential-"+h, action), nil
+	}
+}
+
+// formatSecret and parseSecret pack both cookies into one helper password,
+// in the form of a Cookie header.
+func formatSecret(c Cookies) string {
+	return "li_at=" + c.LiAt + "; JSESSIONID=" + c.JSessionID
+}
+
+func parseSecret(s string) Cookies {
+	var c Cookies
+	for _, part := range strings.Split(s, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "li_at":
+			c.LiAt = v
+		case "JSESSIONID":
+			c.JSessionID = v
+		}
+	}
+	return c
+}
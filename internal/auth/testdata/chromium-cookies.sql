@@ -0,0 +1,14 @@
+-- Regenerate with: rm -f chromium-cookies.sqlite && sqlite3 chromium-cookies.sqlite < chromium-cookies.sql
+-- Schema from a Chromium profile's Cookies database. Timestamps are
+-- microseconds since 1601-01-01; 15746918400000000 is 2100-01-01.
+PRAGMA journal_mode = DELETE;
+CREATE TABLE meta(key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY, value LONGVARCHAR);
+INSERT INTO meta VALUES ('version', '23');
+CREATE TABLE cookies(creation_utc INTEGER NOT NULL,host_key TEXT NOT NULL,top_frame_site_key TEXT NOT NULL,name TEXT NOT NULL,value TEXT NOT NULL,encrypted_value BLOB NOT NULL,path TEXT NOT NULL,expires_utc INTEGER NOT NULL,is_secure INTEGER NOT NULL,is_httponly INTEGER NOT NULL,last_access_utc INTEGER NOT NULL,has_expires INTEGER NOT NULL,is_persistent INTEGER NOT NULL,priority INTEGER NOT NULL,samesite INTEGER NOT NULL,source_scheme INTEGER NOT NULL,source_port INTEGER NOT NULL,last_update_utc INTEGER NOT NULL,source_type INTEGER NOT NULL,has_cross_site_ancestor INTEGER NOT NULL);
+CREATE UNIQUE INDEX cookies_unique_index ON cookies(host_key, top_frame_site_key, has_cross_site_ancestor, name, path, source_scheme, source_port);
+INSERT INTO cookies VALUES
+  (13370000000000000, '.www.example.com', 'https://example.net', 'li_at', 'partitioned', x'', '/', 15746918400000000, 1, 1, 0, 1, 1, 1, 0, 2, 443, 0, 0, 1),
+  (13370000000000001, '.www.example.com', '', 'li_at', 'cr-li-at', x'', '/', 15746918400000000, 1, 1, 0, 1, 1, 1, 0, 2, 443, 0, 0, 0),
+  (13370000000000002, 'www.example.com', '', 'JSESSIONID', '"ajax:cr"', x'', '/', 0, 1, 0, 0, 0, 0, 1, 0, 2, 443, 0, 0, 0),
+  (13370000000000003, '.encrypted.example', '', 'li_at', '', x'763130deadbeef', '/', 15746918400000000, 1, 1, 0, 1, 1, 1, 0, 2, 443, 0, 0, 0),
+  (13370000000000004, '.encrypted.example', '', 'JSESSIONID', '', x'763130cafef00d', '/', 15746918400000000, 1, 0, 0, 1, 1, 1, 0, 2, 443, 0, 0, 0);
@@ -0,0 +1,10 @@
+-- Regenerate with: rm -f firefox-cookies.sqlite && sqlite3 firefox-cookies.sqlite < firefox-cookies.sql
+-- Schema from a Firefox profile's cookies.sqlite.
+PRAGMA journal_mode = DELETE;
+CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '', name TEXT, value TEXT, host TEXT, path TEXT, expiry INTEGER, lastAccessed INTEGER, creationTime INTEGER, isSecure INTEGER, isHttpOnly INTEGER, inBrowserElement INTEGER DEFAULT 0, sameSite INTEGER DEFAULT 0, rawSameSite INTEGER DEFAULT 0, schemeMap INTEGER DEFAULT 0, isPartitionedAttributeSet INTEGER DEFAULT 0, CONSTRAINT moz_uniqueid UNIQUE (name, host, path, originAttributes));
+INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, isSecure, isHttpOnly) VALUES
+  ('^userContextId=2', 'li_at', 'container-li-at', '.www.example.com', '/', 4102444800, 1, 1),
+  ('', 'li_at', 'ff-li-at', '.www.example.com', '/', 4102444800, 1, 1),
+  ('', 'JSESSIONID', '"ajax:ff"', '.www.example.com', '/', 4102444800000, 1, 0),
+  ('', 'li_at', 'expired', 'www.example.com', '/', 1000, 1, 1),
+  ('', 'li_at', 'other-site', '.example.org', '/', 4102444800, 1, 1);
@@ -2,12 +2,22 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/janitrai/bragcli/internal/api"
 	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -16,22 +26,53 @@ var authCmd = &cobra.Command{
 	Short: "Authenticate with Bragnet",
 }
 
+// chromeProfilesDirName is the subdirectory of the user data dir holding
+// the Chrome profiles kept by auth login --keep-profile, one per account.
+const chromeProfilesDirName = "chrome"
+
 var (
-	authManual   bool
-	authHeadless bool
-	authTimeout  time.Duration
+	authManual      bool
+	authHeadless    bool
+	authTimeout     time.Duration
+	authKeepProfile bool
+
+	// authBrowser is the User-Agent and Accept-Language given on the
+	// command line for login and import.
+	authBrowser auth.Browser
 )
 
 var authLoginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login to Bragnet",
+	Long: `Login to Bragnet and save the session as an account.
+
+With --account NAME the session is saved under that name (creating the
+account if needed); otherwise it replaces the current account's. The
+account logged in to becomes the current one.
+
+Bragnet ties a session to the User-Agent of the browser that created it, so
+that browser's User-Agent and Accept-Language are saved with the session
+and sent with every request. The Chrome login records them itself; with
+--manual, paste navigator.userAgent when asked or pass --user-agent.
+
+Chrome normally runs with a throwaway profile. With --keep-profile the
+account gets its own profile under the user data dir, kept across runs so
+that auth refresh (and login --headless) can renew the session without
+signing in again. Once kept, the profile is reused by later logins.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, path, err := loadConfig()
+		cfg, path, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		name, err := sessionAccount(cfg)
 		if err != nil {
 			return err
 		}
 
-		var cookies auth.Cookies
+		var (
+			cookies auth.Cookies
+			browser auth.Browser
+		)
 		if authManual {
 			_ = auth.OpenBrowser(auth.BaseURL() + "/login")
 			fmt.Fprintln(cmd.ErrOrStderr(), "Paste your cookies (from browser devtools -> Application/Storage -> Cookies).")
@@ -51,69 +92,744 @@ var authLoginCmd = &cobra.Command{
 				LiAt:       strings.TrimSpace(liAt),
 				JSessionID: strings.TrimSpace(jsid),
 			}
+			if authBrowser.UserAgent == "" {
+				fmt.Fprint(cmd.ErrOrStderr(), "User-Agent (navigator.userAgent in the devtools console; empty for the default): ")
+				ua, err := r.ReadString('\n')
+				if err != nil && !errors.Is(err, io.EOF) {
+					return fmt.Errorf("read User-Agent: %w", err)
+				}
+				browser.UserAgent = strings.TrimSpace(ua)
+			}
 		} else {
+			profile := ""
+			if acct := cfg.Accounts[name]; acct != nil {
+				profile = acct.ChromeProfile
+			}
+			if profile == "" && authKeepProfile {
+				if profile, err = chromeProfileDir(name); err != nil {
+					return err
+				}
+			}
 			fmt.Fprintln(cmd.ErrOrStderr(), "A Chrome window will open. Complete Bragnet login, then return to this terminal.")
 			ctx := context.Background()
-			cookies, err = auth.LoginWithChrome(ctx, auth.ChromeLoginOptions{
-				Timeout:  authTimeout,
-				Headless: authHeadless,
-				LoginURL: auth.BaseURL() + "/login",
+			cookies, browser, err = auth.LoginWithChrome(ctx, auth.ChromeLoginOptions{
+				Timeout:    authTimeout,
+				Headless:   authHeadless,
+				LoginURL:   auth.BaseURL() + "/login",
+				ProfileDir: profile,
 			})
 			if err != nil {
 				return fmt.Errorf("browser login failed: %w (try --manual)", err)
 			}
+			if profile != "" {
+				cfg.AddAccount(name).ChromeProfile = profile
+			}
 		}
 
 		if !cookies.Valid() {
 			return fmt.Errorf("did not capture required cookies (li_at, JSESSIONID)")
 		}
 
-		cfg.Auth.LiAt = cookies.LiAt
-		cfg.Auth.JSessionID = cookies.JSessionID
-		if err := saveConfig(path, cfg); err != nil {
+		store, err := saveSession(cfg, path, name, cookies, withBrowserFlags(browser))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Logged in to account %q. Saved auth to %s\n", name, store)
+		return nil
+	},
+}
+
+// sessionAccount is the account a new session is saved to: --account,
+// else the current one, else the default.
+func sessionAccount(cfg config.Config) (string, error) {
+	name := accountName
+	if name == "" {
+		name = cfg.CurrentAccount
+	}
+	if name == "" {
+		name = config.DefaultAccount
+	}
+	return name, config.ValidateAccountName(name)
+}
+
+// withBrowserFlags overrides b with --user-agent and --accept-language.
+func withBrowserFlags(b auth.Browser) auth.Browser {
+	if authBrowser.UserAgent != "" {
+		b.UserAgent = authBrowser.UserAgent
+	}
+	if authBrowser.AcceptLanguage != "" {
+		b.AcceptLanguage = authBrowser.AcceptLanguage
+	}
+	return b
+}
+
+// saveSession saves cookies as account name's session, along with the
+// browser they came from, creating the account if needed and making it
+// current. It returns the store the cookies went to.
+func saveSession(cfg config.Config, path, name string, cookies auth.Cookies, browser auth.Browser) (auth.CredentialStore, error) {
+	acct := cfg.AddAccount(name)
+	acct.Domain = auth.Domain()
+	if err := cfg.Select(name); err != nil {
+		return nil, err
+	}
+	// The store may not be this file; drop any plaintext cookies left
+	// from before it was configured.
+	cfg.Auth.LiAt = ""
+	cfg.Auth.JSessionID = ""
+	cfg.Auth.UpdatedAt = time.Now().UTC()
+	cfg.Auth.ExpiresAt = cookies.Expires
+	cfg.Auth.UserAgent = browser.UserAgent
+	cfg.Auth.AcceptLanguage = browser.AcceptLanguage
+	cfg.CurrentAccount = name
+	if err := saveConfig(path, cfg); err != nil {
+		return nil, err
+	}
+	store, err := credentialStore(cfg, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Put(name, cookies); err != nil {
+		return nil, fmt.Errorf("save credentials: %w", err)
+	}
+	return store, nil
+}
+
+var authImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import the session from a browser's cookie store or a cookies.txt",
+	Long: `Import li_at and JSESSIONID for the target domain from a cookie file and
+save them as an account, like auth login does. The file can be:
+
+  - a Netscape cookies.txt, as written by curl or a browser extension
+  - a Firefox profile's cookies.sqlite
+  - a Chrome, Chromium or Edge profile's Cookies database, if its cookies
+    are stored unencrypted (most desktop installs encrypt them; export a
+    cookies.txt from the browser instead)
+
+The format is detected from the file. Browser databases can be read while
+the browser is open. The session is saved to the account given by --account,
+else the current one, which becomes current.
+
+Pass the browser's User-Agent (navigator.userAgent) with --user-agent:
+Bragnet ends sessions used with a different one.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		cookies, format, err := auth.ImportCookies(args[0], auth.Domain())
+		if err != nil {
+			return err
+		}
+		name, err := sessionAccount(cfg)
+		if err != nil {
+			return err
+		}
+		browser := withBrowserFlags(auth.Browser{})
+		if browser.UserAgent == "" {
+			fmt.Fprintln(cmd.ErrOrStderr(), "warning: no --user-agent given; Bragnet may end the session if it came from a browser other than the built-in Chrome user agent")
+		}
+		store, err := saveSession(cfg, path, name, cookies, browser)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Imported %s session from %s (%s) to account %q. Saved auth to %s\n",
+			auth.Domain(), args[0], format, name, store)
+		return nil
+	},
+}
+
+var (
+	authExportFormat string
+	authExportOutput string
+)
+
+var authExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the session cookies to a cookies.txt for curl and other tools",
+	Long: `Write the selected account's li_at and JSESSIONID in Netscape cookies.txt
+format, to stdout or the file given by --output (created with mode 0600).
+The output is a live session: anyone holding it can act as you.
+
+For curl, pass the file with -b and the JSESSIONID value, without quotes, as
+the csrf-token header.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		store, err := credentialStore(cfg, path)
+		if err != nil {
+			return err
+		}
+		cookies, err := loadCookies(cfg, store)
+		if err != nil {
+			return err
+		}
+		cookies.Expires = cfg.Auth.ExpiresAt
+		domain := auth.Domain()
+		if acct := cfg.Account(); acct != nil && acct.Domain != "" {
+			domain = acct.Domain
+		}
+
+		if authExportOutput == "" || authExportOutput == "-" {
+			return auth.ExportCookies(cmd.OutOrStdout(), authExportFormat, domain, cookies)
+		}
+		var buf bytes.Buffer
+		if err := auth.ExportCookies(&buf, authExportFormat, domain, cookies); err != nil {
+			return err
+		}
+		if err := os.WriteFile(authExportOutput, buf.Bytes(), 0o600); err != nil {
 			return err
 		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote the session of account %q to %s\n", cfg.Selected(), authExportOutput)
+		return nil
+	},
+}
+
+// chromeProfileDir is where the kept Chrome profile of account lives.
+func chromeProfileDir(account string) (string, error) {
+	dir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, chromeProfilesDirName, account), nil
+}
+
+// removeChromeProfile deletes a kept Chrome profile. The path comes from
+// the config file, so it is only deleted if it lies inside the profiles
+// dir auth login keeps them in.
+func removeChromeProfile(profile string) error {
+	dir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+	root, err := filepath.Abs(filepath.Join(dir, chromeProfilesDirName))
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(profile)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is not in %s; not removing it", profile, root)
+	}
+	return os.RemoveAll(abs)
+}
+
+var authRefreshTimeout time.Duration
+
+var authRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Renew the session from the account's kept Chrome profile, headless",
+	Long: `Start the account's Chrome profile (kept by auth login --keep-profile)
+headless, load Bragnet so it renews the session cookies, and save them. No
+interaction is needed, so this can run from cron before the cookies expire:
 
-		fmt.Fprintf(cmd.OutOrStdout(), "Logged in. Saved auth to %s\n", path)
+  0 */6 * * *  bragcli auth refresh
+
+Chrome presents the User-Agent recorded at login, not its headless one.
+If the profile's own session has ended, log in again with auth login.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		acct := cfg.Account()
+		if acct == nil {
+			return errNotLoggedIn
+		}
+		name := cfg.Selected()
+		if acct.ChromeProfile == "" {
+			return fmt.Errorf("account %q has no kept browser profile; run `bragcli auth login --keep-profile` once", name)
+		}
+		domain := acct.Domain
+		if domain == "" {
+			domain = auth.Domain()
+		}
+
+		cookies, browser, err := auth.LoginWithChrome(context.Background(), auth.ChromeLoginOptions{
+			Timeout:    authRefreshTimeout,
+			Headless:   true,
+			LoginURL:   "https://" + domain + "/feed/",
+			ProfileDir: acct.ChromeProfile,
+			UserAgent:  cfg.Auth.UserAgent,
+		})
+		if err != nil {
+			return fmt.Errorf("headless refresh of account %q: %w", name, err)
+		}
+
+		store, err := credentialStore(cfg, path)
+		if err != nil {
+			return err
+		}
+		changed, err := sessionChanged(store, name, cookies)
+		if err != nil {
+			return err
+		}
+		if changed {
+			cfg.Auth.UpdatedAt = time.Now().UTC()
+		}
+		cfg.Auth.ExpiresAt = cookies.Expires
+		if cfg.Auth.UserAgent == "" {
+			cfg.Auth.UserAgent = browser.UserAgent
+		}
+		if cfg.Auth.AcceptLanguage == "" {
+			cfg.Auth.AcceptLanguage = browser.AcceptLanguage
+		}
+		if err := saveConfig(path, cfg); err != nil {
+			return err
+		}
+		if !changed {
+			fmt.Fprintf(cmd.OutOrStdout(), "The session of account %q is unchanged\n", name)
+			return nil
+		}
+		if err := store.Put(name, cookies); err != nil {
+			return fmt.Errorf("save credentials: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Refreshed the session of account %q. Saved auth to %s\n", name, store)
 		return nil
 	},
 }
 
+// sessionChanged reports whether cookies differ from the session store
+// holds for account, so saving them is a real change.
+func sessionChanged(store auth.CredentialStore, account string, cookies auth.Cookies) (bool, error) {
+	cur, err := store.Get(account)
+	if errors.Is(err, auth.ErrNoCredentials) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read credentials: %w", err)
+	}
+	return cur.LiAt != cookies.LiAt || cur.CSRFToken() != cookies.CSRFToken(), nil
+}
+
+var (
+	authStatusVerbose bool
+	authStatusJSON    bool
+)
+
 var authStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show authentication status",
+	Long: `Check the session of the current account (or --account) by asking Bragnet
+who is logged in. A session given with --cookies-from-stdin or in
+$BRAGNET_LI_AT and $BRAGNET_JSESSIONID is checked instead of the saved one,
+and status says so.
+
+With --verbose, also show when the cookies were saved and when li_at
+expires (if known), the pinned User-Agent, the domain, the queryIds that
+override the built-in ones, and whether the profile, search and messaging
+endpoints answer. --json prints the same as a JSON object, and is the
+default when the output_format setting is json.
+
+The exit status is 0 only if the session works, so monitoring can alert on
+it: 3 when logged out or the session has expired, 4 for a security check,
+and so on (see Exit codes in the README). A session that expires soon is
+reported on stderr but still exits 0.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, path, err := loadConfig()
 		if err != nil {
 			return err
 		}
 
-		if !cfg.Auth.LoggedIn() {
-			fmt.Fprintf(cmd.OutOrStdout(), "Not logged in. Config: %s\n", path)
-			return nil
+		asJSON := authStatusJSON
+		if !cmd.Flags().Changed("json") && cfg.OutputFormat == config.OutputJSON {
+			asJSON = true
+		}
+		r := newSessionReport(cfg, path)
+		err = r.check(cmd.Context(), cfg, authStatusVerbose || asJSON)
+		if asJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if encErr := enc.Encode(r); encErr != nil {
+				return encErr
+			}
+			return err
+		}
+		r.print(cmd.OutOrStdout(), authStatusVerbose)
+		for _, w := range r.Warnings {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Warning:", w)
+		}
+		return err
+	},
+}
+
+// Session age thresholds for auth status warnings. Bragnet's li_at
+// normally lasts about a year.
+const (
+	sessionExpiryWarning = 14 * 24 * time.Hour
+	sessionAgeWarning    = 180 * 24 * time.Hour
+)
+
+// healthSearchKeywords is what auth status searches for to see that search
+// answers.
+const healthSearchKeywords = "engineer"
+
+// sessionReport is what auth status finds out about a session. It is also
+// the --json output.
+type sessionReport struct {
+	Account          string `json:"account"`
+	Config           string `json:"config"`
+	Domain           string `json:"domain"`
+	CredentialStore  string `json:"credential_store"`
+	SessionSource    string `json:"session_source"`
+	LoggedIn         bool   `json:"logged_in"`
+	Usable           bool   `json:"usable"`
+	Name             string `json:"name,omitempty"`
+	PublicIdentifier string `json:"public_identifier,omitempty"`
+	Error            string `json:"error,omitempty"`
+
+	SavedAt        *time.Time `json:"saved_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	UserAgent      string     `json:"user_agent,omitempty"`
+	AcceptLanguage string     `json:"accept_language,omitempty"`
+
+	// QueryIDs maps operations to the queryIds tried before the built-in
+	// ones.
+	QueryIDs  map[string][]string `json:"query_id_overrides,omitempty"`
+	Endpoints []endpointCheck     `json:"endpoints,omitempty"`
+	Warnings  []string            `json:"warnings,omitempty"`
+
+	err error
+}
+
+// endpointCheck is whether one API endpoint answered.
+type endpointCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// newSessionReport fills in what cfg says about its selected account.
+func newSessionReport(cfg config.Config, path string) *sessionReport {
+	r := &sessionReport{
+		Account:         cfg.Selected(),
+		Config:          path,
+		Domain:          auth.Domain(),
+		CredentialStore: cfg.CredentialStore,
+		SessionSource:   sessionSource(),
+		UserAgent:       cfg.Auth.UserAgent,
+		AcceptLanguage:  cfg.Auth.AcceptLanguage,
+		QueryIDs:        queryIDOverrides(cfg),
+	}
+	if acct := cfg.Account(); acct != nil && acct.Domain != "" {
+		r.Domain = acct.Domain
+	}
+	if r.CredentialStore == "" {
+		r.CredentialStore = auth.StorePlaintext
+	}
+	if r.SessionSource != sourceStore {
+		return r
+	}
+	if t := cfg.Auth.UpdatedAt; !t.IsZero() {
+		r.SavedAt = &t
+	}
+	if t := cfg.Auth.ExpiresAt; !t.IsZero() {
+		r.ExpiresAt = &t
+	}
+	return r
+}
+
+// check asks Bragnet who is logged in and, with all, whether the profile,
+// search and messaging endpoints answer too. It returns the error that
+// makes the session unusable, if any; failures of the other endpoints are
+// only reported.
+func (r *sessionReport) check(ctx context.Context, cfg config.Config, all bool) error {
+	// A health check has to reach Bragnet, not the response cache.
+	li, err := newBragnet(cfg, api.WithCache(nil))
+	if err != nil {
+		return r.fail(err)
+	}
+	r.LoggedIn = true
+	if r.SessionSource == sourceStore {
+		r.Warnings = sessionWarnings(cfg.Auth, time.Now())
+	}
+
+	me, err := li.GetMe(ctx)
+	r.addEndpoint("me", err)
+	if err != nil {
+		return r.fail(err)
+	}
+	r.Usable = true
+	r.Name = strings.TrimSpace(me.FirstName + " " + me.LastName)
+	if r.Name == "" {
+		r.Name = "unknown"
+	}
+	r.PublicIdentifier = me.PublicIdentifier
+	if !all {
+		return nil
+	}
+
+	profile := me.PublicIdentifier
+	if profile == "" {
+		profile = me.ProfileURN
+	}
+	_, err = li.GetProfile(ctx, profile)
+	r.addEndpoint("profile", err)
+	_, err = li.SearchPeople(ctx, healthSearchKeywords, 0, 1)
+	r.addEndpoint("search", err)
+	_, err = li.ListConversations(ctx, me.ProfileURN, 1)
+	r.addEndpoint("messaging", err)
+	return nil
+}
+
+func (r *sessionReport) fail(err error) error {
+	r.err = err
+	r.Error = err.Error()
+	return err
+}
+
+func (r *sessionReport) addEndpoint(name string, err error) {
+	c := endpointCheck{Name: name, OK: err == nil}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	r.Endpoints = append(r.Endpoints, c)
+}
+
+func (r *sessionReport) print(w io.Writer, verbose bool) {
+	switch {
+	case r.Usable && r.PublicIdentifier != "":
+		fmt.Fprintf(w, "Logged in as %s (%s) on account %q. Config: %s\n", r.Name, r.PublicIdentifier, r.Account, r.Config)
+	case r.Usable:
+		fmt.Fprintf(w, "Logged in as %s on account %q. Config: %s\n", r.Name, r.Account, r.Config)
+	case errors.Is(r.err, errNotLoggedIn):
+		fmt.Fprintf(w, "Not logged in. Config: %s\n", r.Config)
+	case !r.LoggedIn:
+		// Cookies may exist, but a client can't be built for them.
+		fmt.Fprintf(w, "Auth present but unusable. Config: %s\n", r.Config)
+	default:
+		fmt.Fprintf(w, "Auth present but request failed. Config: %s\n", r.Config)
+	}
+	injected := map[string]string{
+		sourceStdin: "--cookies-from-stdin",
+		sourceEnv:   "$" + auth.EnvLiAt + " and $" + auth.EnvJSessionID,
+	}[r.SessionSource]
+	if injected != "" {
+		fmt.Fprintf(w, "Session from %s; it is not saved.\n", injected)
+	}
+	if !verbose {
+		return
+	}
+
+	now := time.Now()
+	orDefault := func(s string) string {
+		if s == "" {
+			return "built-in default"
+		}
+		return s
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Domain:\t%s\n", r.Domain)
+	fmt.Fprintf(tw, "Credential store:\t%s\n", r.CredentialStore)
+	fmt.Fprintf(tw, "Session source:\t%s\n", r.SessionSource)
+	fmt.Fprintf(tw, "Cookies saved:\t%s\n", formatWhen(r.SavedAt, now))
+	fmt.Fprintf(tw, "li_at expires:\t%s\n", formatWhen(r.ExpiresAt, now))
+	fmt.Fprintf(tw, "User-Agent:\t%s\n", orDefault(r.UserAgent))
+	fmt.Fprintf(tw, "Accept-Language:\t%s\n", orDefault(r.AcceptLanguage))
+	if len(r.QueryIDs) == 0 {
+		fmt.Fprintf(tw, "QueryIds:\tbuilt-in\n")
+	}
+	for _, q := range api.KnownQueries {
+		if ids, ok := r.QueryIDs[string(q)]; ok {
+			fmt.Fprintf(tw, "QueryIds %s:\t%s\n", q, strings.Join(ids, ", "))
+		}
+	}
+	for _, c := range r.Endpoints {
+		state := "ok"
+		if !c.OK {
+			state = "failed: " + c.Error
+		}
+		fmt.Fprintf(tw, "Endpoint %s:\t%s\n", c.Name, state)
+	}
+	_ = tw.Flush()
+}
+
+// formatWhen formats t in local time along with how far it is from now,
+// or "unknown" for nil.
+func formatWhen(t *time.Time, now time.Time) string {
+	if t == nil {
+		return "unknown"
+	}
+	rel := "today"
+	switch days := int(t.Sub(now).Hours() / 24); {
+	case days == 1:
+		rel = "in 1 day"
+	case days > 1:
+		rel = fmt.Sprintf("in %d days", days)
+	case days == -1:
+		rel = "1 day ago"
+	case days < -1:
+		rel = fmt.Sprintf("%d days ago", -days)
+	}
+	return t.Local().Format("2006-01-02 15:04") + " (" + rel + ")"
+}
+
+// sessionWarnings returns what auth status warns about a: li_at expiring
+// soon or expired, or, if its expiry isn't known, cookies old enough that
+// it may have.
+func sessionWarnings(a config.AuthConfig, now time.Time) []string {
+	days := func(d time.Duration) int { return int(d.Hours() / 24) }
+	switch left := a.ExpiresAt.Sub(now); {
+	case a.ExpiresAt.IsZero():
+		if age := now.Sub(a.UpdatedAt); !a.UpdatedAt.IsZero() && age > sessionAgeWarning {
+			return []string{fmt.Sprintf("the session cookies were saved %d days ago and Bragnet ends sessions after about a year; run `bragcli auth login` if they stop working", days(age))}
+		}
+	case left <= 0:
+		return []string{fmt.Sprintf("li_at expired on %s; run `bragcli auth login`", a.ExpiresAt.Local().Format("2006-01-02"))}
+	case left < sessionExpiryWarning:
+		return []string{fmt.Sprintf("li_at expires in %d days, on %s; run `bragcli auth login` (or `bragcli auth refresh`) before then", days(left), a.ExpiresAt.Local().Format("2006-01-02"))}
+	}
+	return nil
+}
+
+// queryIDOverrides returns, per operation, the queryIds from cfg that are
+// tried before the built-in ones.
+func queryIDOverrides(cfg config.Config) map[string][]string {
+	r := api.NewQueryRegistry()
+	configureQueries(r, cfg)
+	builtin := api.NewQueryRegistry()
+	out := make(map[string][]string)
+	for _, q := range api.KnownQueries {
+		defaults := builtin.IDs(q)
+		for _, id := range r.IDs(q) {
+			if !slices.Contains(defaults, id) {
+				out[string(q)] = append(out[string(q)], id)
+			}
 		}
+	}
+	return out
+}
 
-		li, err := newBragnet(cfg)
+var authListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved accounts",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigFile()
 		if err != nil {
-			// Cookies exist but can't build a client for some reason.
-			fmt.Fprintf(cmd.OutOrStdout(), "Auth present but unusable: %v\nConfig: %s\n", err, path)
+			return err
+		}
+		names := cfg.AccountNames()
+		if len(names) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No accounts. Run `bragcli auth login` to add one. Config: %s\n", path)
 			return nil
 		}
 
-		me, err := li.GetMe(context.Background())
+		// Only the plaintext store can be checked without unlocking it.
+		plaintext := cfg.CredentialStore == "" || cfg.CredentialStore == auth.StorePlaintext
+
+		tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		for _, name := range names {
+			acct := cfg.Accounts[name]
+			marker := " "
+			if name == cfg.CurrentAccount {
+				marker = "*"
+			}
+			domain := acct.Domain
+			if domain == "" {
+				domain = auth.Domain()
+			}
+			state := "logged out"
+			switch {
+			case !plaintext:
+				state = "session in " + cfg.CredentialStore + " store"
+			case acct.Auth.LoggedIn():
+				state = "logged in"
+			}
+			if state != "logged out" && !acct.Auth.UpdatedAt.IsZero() {
+				state += ", saved " + acct.Auth.UpdatedAt.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(tw, "%s %s\t%s\t%s\n", marker, name, domain, state)
+		}
+		return tw.Flush()
+	},
+}
+
+var authSwitchCmd = &cobra.Command{
+	Use:   "switch <account>",
+	Short: "Make another saved account the current one",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigFile()
 		if err != nil {
-			fmt.Fprintf(cmd.OutOrStdout(), "Auth present but request failed: %v\nConfig: %s\n", err, path)
-			return nil
+			return err
 		}
+		if err := cfg.Select(args[0]); err != nil {
+			return err
+		}
+		cfg.CurrentAccount = args[0]
+		if err := saveConfig(path, cfg); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Switched to account %q\n", args[0])
+		return nil
+	},
+}
 
-		name := strings.TrimSpace(strings.TrimSpace(me.FirstName + " " + me.LastName))
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout [account]",
+	Short: "Remove a saved account and its session",
+	Long: `Remove a saved account and its session: the named one, else the one
+given by --account, else the current one. If it was current, the first
+remaining account (by name) becomes current.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		name := accountName
+		if len(args) == 1 {
+			name = args[0]
+		}
 		if name == "" {
-			name = "unknown"
+			name = cfg.CurrentAccount
 		}
-		if me.PublicIdentifier != "" {
-			fmt.Fprintf(cmd.OutOrStdout(), "Logged in as %s (%s). Config: %s\n", name, me.PublicIdentifier, path)
-		} else {
-			fmt.Fprintf(cmd.OutOrStdout(), "Logged in as %s. Config: %s\n", name, path)
+		if name == "" {
+			return errNotLoggedIn
+		}
+		if err := config.ValidateAccountName(name); err != nil {
+			return err
+		}
+		if _, ok := cfg.Accounts[name]; !ok {
+			return fmt.Errorf("%w %q", config.ErrUnknownAccount, name)
+		}
+		if cfg.CredentialStore != "" && cfg.CredentialStore != auth.StorePlaintext {
+			if err := cfg.Select(name); err != nil {
+				return err
+			}
+			store, err := credentialStore(cfg, path)
+			if err != nil {
+				return err
+			}
+			if err := store.Delete(name); err != nil {
+				return fmt.Errorf("delete credentials: %w", err)
+			}
+		}
+		profile := cfg.Accounts[name].ChromeProfile
+		cfg.RemoveAccount(name)
+		if err := saveConfig(path, cfg); err != nil {
+			return err
+		}
+		if profile != "" {
+			// The profile holds the session too.
+			if err := removeChromeProfile(profile); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not remove browser profile: %v\n", err)
+			}
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Logged out of account %q\n", name)
+		if cfg.CurrentAccount != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Current account is now %q\n", cfg.CurrentAccount)
 		}
 		return nil
 	},
@@ -122,8 +838,28 @@ var authStatusCmd = &cobra.Command{
 func init() {
 	authCmd.AddCommand(authLoginCmd)
 	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authListCmd)
+	authCmd.AddCommand(authSwitchCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authImportCmd)
+	authCmd.AddCommand(authExportCmd)
+	authCmd.AddCommand(authRefreshCmd)
 
 	authLoginCmd.Flags().BoolVar(&authManual, "manual", false, "Manually paste cookies instead of using a controlled Chrome session")
 	authLoginCmd.Flags().BoolVar(&authHeadless, "headless", false, "Run Chrome in headless mode (usually requires pre-existing login state)")
 	authLoginCmd.Flags().DurationVar(&authTimeout, "timeout", 10*time.Minute, "How long to wait for you to complete login in the browser")
+	authLoginCmd.Flags().BoolVar(&authKeepProfile, "keep-profile", false, "Keep a Chrome profile for this account under the user data dir, for auth refresh")
+
+	authRefreshCmd.Flags().DurationVar(&authRefreshTimeout, "timeout", 2*time.Minute, "How long to wait for Bragnet to load")
+
+	for _, c := range []*cobra.Command{authLoginCmd, authImportCmd} {
+		c.Flags().StringVar(&authBrowser.UserAgent, "user-agent", "", "User-Agent of the browser the session comes from")
+		c.Flags().StringVar(&authBrowser.AcceptLanguage, "accept-language", "", "Accept-Language of the browser the session comes from")
+	}
+
+	authStatusCmd.Flags().BoolVar(&authStatusVerbose, "verbose", false, "Also show cookie age and expiry, the pinned browser, queryId overrides and endpoint health")
+	authStatusCmd.Flags().BoolVar(&authStatusJSON, "json", false, "Print the full report as JSON")
+
+	authExportCmd.Flags().StringVar(&authExportFormat, "format", auth.FormatNetscape, "Output format (netscape)")
+	authExportCmd.Flags().StringVar(&authExportOutput, "output", "", "Write to this file instead of stdout")
 }
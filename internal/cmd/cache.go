@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local response cache",
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached API responses and resolved identities",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		cache, err := newCache(cfg)
+		if err != nil {
+			return err
+		}
+		n, err := cache.Clear()
+		if err != nil {
+			return err
+		}
+		identities, err := newIdentityCache()
+		if err != nil {
+			return err
+		}
+		if err := identities.Clear(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Cleared %d cached responses from %s\n", n, cache.Dir)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -1,23 +1,77 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/janitrai/bragcli/internal/api"
 	"github.com/janitrai/bragcli/internal/auth"
 	"github.com/janitrai/bragcli/internal/config"
+	"golang.org/x/term"
 )
 
+// rateLimitFileName is the shared limiter state, kept next to the config so
+// every bragcli process using that config draws from one budget.
+const rateLimitFileName = "ratelimit.json"
+
+// responseCacheDirName is the subdirectory of the user cache dir holding
+// cached API responses.
+const responseCacheDirName = "responses"
+
+// identityCacheFileName is the file in the user cache dir mapping
+// usernames and URNs to resolved identities.
+const identityCacheFileName = "identities.json"
+
+// credentialsFileName is the encrypted credential store, kept next to the
+// config.
+const credentialsFileName = "credentials.enc"
+
+// identifierHelp describes the forms a [username] argument can take.
+const identifierHelp = `The member can be given as a username (jane-doe or @jane-doe), a profile
+URL, or a member, fsd_profile or messaging participant URN.`
+
+func resolveConfigPath() (string, error) {
+	if cfgPath != "" {
+		return cfgPath, nil
+	}
+	// A config left where older releases kept it is still read there if
+	// it can't be moved.
+	if err := config.MoveLegacyDir(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return config.DefaultPath()
+}
+
+// clientOptions are appended to every client newBragnet builds. Tests use
+// it to point commands at internal/fakebragnet.
+var clientOptions []api.Option
+
+// loadConfig loads the config with the --account account selected, or the
+// current one if the flag isn't set.
 func loadConfig() (config.Config, string, error) {
-	path := cfgPath
-	if path == "" {
-		var err error
-		path, err = config.DefaultPath()
-		if err != nil {
-			return config.Config{}, "", err
+	cfg, path, err := loadConfigFile()
+	if err != nil {
+		return cfg, path, err
+	}
+	if accountName != "" {
+		if err := cfg.Select(accountName); err != nil {
+			return config.Config{}, path, err
 		}
 	}
+	return cfg, path, nil
+}
+
+// loadConfigFile loads the config without applying --account, for the
+// commands that manage accounts.
+func loadConfigFile() (config.Config, string, error) {
+	path, err := resolveConfigPath()
+	if err != nil {
+		return config.Config{}, "", err
+	}
 	cfg, err := config.Load(path)
 	if err != nil {
 		return config.Config{}, path, err
@@ -29,26 +83,300 @@ func saveConfig(path string, cfg config.Config) error {
 	return config.Save(path, cfg)
 }
 
-func newBragnet(cfg config.Config) (*api.Bragnet, error) {
-	cookies := auth.Cookies{
-		LiAt:       cfg.Auth.LiAt,
-		JSessionID: cfg.Auth.JSessionID,
+// credentialStore returns the store cfg's credential_store names. path is
+// the config file; the encrypted store lives next to it.
+func credentialStore(cfg config.Config, path string) (auth.CredentialStore, error) {
+	switch cfg.CredentialStore {
+	case "", auth.StorePlaintext:
+		return auth.PlaintextStore{ConfigPath: path}, nil
+	case auth.StoreEncrypted:
+		return &auth.EncryptedStore{
+			Path:       filepath.Join(filepath.Dir(path), credentialsFileName),
+			Passphrase: readPassphrase,
+		}, nil
+	case auth.StoreHelper:
+		if cfg.CredentialHelper == "" {
+			return nil, fmt.Errorf("config credential_store is %q but credential_helper is not set", auth.StoreHelper)
+		}
+		store := auth.HelperStore{Helper: cfg.CredentialHelper}
+		if acct := cfg.Account(); acct != nil {
+			store.Host = acct.Domain
+		}
+		return store, nil
+	}
+	return nil, fmt.Errorf("config credential_store %q: want %s, %s or %s",
+		cfg.CredentialStore, auth.StorePlaintext, auth.StoreEncrypted, auth.StoreHelper)
+}
+
+// readPassphrase unlocks the encrypted store from $BRAGCLI_PASSPHRASE, or
+// by prompting on the terminal.
+func readPassphrase(confirm bool) (string, error) {
+	if p := os.Getenv(auth.EnvPassphrase); p != "" {
+		return p, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("credentials are encrypted and stdin is not a terminal: set %s", auth.EnvPassphrase)
+	}
+	prompt := func(label string) (string, error) {
+		fmt.Fprint(os.Stderr, label)
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+		return string(b), nil
+	}
+	p, err := prompt("Passphrase for bragcli credentials: ")
+	if err != nil || !confirm {
+		return p, err
+	}
+	again, err := prompt("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != p {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return p, nil
+}
+
+// Where a command's session comes from, in order of precedence. Only
+// sessions from the credential store are ever saved, rotated or not.
+const (
+	sourceStdin = "stdin"
+	sourceEnv   = "environment"
+	sourceStore = "credential store"
+)
+
+// sessionSource reports which of the sources the session comes from.
+func sessionSource() string {
+	switch {
+	case cookiesFromStdin:
+		return sourceStdin
+	case os.Getenv(auth.EnvLiAt) != "" || os.Getenv(auth.EnvJSessionID) != "":
+		return sourceEnv
+	}
+	return sourceStore
+}
+
+// sessionCookies returns the session to use for cfg's selected account and
+// the store it came from, which is nil for a session from stdin or the
+// environment.
+func sessionCookies(cfg config.Config, path string) (auth.Cookies, auth.CredentialStore, error) {
+	switch sessionSource() {
+	case sourceStdin:
+		domain := auth.Domain()
+		if acct := cfg.Account(); acct != nil && acct.Domain != "" {
+			domain = acct.Domain
+		}
+		c, err := auth.ReadCookies(rootCmd.InOrStdin(), domain)
+		if err != nil {
+			return auth.Cookies{}, nil, fmt.Errorf("%w: --cookies-from-stdin: %w", errNotLoggedIn, err)
+		}
+		return c, nil, nil
+	case sourceEnv:
+		c, _, err := auth.CookiesFromEnv()
+		if err != nil {
+			return auth.Cookies{}, nil, fmt.Errorf("%w: %w", errNotLoggedIn, err)
+		}
+		return c, nil, nil
+	}
+	store, err := credentialStore(cfg, path)
+	if err != nil {
+		return auth.Cookies{}, nil, err
+	}
+	c, err := loadCookies(cfg, store)
+	if err != nil {
+		return auth.Cookies{}, nil, err
+	}
+	c.Expires = cfg.Auth.ExpiresAt
+	return c, store, nil
+}
+
+// loadCookies fetches the selected account's session from its store.
+func loadCookies(cfg config.Config, store auth.CredentialStore) (auth.Cookies, error) {
+	if cfg.Selected() == "" {
+		return auth.Cookies{}, errNotLoggedIn
+	}
+	c, err := store.Get(cfg.Selected())
+	if errors.Is(err, auth.ErrNoCredentials) || errors.Is(err, config.ErrUnknownAccount) {
+		return auth.Cookies{}, fmt.Errorf("%w: %w", errNotLoggedIn, err)
+	}
+	if err != nil {
+		return auth.Cookies{}, err
+	}
+	if !c.Valid() {
+		return auth.Cookies{}, errNotLoggedIn
+	}
+	return c, nil
+}
+
+// newBragnet builds a client for cfg's selected account. extra options
+// are applied after the ones from cfg and flags.
+func newBragnet(cfg config.Config, extra ...api.Option) (*api.Bragnet, error) {
+	path, err := resolveConfigPath()
+	if err != nil {
+		return nil, err
 	}
-	if !cookies.Valid() {
-		return nil, fmt.Errorf("not logged in (missing li_at/JSESSIONID). Run `li auth login`")
+	var (
+		cookies auth.Cookies
+		store   auth.CredentialStore
+	)
+	if replayDir == "" {
+		if cookies, store, err = sessionCookies(cfg, path); err != nil {
+			return nil, err
+		}
 	}
 
-	var opts []api.Option
+	opts := []api.Option{
+		api.WithSchemaDriftHandler(noteSchemaDrift),
+		api.WithUserAgent(cfg.Auth.UserAgent),
+		api.WithAcceptLanguage(cfg.Auth.AcceptLanguage),
+	}
+	if acct := cfg.Account(); acct != nil && acct.Domain != "" {
+		opts = append(opts, api.WithBaseURL("https://"+acct.Domain+"/voyager/api"))
+	}
 	if debug {
 		opts = append(opts, api.WithDebug(os.Stderr))
 	}
+	if cfg.MaxResponseMB > 0 {
+		opts = append(opts, api.WithMaxResponseSize(int64(cfg.MaxResponseMB)<<20))
+	}
+	if recordDir != "" && replayDir != "" {
+		return nil, fmt.Errorf("--record and --replay are mutually exclusive")
+	}
+	if recordDir != "" {
+		opts = append(opts, api.WithRecorder(recordDir))
+	}
+	if replayDir != "" {
+		opts = append(opts, api.WithReplayer(replayDir))
+	}
+	if harPath != "" {
+		opts = append(opts, api.WithHAR(harPath, harSecrets))
+	}
+	if !noCache && recordDir == "" && replayDir == "" {
+		cache, err := newCache(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, api.WithCache(cache))
+	}
+	if replayDir == "" {
+		rates := make(map[api.EndpointClass]api.Rate, len(cfg.RateLimits))
+		for class, r := range cfg.RateLimits {
+			rates[api.EndpointClass(class)] = api.Rate{Burst: r.Burst, PerMinute: r.PerMinute}
+		}
+		limiter := api.NewFileLimiter(filepath.Join(filepath.Dir(path), rateLimitFile(cfg.Selected())), rates)
+		opts = append(opts, api.WithRateLimiter(limiter))
+	}
+	if store != nil {
+		account := cfg.Selected()
+		opts = append(opts, api.WithCookieRotation(func(c auth.Cookies) {
+			err := store.Put(account, c)
+			if err == nil {
+				err = saveSessionExpiry(path, account, c.Expires)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: could not save rotated session cookies: %v\n", err)
+			}
+		}))
+	}
+	opts = append(opts, extra...)
+	opts = append(opts, clientOptions...)
 	client, err := api.NewClient(cookies, opts...)
 	if err != nil {
 		return nil, err
 	}
 	li := api.NewBragnet(client)
-	li.SearchQueryID = cfg.SearchQueryID
-	li.ConversationsQueryID = cfg.ConversationsQueryID
-	li.MessagesQueryID = cfg.MessagesQueryID
+	configureQueries(li.Queries, cfg)
 	return li, nil
 }
+
+// saveSessionExpiry records when account's li_at expires (zero if
+// unknown) in the config, whichever store holds the cookies.
+func saveSessionExpiry(path, account string, expires time.Time) error {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Select(account); err != nil {
+		return err
+	}
+	if cfg.Auth.ExpiresAt.Equal(expires) {
+		return nil
+	}
+	cfg.Auth.ExpiresAt = expires
+	return saveConfig(path, cfg)
+}
+
+// configureQueries puts the queryIds from cfg ahead of the built-in
+// defaults: the hand-set single overrides first, then the selected
+// account's refreshed ones.
+func configureQueries(r *api.QueryRegistry, cfg config.Config) {
+	if acct := cfg.Account(); acct != nil {
+		for name, ids := range acct.QueryIDs {
+			r.Prefer(api.Query(name), ids...)
+		}
+	}
+	// Prefer puts ids first, so the explicit settings go in last.
+	r.Prefer(api.QuerySearchClusters, cfg.SearchQueryID)
+	r.Prefer(api.QueryConversations, cfg.ConversationsQueryID)
+	r.Prefer(api.QueryMessages, cfg.MessagesQueryID)
+}
+
+// rateLimitFile returns the limiter state file for account. Bragnet
+// throttles each session separately, so each account has its own budget;
+// the default one keeps the file from before accounts existed.
+func rateLimitFile(account string) string {
+	if account == "" || account == config.DefaultAccount {
+		return rateLimitFileName
+	}
+	return strings.TrimSuffix(rateLimitFileName, ".json") + "." + account + ".json"
+}
+
+// newCache opens the response cache under the user cache dir, with the
+// TTL overrides from cfg applied.
+func newCache(cfg config.Config) (*api.Cache, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	ttls := make(map[string]time.Duration, len(api.DefaultCacheTTLs)+len(cfg.CacheTTLs))
+	for path, ttl := range api.DefaultCacheTTLs {
+		ttls[path] = ttl
+	}
+	for path, s := range cfg.CacheTTLs {
+		ttl, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("config cache_ttls[%q]: %w", path, err)
+		}
+		ttls["/"+strings.TrimPrefix(path, "/")] = ttl
+	}
+	cache := api.NewCache(filepath.Join(dir, responseCacheDirName), ttls)
+	cache.Refresh = refreshCache
+	return cache, nil
+}
+
+// newResolver returns an identity resolver for li, caching mappings under
+// the user cache dir unless --no-cache, --record or --replay is set.
+func newResolver(li *api.Bragnet) (*api.Resolver, error) {
+	if noCache || recordDir != "" || replayDir != "" {
+		return api.NewResolver(li, nil), nil
+	}
+	cache, err := newIdentityCache()
+	if err != nil {
+		return nil, err
+	}
+	return api.NewResolver(li, cache), nil
+}
+
+func newIdentityCache() (*api.IdentityCache, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	cache := api.NewIdentityCache(filepath.Join(dir, identityCacheFileName))
+	cache.Refresh = refreshCache
+	return cache, nil
+}
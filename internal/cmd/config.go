@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get and set bragcli settings",
+	Long: `Read and change the settings in the config file. Values are checked before
+they are saved.
+
+Keys:
+`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting, or its default if unset",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		v, err := configValue(&cfg, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), v)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		prev := cfg
+		if err := cfg.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		return saveSettings(cmd, prev, cfg, path)
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting, so its default applies",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		prev := cfg
+		if err := cfg.Unset(args[0]); err != nil {
+			return err
+		}
+		return saveSettings(cmd, prev, cfg, path)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every setting as key=value",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		for _, k := range config.Keys {
+			v, err := configValue(&cfg, k.Name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", k.Name, v)
+		}
+		return nil
+	},
+}
+
+// saveSettings saves cfg, changed from prev by config set or unset. If
+// the credential store changed, the saved sessions move to the new one
+// first, so none is left behind in plaintext or lost.
+func saveSettings(cmd *cobra.Command, prev, cfg config.Config, path string) error {
+	if storeName(prev) == storeName(cfg) {
+		return saveConfig(path, cfg)
+	}
+	// Each store is opened once, so the encrypted one asks for its
+	// passphrase once however many accounts there are.
+	old := cfg
+	old.CredentialStore, old.CredentialHelper = prev.CredentialStore, prev.CredentialHelper
+	from, err := credentialStore(old, path)
+	if err != nil {
+		return err
+	}
+	to, err := credentialStore(cfg, path)
+	if err != nil {
+		return err
+	}
+	_, fromPlain := from.(auth.PlaintextStore)
+	_, toPlain := to.(auth.PlaintextStore)
+
+	// Read every session before writing any, so a failed read leaves the
+	// new store untouched.
+	type session struct {
+		account string
+		cookies auth.Cookies
+	}
+	var moved []session
+	for _, name := range cfg.AccountNames() {
+		if err := cfg.Select(name); err != nil {
+			return err
+		}
+		// The plaintext session is the one in cfg, not yet saved.
+		cookies := auth.Cookies{LiAt: cfg.Auth.LiAt, JSessionID: cfg.Auth.JSessionID}
+		if !fromPlain {
+			cookies, err = forAccount(from, cfg.Account()).Get(name)
+			if errors.Is(err, auth.ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("read the session of account %q from %s: %w", name, from, err)
+			}
+		}
+		if cookies.LiAt != "" {
+			moved = append(moved, session{name, cookies})
+		}
+	}
+	for _, m := range moved {
+		if err := cfg.Select(m.account); err != nil {
+			return err
+		}
+		if toPlain {
+			cfg.Auth.LiAt, cfg.Auth.JSessionID = m.cookies.LiAt, m.cookies.JSessionID
+			continue
+		}
+		if err := forAccount(to, cfg.Account()).Put(m.account, m.cookies); err != nil {
+			return fmt.Errorf("move the session of account %q to %s: %w", m.account, to, err)
+		}
+		cfg.Auth.LiAt, cfg.Auth.JSessionID = "", ""
+	}
+	if _, ok := cfg.Accounts[cfg.CurrentAccount]; ok {
+		if err := cfg.Select(cfg.CurrentAccount); err != nil {
+			return err
+		}
+	}
+	if err := saveConfig(path, cfg); err != nil {
+		return err
+	}
+
+	// Only once the config points at the new store are the sessions
+	// removed from the old one.
+	var names []string
+	for _, m := range moved {
+		names = append(names, m.account)
+		if fromPlain {
+			continue
+		}
+		if err := forAccount(from, cfg.Accounts[m.account]).Delete(m.account); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not remove the session of account %q from %s: %v\n", m.account, from, err)
+		}
+	}
+	if len(names) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Moved the sessions of %s to %s\n", strings.Join(names, ", "), storeName(cfg))
+	}
+	return nil
+}
+
+// forAccount returns store as used for acct: a credential helper is told
+// the account's domain.
+func forAccount(store auth.CredentialStore, acct *config.Account) auth.CredentialStore {
+	if h, ok := store.(auth.HelperStore); ok && acct != nil {
+		h.Host = acct.Domain
+		return h
+	}
+	return store
+}
+
+// storeName names the credential store cfg uses.
+func storeName(cfg config.Config) string {
+	switch cfg.CredentialStore {
+	case "", auth.StorePlaintext:
+		return "the config file"
+	case auth.StoreHelper:
+		return "credential helper " + cfg.CredentialHelper
+	}
+	return "the " + cfg.CredentialStore + " store"
+}
+
+// configValue returns the setting name from cfg, or its default.
+func configValue(cfg *config.Config, name string) (string, error) {
+	v, err := cfg.Get(name)
+	if err != nil || v != "" {
+		return v, err
+	}
+	k, err := config.LookupKey(name)
+	return k.Default, err
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+
+	var b strings.Builder
+	for _, k := range config.Keys {
+		help := k.Help
+		if len(k.Values) > 0 {
+			help += " (" + strings.Join(k.Values, ", ") + ")"
+		}
+		fmt.Fprintf(&b, "  %-24s %s\n", k.Name, help)
+	}
+	configCmd.Long += strings.TrimSuffix(b.String(), "\n")
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/janitrai/bragcli/internal/auth"
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +11,7 @@ var connectNote string
 var connectCmd = &cobra.Command{
 	Use:   "connect [username]",
 	Short: "Send a connection request",
+	Long:  "Send a connection request.\n\n" + identifierHelp,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, _, err := loadConfig()
@@ -23,16 +23,17 @@ var connectCmd = &cobra.Command{
 			return err
 		}
 
-		publicID := auth.NormalizePublicIdentifier(args[0])
-		profile, err := li.GetProfile(cmd.Context(), publicID)
+		resolver, err := newResolver(li)
 		if err != nil {
 			return err
 		}
-		if profile.MiniProfileEntityURN == "" {
-			return fmt.Errorf("could not determine profile URN for %q", publicID)
+		target, err := resolver.Resolve(cmd.Context(), args[0])
+		if err != nil {
+			return err
 		}
+		publicID := target.PublicIdentifier
 
-		if err := li.Connect(cmd.Context(), profile.MiniProfileEntityURN, connectNote); err != nil {
+		if err := li.Connect(cmd.Context(), target.ProfileURN, connectNote); err != nil {
 			return err
 		}
 
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/janitrai/bragcli/internal/api"
+)
+
+// schemaDrift collects the drift reported by API calls during one command
+// run, so Execute can print a single warning at the end.
+var schemaDrift []api.SchemaDrift
+
+// noteSchemaDrift is the client's drift handler: it fails the call under
+// --strict and otherwise records the drift for printSchemaDrift.
+func noteSchemaDrift(d api.SchemaDrift) error {
+	if strict {
+		return &api.SchemaDriftError{SchemaDrift: d}
+	}
+	for i, seen := range schemaDrift {
+		if seen.Endpoint == d.Endpoint {
+			schemaDrift[i].Missing = mergeMissing(seen.Missing, d.Missing)
+			return nil
+		}
+	}
+	schemaDrift = append(schemaDrift, d)
+	return nil
+}
+
+// printSchemaDrift writes one warning covering every endpoint that drifted
+// during the run, then resets the collection.
+func printSchemaDrift(w io.Writer) {
+	if len(schemaDrift) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "warning: Bragnet responses are missing expected fields; some output may be blank:")
+	for _, d := range schemaDrift {
+		fmt.Fprintf(w, "  %s\n", d)
+	}
+	fmt.Fprintln(w, "  (bragcli may need an update; pass --strict to fail instead)")
+	schemaDrift = nil
+}
+
+func mergeMissing(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	out := append([]string(nil), a...)
+	for _, s := range a {
+		seen[s] = true
+	}
+	for _, s := range b {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
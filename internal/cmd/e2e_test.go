@@ -0,0 +1,899 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/janitrai/bragcli/internal/api"
+	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/config"
+	"github.com/janitrai/bragcli/internal/fakebragnet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ---------------------------------------------------------------------------
+// Harness
+// ---------------------------------------------------------------------------
+
+type cliEnv struct {
+	srv     *fakebragnet.Server
+	cfgPath string
+	// opts are extra client options for every command.
+	opts []api.Option
+}
+
+func newCLIEnv(t *testing.T) *cliEnv {
+	t.Helper()
+	srv := fakebragnet.New(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path := t.TempDir() + "/config.json"
+	liAt, jsession := srv.Cookies()
+	if err := config.Save(path, config.Config{Auth: config.AuthConfig{LiAt: liAt, JSessionID: jsession}}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	return &cliEnv{srv: srv, cfgPath: path}
+}
+
+// run executes bragcli with args against the fake server.
+func (e *cliEnv) run(t *testing.T, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	out, errOut := e.prepare(t, args)
+	err = execute()
+	return out.String(), errOut.String(), err
+}
+
+// status runs bragcli as main does and returns its exit status.
+func (e *cliEnv) status(t *testing.T, args ...string) int {
+	t.Helper()
+	e.prepare(t, args)
+	return Execute()
+}
+
+func (e *cliEnv) prepare(t *testing.T, args []string) (out, errOut *bytes.Buffer) {
+	resetFlags(rootCmd)
+	clientOptions = append([]api.Option{api.WithBaseURL(e.srv.BaseURL()), api.WithRetryPolicy(api.NoRetry)}, e.opts...)
+	t.Cleanup(func() { clientOptions = nil })
+
+	out, errOut = new(bytes.Buffer), new(bytes.Buffer)
+	rootCmd.SetOut(out)
+	rootCmd.SetErr(errOut)
+	rootCmd.SetArgs(append([]string{"--config", e.cfgPath}, args...))
+	return out, errOut
+}
+
+func (e *cliEnv) mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	out, stderr, err := e.run(t, args...)
+	if err != nil {
+		t.Fatalf("bragcli %s: %v\nstderr: %s", strings.Join(args, " "), err, stderr)
+	}
+	return out
+}
+
+// resetFlags restores every flag to its default, since cobra keeps flag
+// values in package variables between Execute calls.
+func resetFlags(c *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if f.Changed {
+			_ = f.Value.Set(f.DefValue)
+			f.Changed = false
+		}
+	}
+	c.Flags().VisitAll(reset)
+	c.PersistentFlags().VisitAll(reset)
+	for _, sub := range c.Commands() {
+		resetFlags(sub)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func assertContains(t *testing.T, got string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("output missing %q:\n%s", w, got)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Commands
+// ---------------------------------------------------------------------------
+
+func TestE2E_Profile(t *testing.T) {
+	e := newCLIEnv(t)
+
+	out := e.mustRun(t, "profile", "view", "@jane-smith")
+	assertContains(t, out, "Name: Jane Smith", "Headline: VP of Engineering", "Public ID: jane-smith")
+
+	out = e.mustRun(t, "profile", "me")
+	assertContains(t, out, "Name: John Doe")
+}
+
+func TestE2E_Search(t *testing.T) {
+	e := newCLIEnv(t)
+
+	out := e.mustRun(t, "search", "people", "engineer")
+	assertContains(t, out, "jane-smith\tJane Smith", "alice-johnson\tAlice Johnson")
+
+	out = e.mustRun(t, "search", "jobs", "go")
+	assertContains(t, out, "Senior Go Engineer\tACME Corp")
+}
+
+func TestE2E_Posts(t *testing.T) {
+	e := newCLIEnv(t)
+
+	out := e.mustRun(t, "post", "list", "--limit", "2")
+	if n := strings.Count(out, "\n"); n != 2 {
+		t.Fatalf("post list --limit 2 printed %d lines:\n%s", n, out)
+	}
+
+	out = e.mustRun(t, "post", "create", "Hello", "from", "tests")
+	assertContains(t, out, "Posted: urn:li:share:")
+
+	out = e.mustRun(t, "post", "list", "--all", "--limit", "2")
+	assertContains(t, out, "Hello from tests", "Excited to share my latest project!")
+}
+
+func TestE2E_FollowAndConnect(t *testing.T) {
+	e := newCLIEnv(t)
+
+	out := e.mustRun(t, "follow", "jane-smith")
+	assertContains(t, out, "Followed jane-smith")
+	if len(e.srv.Follows) != 1 {
+		t.Fatalf("follows = %v", e.srv.Follows)
+	}
+
+	out = e.mustRun(t, "connect", "bob-williams", "--note", "Hi Bob")
+	assertContains(t, out, "(with note)")
+	if len(e.srv.Invitations) != 1 || e.srv.Invitations[0].Note != "Hi Bob" {
+		t.Fatalf("invitations = %+v", e.srv.Invitations)
+	}
+}
+
+func TestE2E_Messaging(t *testing.T) {
+	e := newCLIEnv(t)
+
+	out := e.mustRun(t, "message", "list")
+	assertContains(t, out, "Jane Smith", "Doing well, thanks!", "Bob Williams")
+
+	out = e.mustRun(t, "message", "read", "jane-smith")
+	assertContains(t, out, "Conversation with Jane Smith", "Jane Smith:\nHey John, how are you?")
+
+	e.mustRun(t, "message", "send", "jane-smith", "See", "you", "soon")
+	e.mustRun(t, "message", "send", "alice-johnson", "Nice to meet you")
+	if n := len(e.srv.Conversations); n != 3 {
+		t.Fatalf("conversations = %d, want 3 (one new)", n)
+	}
+	out = e.mustRun(t, "message", "read", "jane-smith")
+	assertContains(t, out, "See you soon")
+}
+
+func TestE2E_IdentifierForms(t *testing.T) {
+	e := newCLIEnv(t)
+	jane := e.srv.Person("jane-smith")
+
+	for _, who := range []string{
+		"@jane-smith",
+		auth.BaseURL() + "/in/jane-smith/",
+		jane.ProfileURN(),
+		"urn:li:member:" + jane.ProfileID,
+		"urn:li:msg_participant:(" + e.srv.Me.ProfileURN() + "," + jane.ProfileURN() + ")",
+	} {
+		out := e.mustRun(t, "message", "read", who)
+		assertContains(t, out, "Conversation with Jane Smith")
+	}
+
+	out := e.mustRun(t, "follow", jane.ProfileURN())
+	assertContains(t, out, "Followed jane-smith")
+
+	for _, bad := range []string{"urn:li:organization:42", jane.MemberURN()} {
+		_, _, err := e.run(t, "connect", bad)
+		if err == nil || !strings.Contains(err.Error(), "unsupported identifier") {
+			t.Fatalf("connect %s: err = %v", bad, err)
+		}
+	}
+}
+
+func TestE2E_IdentityCache(t *testing.T) {
+	e := newCLIEnv(t)
+	profileFetches := func() int {
+		n := 0
+		for _, r := range e.srv.Requests() {
+			if strings.HasSuffix(r, "/identity/dash/profiles") {
+				n++
+			}
+		}
+		return n
+	}
+
+	bob := e.srv.Person("bob-williams")
+
+	// Looking bob up by URN requests a URL the response cache hasn't seen,
+	// so only the identity cache can answer it.
+	e.mustRun(t, "follow", "bob-williams")
+	e.mustRun(t, "connect", bob.ProfileURN())
+	if n := profileFetches(); n != 1 {
+		t.Fatalf("profile fetched %d times, want 1", n)
+	}
+
+	e.mustRun(t, "cache", "clear")
+	e.mustRun(t, "connect", bob.ProfileURN())
+	if n := profileFetches(); n != 2 {
+		t.Fatalf("after clear: profile fetched %d times, want 2", n)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Failures
+// ---------------------------------------------------------------------------
+
+func TestE2E_FailureExitCodes(t *testing.T) {
+	tests := []struct {
+		failure  fakebragnet.Failure
+		wantCode int
+		wantHint string
+	}{
+		{fakebragnet.FailRateLimit, ExitRateLimited, "throttling"},
+		{fakebragnet.FailSessionExpired, ExitAuth, "bragcli auth login"},
+		{fakebragnet.FailChallenge, ExitChallenge, "security check"},
+	}
+	for _, tt := range tests {
+		e := newCLIEnv(t)
+		e.srv.Inject(tt.failure, "", 0)
+
+		_, stderr, err := e.run(t, "profile", "me")
+		if got := ExitCode(err); got != tt.wantCode {
+			t.Errorf("failure %d: exit code = %d, want %d (err: %v)", tt.failure, got, tt.wantCode, err)
+		}
+		assertContains(t, stderr, tt.wantHint)
+	}
+}
+
+func TestE2E_ExitStatus(t *testing.T) {
+	e := newCLIEnv(t)
+	if got := e.status(t, "profile", "me"); got != ExitOK {
+		t.Errorf("profile me: status %d, want %d", got, ExitOK)
+	}
+	if got := e.status(t, "no-such-command"); got != ExitError {
+		t.Errorf("unknown command: status %d, want %d", got, ExitError)
+	}
+	if got := e.status(t, "--config", filepath.Join(t.TempDir(), "config.json"), "profile", "me"); got != ExitAuth {
+		t.Errorf("logged out: status %d, want %d", got, ExitAuth)
+	}
+	if got := e.status(t, "profile", "view", "nobody-here"); got != ExitNotFound {
+		t.Errorf("not found: status %d, want %d", got, ExitNotFound)
+	}
+
+	e.srv.Inject(fakebragnet.FailSchemaDrift, "/voyagerMessagingGraphQL", 0)
+	if got := e.status(t, "--strict", "message", "read", "jane-smith"); got != ExitSchemaDrift {
+		t.Errorf("--strict drift: status %d, want %d", got, ExitSchemaDrift)
+	}
+	e.srv.ClearFailures()
+
+	e.srv.Inject(fakebragnet.FailChallenge, "", 0)
+	if got := e.status(t, "--no-cache", "profile", "me"); got != ExitChallenge {
+		t.Errorf("security check: status %d, want %d", got, ExitChallenge)
+	}
+}
+
+func TestE2E_ProfileNotFound(t *testing.T) {
+	e := newCLIEnv(t)
+	_, _, err := e.run(t, "profile", "view", "nobody-here")
+	if got := ExitCode(err); got != ExitNotFound {
+		t.Fatalf("exit code = %d, want %d (err: %v)", got, ExitNotFound, err)
+	}
+}
+
+func TestE2E_SchemaDrift(t *testing.T) {
+	e := newCLIEnv(t)
+	e.srv.Inject(fakebragnet.FailSchemaDrift, "/identity/dash/profiles", 0)
+
+	_, _, err := e.run(t, "profile", "view", "jane-smith")
+	if err == nil || !strings.Contains(err.Error(), "publicIdentifier") {
+		t.Fatalf("err = %v, want missing publicIdentifier", err)
+	}
+}
+
+func TestE2E_SchemaDriftWarning(t *testing.T) {
+	e := newCLIEnv(t)
+
+	_, stderr, err := e.run(t, "message", "read", "jane-smith")
+	if err != nil || strings.Contains(stderr, "warning") {
+		t.Fatalf("clean run: err = %v, stderr = %q", err, stderr)
+	}
+
+	e.srv.Inject(fakebragnet.FailSchemaDrift, "/voyagerMessagingGraphQL", 0)
+	_, stderr, err = e.run(t, "message", "read", "jane-smith")
+	if err != nil {
+		t.Fatalf("drift without --strict: %v", err)
+	}
+	if n := strings.Count(stderr, "warning:"); n != 1 {
+		t.Errorf("got %d warnings, want 1:\n%s", n, stderr)
+	}
+	assertContains(t, stderr,
+		"messengerConversations: ",
+		"messengerMessages: Message.body, MessagingParticipant.participantType.member.firstName, MessagingParticipant.participantType.member.lastName",
+	)
+
+	_, _, err = e.run(t, "--strict", "message", "read", "jane-smith")
+	if got := ExitCode(err); got != ExitSchemaDrift {
+		t.Fatalf("--strict exit code = %d, want %d (err: %v)", got, ExitSchemaDrift, err)
+	}
+}
+
+func TestE2E_RotatedCookiesArePersisted(t *testing.T) {
+	e := newCLIEnv(t)
+	e.srv.RotateSession("rotated-li-at")
+
+	e.mustRun(t, "profile", "me")
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.LiAt != "rotated-li-at" {
+		t.Fatalf("li_at in config = %q, want rotated value", cfg.Auth.LiAt)
+	}
+	// The next run must work with the persisted cookie.
+	e.mustRun(t, "profile", "me")
+}
+
+func TestE2E_Accounts(t *testing.T) {
+	e := newCLIEnv(t)
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second account whose session the fake server doesn't know.
+	cfg.AddAccount("recruiting").Auth = config.AuthConfig{LiAt: "other", JSessionID: "ajax:other"}
+	if err := config.Save(e.cfgPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	out := e.mustRun(t, "auth", "list")
+	assertContains(t, out, "* default", "  recruiting", "logged in")
+
+	e.mustRun(t, "profile", "me")
+	_, _, err = e.run(t, "--account", "recruiting", "profile", "me")
+	if ExitCode(err) != ExitAuth {
+		t.Fatalf("--account recruiting: err = %v, want the other session used", err)
+	}
+	_, stderr, err := e.run(t, "--account", "nope", "profile", "me")
+	if !errors.Is(err, config.ErrUnknownAccount) {
+		t.Fatalf("--account nope: err = %v", err)
+	}
+	assertContains(t, stderr, "bragcli auth list")
+
+	out = e.mustRun(t, "auth", "switch", "recruiting")
+	assertContains(t, out, `Switched to account "recruiting"`)
+	if _, _, err := e.run(t, "profile", "me"); ExitCode(err) != ExitAuth {
+		t.Fatalf("after switch: err = %v, want the recruiting session used", err)
+	}
+	out = e.mustRun(t, "auth", "list")
+	assertContains(t, out, "  default", "* recruiting")
+
+	out = e.mustRun(t, "auth", "logout")
+	assertContains(t, out, `Logged out of account "recruiting"`, `Current account is now "default"`)
+	e.mustRun(t, "profile", "me")
+	if _, _, err := e.run(t, "auth", "switch", "recruiting"); !errors.Is(err, config.ErrUnknownAccount) {
+		t.Fatalf("switch to removed account: err = %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--account", "../x", "auth", "login", "--manual"},
+		{"auth", "switch", ".."},
+		{"auth", "logout", "a/../../x"},
+		{"--account", "a\nb", "profile", "me"},
+	} {
+		if _, _, err := e.run(t, args...); !errors.Is(err, config.ErrInvalidAccountName) {
+			t.Errorf("bragcli %s: err = %v, want ErrInvalidAccountName", strings.Join(args, " "), err)
+		}
+	}
+}
+
+func TestE2E_RotatedCookiesPersistedToAccount(t *testing.T) {
+	e := newCLIEnv(t)
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	liAt, jsession := e.srv.Cookies()
+	cfg.AddAccount("work").Auth = config.AuthConfig{LiAt: liAt, JSessionID: jsession}
+	if err := config.Save(e.cfgPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+	e.srv.RotateSession("rotated-li-at")
+
+	e.mustRun(t, "--account", "work", "profile", "me")
+	cfg, err = config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Accounts["work"].Auth.LiAt; got != "rotated-li-at" {
+		t.Errorf("work li_at = %q, want rotated value", got)
+	}
+	if got := cfg.Accounts[config.DefaultAccount].Auth.LiAt; got != liAt {
+		t.Errorf("default li_at = %q, want it untouched", got)
+	}
+}
+
+func TestE2E_EncryptedCredentialStore(t *testing.T) {
+	e := newCLIEnv(t)
+	t.Setenv(auth.EnvPassphrase, "correct horse")
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.CredentialStore = auth.StoreEncrypted
+	cfg.Auth = config.AuthConfig{}
+	if err := config.Save(e.cfgPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := e.run(t, "profile", "me"); ExitCode(err) != ExitAuth {
+		t.Fatalf("profile me with empty store: err = %v, want an auth error", err)
+	}
+
+	store, err := credentialStore(cfg, e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	liAt, jsession := e.srv.Cookies()
+	if err := store.Put(config.DefaultAccount, auth.Cookies{LiAt: liAt, JSessionID: jsession}); err != nil {
+		t.Fatal(err)
+	}
+	e.srv.RotateSession("rotated-li-at")
+	e.mustRun(t, "profile", "me")
+
+	b, err := os.ReadFile(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "rotated-li-at") || strings.Contains(string(b), liAt) {
+		t.Errorf("config holds the session cookie:\n%s", b)
+	}
+	reopened, _ := credentialStore(cfg, e.cfgPath)
+	if got, err := reopened.Get(config.DefaultAccount); err != nil || got.LiAt != "rotated-li-at" {
+		t.Errorf("stored cookies = %+v, %v; want the rotated li_at", got, err)
+	}
+
+	out := e.mustRun(t, "auth", "list")
+	assertContains(t, out, "session in encrypted store")
+
+	t.Setenv(auth.EnvPassphrase, "wrong")
+	_, stderr, err := e.run(t, "profile", "me")
+	if !errors.Is(err, auth.ErrBadPassphrase) || ExitCode(err) != ExitAuth {
+		t.Fatalf("wrong passphrase: err = %v", err)
+	}
+	assertContains(t, stderr, auth.EnvPassphrase)
+
+	t.Setenv(auth.EnvPassphrase, "correct horse")
+	e.mustRun(t, "auth", "logout")
+	if _, err := reopened.Get(config.DefaultAccount); !errors.Is(err, auth.ErrNoCredentials) {
+		t.Errorf("after logout: err = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestE2E_ImportExportCookies(t *testing.T) {
+	e := newCLIEnv(t)
+	liAt, jsession := e.srv.Cookies()
+	dir := t.TempDir()
+	in := filepath.Join(dir, "cookies.txt")
+	txt := "# Netscape HTTP Cookie File\n" +
+		"#HttpOnly_." + auth.Domain() + "\tTRUE\t/\tTRUE\t0\tli_at\t" + liAt + "\n" +
+		"." + auth.Domain() + "\tTRUE\t/\tTRUE\t0\tJSESSIONID\t" + jsession + "\n"
+	if err := os.WriteFile(in, []byte(txt), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	const firefoxUA = "Mozilla/5.0 (X11; Linux x86_64; rv:140.0) Gecko/20100101 Firefox/140.0"
+	var sentUA []string
+	e.opts = []api.Option{api.WithHTTPClient(&http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			sentUA = append(sentUA, r.Header.Get("user-agent")+" | "+r.Header.Get("accept-language"))
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	})}
+
+	out := e.mustRun(t, "--account", "imported", "auth", "import", in, "--user-agent", firefoxUA, "--accept-language", "de-DE,de;q=0.9")
+	assertContains(t, out, `to account "imported"`, "("+auth.FormatNetscape+")")
+	out = e.mustRun(t, "profile", "me")
+	assertContains(t, out, "Name: John Doe")
+	if len(sentUA) == 0 || sentUA[0] != firefoxUA+" | de-DE,de;q=0.9" {
+		t.Errorf("sent user-agent | accept-language = %q, want the imported browser's", sentUA)
+	}
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CurrentAccount != "imported" || cfg.Auth.UserAgent != firefoxUA {
+		t.Errorf("current account = %q with auth %+v, want imported with the Firefox UA", cfg.CurrentAccount, cfg.Auth)
+	}
+
+	exported := filepath.Join(dir, "exported.txt")
+	e.mustRun(t, "auth", "export", "--output", exported)
+	got, _, err := auth.ImportCookies(exported, auth.Domain())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LiAt != liAt || got.CSRFToken() != (auth.Cookies{JSessionID: jsession}).CSRFToken() {
+		t.Errorf("exported %+v, want the imported session", got)
+	}
+	if fi, err := os.Stat(exported); err == nil && fi.Mode().Perm() != 0o600 {
+		t.Errorf("export mode = %v, want 0600", fi.Mode().Perm())
+	}
+
+	out = e.mustRun(t, "auth", "export")
+	assertContains(t, out, "# Netscape HTTP Cookie File", "\tli_at\t"+liAt)
+
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, []byte("# Netscape HTTP Cookie File\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := e.run(t, "auth", "import", empty); !errors.Is(err, auth.ErrNoCookies) {
+		t.Errorf("import without cookies: err = %v, want ErrNoCookies", err)
+	}
+}
+
+func TestE2E_KeptChromeProfile(t *testing.T) {
+	e := newCLIEnv(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	_, _, err := e.run(t, "auth", "refresh")
+	if err == nil || !strings.Contains(err.Error(), "--keep-profile") {
+		t.Fatalf("refresh without a kept profile: err = %v", err)
+	}
+
+	profile, err := chromeProfileDir(config.DefaultAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(profile, "Default"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Account().ChromeProfile = profile
+	if err := config.Save(e.cfgPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	e.mustRun(t, "auth", "logout")
+	if _, err := os.Stat(profile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("profile after logout: %v, want it removed", err)
+	}
+
+	// A profile path outside the profiles dir is left alone.
+	for _, outside := range []string{t.TempDir(), filepath.Join(filepath.Dir(profile), "..", "other")} {
+		if err := os.MkdirAll(outside, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := config.Load(e.cfgPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.AddAccount(config.DefaultAccount).ChromeProfile = outside
+		cfg.CurrentAccount = config.DefaultAccount
+		if err := config.Save(e.cfgPath, cfg); err != nil {
+			t.Fatal(err)
+		}
+		_, stderr, err := e.run(t, "auth", "logout")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertContains(t, stderr, "not removing it")
+		if _, err := os.Stat(outside); err != nil {
+			t.Errorf("profile %s outside the profiles dir after logout: %v, want it kept", outside, err)
+		}
+	}
+}
+
+func TestE2E_QueryIDsRefresh(t *testing.T) {
+	e := newCLIEnv(t)
+	const rotated = "voyagerSearchDashClusters.00112233445566778899aabbccddeeff"
+	e.srv.QueryIDs["voyagerSearchDashClusters"] = rotated
+
+	_, stderr, err := e.run(t, "search", "people", "engineer")
+	if !errors.Is(err, api.ErrStaleQueryID) {
+		t.Fatalf("search with rotated queryId: err = %v, want ErrStaleQueryID", err)
+	}
+	assertContains(t, stderr, "bragcli queryids refresh")
+
+	out := e.mustRun(t, "queryids", "refresh")
+	assertContains(t, out, rotated, "messengerConversations.", "messengerMessages.")
+
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Account().QueryIDs["voyagerSearchDashClusters"]; len(got) != 1 || got[0] != rotated {
+		t.Errorf("saved search queryIds = %v, want [%s]", got, rotated)
+	}
+	if cfg.Auth.LiAt == "" {
+		t.Error("refresh dropped the session cookies")
+	}
+
+	out = e.mustRun(t, "search", "people", "engineer")
+	assertContains(t, out, "jane-smith\tJane Smith")
+
+	// A queryId set by hand is tried before the refreshed ones.
+	const manual = "voyagerSearchDashClusters.ffeeddccbbaa99887766554433221100"
+	e.mustRun(t, "config", "set", "search_query_id", manual)
+	out = e.mustRun(t, "auth", "status", "--verbose")
+	assertContains(t, out, manual+", "+rotated)
+}
+
+func TestE2E_ResponseCache(t *testing.T) {
+	e := newCLIEnv(t)
+	profileFetches := func() int {
+		n := 0
+		for _, r := range e.srv.Requests() {
+			if strings.HasSuffix(r, "/identity/dash/profiles") {
+				n++
+			}
+		}
+		return n
+	}
+
+	e.mustRun(t, "profile", "view", "jane-smith")
+	out := e.mustRun(t, "profile", "view", "jane-smith")
+	assertContains(t, out, "Name: Jane Smith")
+	if n := profileFetches(); n != 1 {
+		t.Fatalf("profile fetched %d times, want 1 (second run cached)", n)
+	}
+
+	e.mustRun(t, "--no-cache", "profile", "view", "jane-smith")
+	if n := profileFetches(); n != 2 {
+		t.Fatalf("--no-cache: profile fetched %d times, want 2", n)
+	}
+
+	// --refresh fetches and updates the entry.
+	e.srv.Person("jane-smith").Headline = "CTO"
+	out = e.mustRun(t, "--refresh", "profile", "view", "jane-smith")
+	assertContains(t, out, "Headline: CTO")
+	out = e.mustRun(t, "profile", "view", "jane-smith")
+	assertContains(t, out, "Headline: CTO")
+	if n := profileFetches(); n != 3 {
+		t.Fatalf("--refresh: profile fetched %d times, want 3", n)
+	}
+
+	out = e.mustRun(t, "cache", "clear")
+	assertContains(t, out, "Cleared 1 cached responses")
+	e.mustRun(t, "profile", "view", "jane-smith")
+	if n := profileFetches(); n != 4 {
+		t.Fatalf("after clear: profile fetched %d times, want 4", n)
+	}
+}
+
+func TestE2E_AuthStatus(t *testing.T) {
+	e := newCLIEnv(t)
+	const ua = "Mozilla/5.0 (X11; Linux x86_64) Chrome/140.0.0.0 Safari/537.36"
+	const searchID = "voyagerSearchDashClusters.0123456789abcdef0123456789abcdef"
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Auth.UserAgent = ua
+	cfg.Auth.ExpiresAt = time.Now().Add(72 * time.Hour).UTC()
+	cfg.SearchQueryID = searchID
+	if err := config.Save(e.cfgPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	out, stderr, err := e.run(t, "auth", "status", "--verbose")
+	if err != nil {
+		t.Fatalf("auth status --verbose: %v\nstderr: %s", err, stderr)
+	}
+	assertContains(t, out, "Logged in as John Doe", "Domain:", auth.Domain(), ua,
+		"QueryIds voyagerSearchDashClusters:", searchID, "Endpoint messaging:")
+	assertContains(t, stderr, "Warning: li_at expires in 2 days")
+
+	// A failing endpoint other than /me is reported but the session works.
+	e.srv.Inject(fakebragnet.FailServerError, "/voyagerMessagingGraphQL", 0)
+	out = e.mustRun(t, "auth", "status", "--json")
+	var r sessionReport
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		t.Fatalf("parse --json output: %v\n%s", err, out)
+	}
+	if !r.Usable || r.UserAgent != ua || r.ExpiresAt == nil || r.SavedAt == nil || len(r.QueryIDs["voyagerSearchDashClusters"]) != 1 {
+		t.Errorf("report = %+v", r)
+	}
+	var health []string
+	for _, c := range r.Endpoints {
+		health = append(health, fmt.Sprintf("%s=%v", c.Name, c.OK))
+	}
+	if got := strings.Join(health, " "); got != "me=true profile=true search=true messaging=false" {
+		t.Errorf("endpoints = %s", got)
+	}
+
+	e.srv.ClearFailures()
+	e.srv.Inject(fakebragnet.FailSessionExpired, "", 0)
+	out, _, err = e.run(t, "auth", "status", "--json")
+	if ExitCode(err) != ExitAuth {
+		t.Fatalf("expired session: err = %v, want exit %d", err, ExitAuth)
+	}
+	assertContains(t, out, `"usable": false`, `"logged_in": true`)
+
+	e.mustRun(t, "auth", "logout")
+	out, _, err = e.run(t, "auth", "status")
+	if ExitCode(err) != ExitAuth {
+		t.Fatalf("logged out: err = %v, want exit %d", err, ExitAuth)
+	}
+	assertContains(t, out, "Not logged in")
+}
+
+func TestE2E_InjectedCookies(t *testing.T) {
+	e := newCLIEnv(t)
+	liAt, jsession := e.srv.Cookies()
+	// The saved session is one the server doesn't know, so commands only
+	// work with the injected one.
+	if err := config.Save(e.cfgPath, config.Config{Auth: config.AuthConfig{LiAt: "stale", JSessionID: "ajax:stale"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(auth.EnvLiAt, liAt)
+	t.Setenv(auth.EnvJSessionID, jsession)
+	out := e.mustRun(t, "auth", "status", "--verbose")
+	assertContains(t, out, "Session from $"+auth.EnvLiAt, "Session source:", "environment")
+	e.srv.RotateSession("rotated-li-at")
+	out = e.mustRun(t, "profile", "me")
+	assertContains(t, out, "Name: John Doe")
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.LiAt != "stale" {
+		t.Errorf("li_at in config = %q, want the injected session never saved", cfg.Auth.LiAt)
+	}
+
+	t.Setenv(auth.EnvJSessionID, "")
+	if _, _, err := e.run(t, "profile", "me"); ExitCode(err) != ExitAuth || !strings.Contains(err.Error(), auth.EnvJSessionID) {
+		t.Errorf("only $%s set: err = %v", auth.EnvLiAt, err)
+	}
+	t.Setenv(auth.EnvLiAt, "")
+
+	rootCmd.SetIn(strings.NewReader("li_at=rotated-li-at; JSESSIONID=" + jsession + "\n"))
+	t.Cleanup(func() { rootCmd.SetIn(nil) })
+	out = e.mustRun(t, "--cookies-from-stdin", "auth", "status", "--json")
+	assertContains(t, out, `"session_source": "stdin"`, `"usable": true`)
+	if _, _, err := e.run(t, "profile", "me"); ExitCode(err) != ExitAuth {
+		t.Errorf("without injection: err = %v, want the stale saved session used", err)
+	}
+}
+
+func TestE2E_Config(t *testing.T) {
+	e := newCLIEnv(t)
+
+	e.mustRun(t, "config", "set", "default_limit", "1")
+	e.mustRun(t, "config", "set", "time_zone", "Asia/Tokyo")
+	if out := e.mustRun(t, "config", "get", "default_limit"); out != "1\n" {
+		t.Errorf("config get default_limit = %q", out)
+	}
+	out := e.mustRun(t, "config", "list")
+	assertContains(t, out, "default_limit=1\n", "time_zone=Asia/Tokyo\n", "output_format=text\n", "max_response_mb=32\n")
+
+	if _, _, err := e.run(t, "config", "set", "default_limit", "none"); err == nil {
+		t.Error("invalid value: no error")
+	}
+	if _, _, err := e.run(t, "config", "get", "nope"); !errors.Is(err, config.ErrUnknownKey) {
+		t.Errorf("unknown key: err = %v", err)
+	}
+
+	out = e.mustRun(t, "post", "list")
+	if n := strings.Count(out, "\n"); n != 1 {
+		t.Errorf("post list with default_limit 1 printed %d lines:\n%s", n, out)
+	}
+	assertContains(t, out, "+09:00")
+	out = e.mustRun(t, "post", "list", "--limit", "2")
+	if n := strings.Count(out, "\n"); n != 2 {
+		t.Errorf("post list --limit 2 printed %d lines:\n%s", n, out)
+	}
+
+	e.mustRun(t, "config", "set", "output_format", "json")
+	out = e.mustRun(t, "auth", "status")
+	assertContains(t, out, `"usable": true`)
+	e.mustRun(t, "config", "unset", "output_format")
+	out = e.mustRun(t, "auth", "status")
+	assertContains(t, out, "Logged in as John Doe")
+
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultLimit != 1 || cfg.OutputFormat != "" || !cfg.Auth.LoggedIn() {
+		t.Errorf("saved config = %+v", cfg)
+	}
+}
+
+func TestE2E_PostCreateWithEditor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("editor script needs sh")
+	}
+	e := newCLIEnv(t)
+	e.mustRun(t, "config", "set", "editor", "printf 'Written in the editor' >")
+
+	out := e.mustRun(t, "post", "create")
+	assertContains(t, out, "Posted: urn:li:share:")
+	out = e.mustRun(t, "post", "list", "--all")
+	assertContains(t, out, "Written in the editor")
+
+	e.mustRun(t, "config", "set", "editor", "true")
+	if _, _, err := e.run(t, "post", "create"); err == nil || !strings.Contains(err.Error(), "empty post") {
+		t.Errorf("empty post: err = %v", err)
+	}
+}
+
+func TestE2E_ConfigSetMovesSessions(t *testing.T) {
+	e := newCLIEnv(t)
+	t.Setenv(auth.EnvPassphrase, "correct horse")
+	liAt, _ := e.srv.Cookies()
+	cfg, err := config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AddAccount("work").Auth = config.AuthConfig{LiAt: "work-li-at", JSessionID: "ajax:work"}
+	if err := config.Save(e.cfgPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	out := e.mustRun(t, "config", "set", "credential_store", "encrypted")
+	assertContains(t, out, "Moved the sessions of "+config.DefaultAccount+", work")
+	b, err := os.ReadFile(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), liAt) || strings.Contains(string(b), "work-li-at") {
+		t.Errorf("config still holds the session cookies:\n%s", b)
+	}
+	e.mustRun(t, "profile", "me", "--no-cache")
+
+	e.mustRun(t, "config", "unset", "credential_store")
+	cfg, err = config.Load(e.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.LiAt != liAt || cfg.Accounts["work"].Auth.LiAt != "work-li-at" {
+		t.Errorf("li_at after moving back = %q and %q, want %q and work-li-at", cfg.Auth.LiAt, cfg.Accounts["work"].Auth.LiAt, liAt)
+	}
+	store := &auth.EncryptedStore{Path: filepath.Join(filepath.Dir(e.cfgPath), credentialsFileName), Passphrase: readPassphrase}
+	if _, err := store.Get(config.DefaultAccount); !errors.Is(err, auth.ErrNoCredentials) {
+		t.Errorf("encrypted store after moving back: err = %v, want ErrNoCredentials", err)
+	}
+	e.mustRun(t, "profile", "me", "--no-cache")
+}
+
+func TestSessionChanged(t *testing.T) {
+	e := newCLIEnv(t)
+	store := auth.PlaintextStore{ConfigPath: e.cfgPath}
+	liAt, jsession := e.srv.Cookies()
+
+	for _, tt := range []struct {
+		cookies auth.Cookies
+		want    bool
+	}{
+		{auth.Cookies{LiAt: liAt, JSessionID: `"` + jsession + `"`, Expires: time.Now().Add(time.Hour)}, false},
+		{auth.Cookies{LiAt: "renewed", JSessionID: jsession}, true},
+	} {
+		got, err := sessionChanged(store, config.DefaultAccount, tt.cookies)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("sessionChanged(%+v) = %v, want %v", tt.cookies, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/janitrai/bragcli/internal/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// shellCommand runs command, a setting like "less -FR" or "code --wait",
+// with args appended: through sh, so quoting works as in a shell, except
+// on Windows, where it is split on spaces.
+func shellCommand(command string, args ...string) (*exec.Cmd, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, errors.New("empty command")
+	}
+	if runtime.GOOS == "windows" {
+		f := strings.Fields(command)
+		return exec.Command(f[0], append(f[1:], args...)...), nil
+	}
+	return exec.Command("sh", append([]string{"-c", command + ` "$@"`, "sh"}, args...)...), nil
+}
+
+// editText opens the editor from cfg, else $VISUAL or $EDITOR, on a
+// temporary file and returns what was saved in it, trimmed.
+func editText(cfg config.Config) (string, error) {
+	editor := cfg.Editor
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor == "" {
+			editor = os.Getenv(env)
+		}
+	}
+	if editor == "" {
+		return "", errors.New("no editor: set one with `bragcli config set editor vim`, or $EDITOR")
+	}
+
+	f, err := os.CreateTemp("", "bragcli-*.txt")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	_ = f.Close()
+	defer func() { _ = os.Remove(path) }()
+
+	c, err := shellCommand(editor, path)
+	if err != nil {
+		return "", fmt.Errorf("editor %q: %w", editor, err)
+	}
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("editor %q: %w", editor, err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// startPager sends cmd's output through cfg's pager when it is going to a
+// terminal. The returned func closes the pager and waits for it to exit;
+// call it once output is done.
+func startPager(cmd *cobra.Command, cfg config.Config) func() {
+	f, ok := cmd.OutOrStdout().(*os.File)
+	if cfg.Pager == "" || !ok || !term.IsTerminal(int(f.Fd())) {
+		return func() {}
+	}
+	p, err := shellCommand(cfg.Pager)
+	var w io.WriteCloser
+	if err == nil {
+		p.Stdout, p.Stderr = f, cmd.ErrOrStderr()
+		w, err = p.StdinPipe()
+	}
+	if err == nil {
+		err = p.Start()
+	}
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not start pager %q: %v\n", cfg.Pager, err)
+		return func() {}
+	}
+	cmd.SetOut(w)
+	return func() {
+		_ = w.Close()
+		_ = p.Wait()
+		cmd.SetOut(f)
+	}
+}
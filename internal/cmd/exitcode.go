@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/janitrai/bragcli/internal/api"
+	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/config"
+)
+
+// Process exit codes. These are part of the CLI's scripting contract; keep
+// them in sync with the "Exit codes" section of the README.
+const (
+	ExitOK          = 0
+	ExitError       = 1 // anything not listed below
+	ExitAuth        = 3 // not logged in, session expired, CSRF mismatch or bad passphrase
+	ExitChallenge   = 4 // Bragnet wants a browser security check
+	ExitNotFound    = 5 // profile, post or conversation doesn't exist
+	ExitRateLimited = 6 // throttled by Bragnet; retry later
+	ExitSchemaDrift = 7 // a response is missing required fields (or expected ones, with --strict)
+)
+
+// errNotLoggedIn is returned when the config has no session cookies.
+var errNotLoggedIn = errors.New("not logged in (missing li_at/JSESSIONID)")
+
+// ExitCode maps an error returned by a command to the process exit code.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, errNotLoggedIn),
+		errors.Is(err, api.ErrSessionExpired),
+		errors.Is(err, api.ErrCSRFMismatch),
+		errors.Is(err, auth.ErrBadPassphrase):
+		return ExitAuth
+	case errors.Is(err, api.ErrChallenge):
+		return ExitChallenge
+	case errors.Is(err, api.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, api.ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, api.ErrSchemaDrift):
+		return ExitSchemaDrift
+	}
+	return ExitError
+}
+
+// hint suggests what to do about err, or returns "".
+func hint(err error) string {
+	switch {
+	case errors.Is(err, errNotLoggedIn):
+		return "Run `bragcli auth login` to sign in."
+	case errors.Is(err, api.ErrSessionExpired):
+		return "Your Bragnet session has expired. Run `bragcli auth login` to sign in again."
+	case errors.Is(err, api.ErrCSRFMismatch):
+		return "The stored JSESSIONID no longer matches the session. Run `bragcli auth login` to refresh both cookies."
+	case errors.Is(err, api.ErrChallenge):
+		return "Bragnet wants a security check. Open Bragnet in your browser, complete it, then run `bragcli auth login`."
+	case errors.Is(err, api.ErrNotFound):
+		return "Check the username, URN or ID and try again."
+	case errors.Is(err, api.ErrRateLimited):
+		var httpErr *api.HTTPError
+		if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+			return fmt.Sprintf("Bragnet is throttling requests. Try again in %s.", httpErr.RetryAfter)
+		}
+		return "Bragnet is throttling requests. Wait a few minutes before trying again."
+	case errors.Is(err, api.ErrSchemaDrift):
+		return "Bragnet changed the format of its responses. Check for a newer bragcli, or report the missing fields."
+	case errors.Is(err, auth.ErrBadPassphrase):
+		return "Check the passphrase (or $" + auth.EnvPassphrase + "). If it is lost, delete the credentials file and run `bragcli auth login` again."
+	case errors.Is(err, config.ErrUnknownAccount):
+		return "Run `bragcli auth list` to see saved accounts, or `bragcli auth login --account NAME` to add one."
+	case errors.Is(err, api.ErrStaleQueryID):
+		return "Bragnet has probably rotated its GraphQL queryIds. Run `bragcli queryids refresh` to fetch the current ones."
+	}
+	return ""
+}
+
+func printHint(w io.Writer, err error) {
+	if h := hint(err); h != "" {
+		fmt.Fprintln(w, "Hint:", h)
+	}
+}
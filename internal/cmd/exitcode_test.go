@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/api"
+	"github.com/janitrai/bragcli/internal/auth"
+)
+
+func TestExitCode(t *testing.T) {
+	wrap := func(kind error) error {
+		return fmt.Errorf("get profile: %w", &api.HTTPError{StatusCode: 400, Kind: kind})
+	}
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{wrap(nil), ExitError},
+		{errNotLoggedIn, ExitAuth},
+		{wrap(api.ErrSessionExpired), ExitAuth},
+		{wrap(api.ErrCSRFMismatch), ExitAuth},
+		{fmt.Errorf("credentials.enc: %w", auth.ErrBadPassphrase), ExitAuth},
+		{wrap(api.ErrChallenge), ExitChallenge},
+		{wrap(api.ErrNotFound), ExitNotFound},
+		{wrap(api.ErrRateLimited), ExitRateLimited},
+		{&api.SchemaDriftError{}, ExitSchemaDrift},
+		{fmt.Errorf("parse: %w", &api.MissingFieldsError{}), ExitSchemaDrift},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHint_SuggestsLogin(t *testing.T) {
+	var sb strings.Builder
+	printHint(&sb, fmt.Errorf("x: %w", &api.HTTPError{Kind: api.ErrSessionExpired}))
+	if !strings.Contains(sb.String(), "bragcli auth login") {
+		t.Fatalf("hint = %q, want auth login suggestion", sb.String())
+	}
+
+	sb.Reset()
+	printHint(&sb, errors.New("boom"))
+	if sb.String() != "" {
+		t.Fatalf("hint for unknown error = %q, want empty", sb.String())
+	}
+}
@@ -3,13 +3,13 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/janitrai/bragcli/internal/auth"
 	"github.com/spf13/cobra"
 )
 
 var followCmd = &cobra.Command{
 	Use:   "follow [username]",
 	Short: "Follow a user",
+	Long:  "Follow a user.\n\n" + identifierHelp,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, _, err := loadConfig()
@@ -21,19 +21,22 @@ var followCmd = &cobra.Command{
 			return err
 		}
 
-		publicID := auth.NormalizePublicIdentifier(args[0])
-		profile, err := li.GetProfile(cmd.Context(), publicID)
+		resolver, err := newResolver(li)
 		if err != nil {
 			return err
 		}
-		if profile.MemberURN == "" {
-			return fmt.Errorf("could not determine member urn for %q", publicID)
+		target, err := resolver.Resolve(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if target.MemberURN == "" {
+			return fmt.Errorf("could not determine member urn for %q", args[0])
 		}
 
-		if err := li.Follow(cmd.Context(), profile.MemberURN); err != nil {
+		if err := li.Follow(cmd.Context(), target.MemberURN); err != nil {
 			return err
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Followed %s\n", publicID)
+		fmt.Fprintf(cmd.OutOrStdout(), "Followed %s\n", target.PublicIdentifier)
 		return nil
 	},
 }
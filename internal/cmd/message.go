@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/janitrai/bragcli/internal/api"
-	"github.com/janitrai/bragcli/internal/auth"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +15,10 @@ var messageCmd = &cobra.Command{
 	Short:   "Bragnet messaging",
 }
 
-var messageListLimit int
+var (
+	messageListLimit int
+	messageListPages pageFlags
+)
 
 var messageListCmd = &cobra.Command{
 	Use:   "list",
@@ -32,12 +34,18 @@ var messageListCmd = &cobra.Command{
 			return err
 		}
 
-		profileURN, err := resolveMyProfileURN(cmd, li)
+		resolver, err := newResolver(li)
+		if err != nil {
+			return err
+		}
+		profileURN, err := resolveMyProfileURN(cmd, resolver)
 		if err != nil {
 			return err
 		}
 
-		convos, err := li.ListConversations(cmd.Context(), profileURN, messageListLimit)
+		limit := pageLimit(cmd, cfg, messageListLimit)
+		pager := li.ConversationsPager(profileURN, limit)
+		convos, err := fetchPages(cmd.Context(), pager, messageListPages, limit)
 		if err != nil {
 			return err
 		}
@@ -47,6 +55,7 @@ var messageListCmd = &cobra.Command{
 			return nil
 		}
 
+		loc := cfg.Location()
 		for _, c := range convos {
 			// Build participant names (skip "Me" / self by checking profileURN).
 			var names []string
@@ -67,7 +76,7 @@ var messageListCmd = &cobra.Command{
 			who := strings.Join(names, ", ")
 
 			if c.LastMessage != nil {
-				ts := formatTimestamp(c.LastMessage.DeliveredAt)
+				ts := formatTimestamp(c.LastMessage.DeliveredAt, loc)
 				preview := truncate(c.LastMessage.BodyText, 80)
 				fmt.Fprintf(cmd.OutOrStdout(), "%s  %s\n  %s\n\n", who, ts, preview)
 			} else {
@@ -81,6 +90,7 @@ var messageListCmd = &cobra.Command{
 var messageReadCmd = &cobra.Command{
 	Use:   "read <username>",
 	Short: "Read messages in a conversation with a specific user",
+	Long:  "Read messages in a conversation with a specific user.\n\n" + identifierHelp,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, _, err := loadConfig()
@@ -93,21 +103,22 @@ var messageReadCmd = &cobra.Command{
 		}
 
 		// 1. Resolve my own profile URN.
-		myProfileURN, err := resolveMyProfileURN(cmd, li)
+		resolver, err := newResolver(li)
+		if err != nil {
+			return err
+		}
+		myProfileURN, err := resolveMyProfileURN(cmd, resolver)
 		if err != nil {
 			return err
 		}
 
 		// 2. Resolve target user's profile URN.
-		username := auth.NormalizePublicIdentifier(args[0])
-		targetProfile, err := li.GetProfile(cmd.Context(), username)
+		target, err := resolver.Resolve(cmd.Context(), args[0])
 		if err != nil {
-			return fmt.Errorf("resolve profile %q: %w", username, err)
-		}
-		targetURN := targetProfile.MiniProfileEntityURN
-		if targetURN == "" {
-			return fmt.Errorf("could not determine profile URN for %q", username)
+			return fmt.Errorf("resolve profile %q: %w", args[0], err)
 		}
+		username := target.PublicIdentifier
+		targetURN := target.ProfileURN
 
 		// 3. List conversations and find the one with the target.
 		convos, err := li.ListConversations(cmd.Context(), myProfileURN, 25)
@@ -131,19 +142,22 @@ var messageReadCmd = &cobra.Command{
 			return nil
 		}
 
-		targetName := strings.TrimSpace(targetProfile.FirstName + " " + targetProfile.LastName)
+		defer startPager(cmd, cfg)()
+
+		targetName := target.FullName()
 		if targetName == "" {
 			targetName = username
 		}
 		fmt.Fprintf(cmd.OutOrStdout(), "Conversation with %s\n%s\n\n",
 			targetName, strings.Repeat("─", 40))
 
+		loc := cfg.Location()
 		for _, msg := range msgs {
 			sender := msg.SenderName
 			if sender == "" {
 				sender = msg.SenderURN
 			}
-			ts := formatTimestamp(msg.DeliveredAt)
+			ts := formatTimestamp(msg.DeliveredAt, loc)
 			fmt.Fprintf(cmd.OutOrStdout(), "[%s] %s:\n%s\n\n", ts, sender, msg.BodyText)
 		}
 		return nil
@@ -153,7 +167,7 @@ var messageReadCmd = &cobra.Command{
 var messageSendCmd = &cobra.Command{
 	Use:   "send <username> <message>",
 	Short: "Send a message to a user (experimental)",
-	Long:  "Send a text message to a Bragnet user. Creates a new conversation if one doesn't exist.\nNote: this command is experimental and may not work with all Bragnet API versions.",
+	Long:  "Send a text message to a Bragnet user. Creates a new conversation if one doesn't exist.\nNote: this command is experimental and may not work with all Bragnet API versions.\n\n" + identifierHelp,
 	Args:  cobra.MinimumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, _, err := loadConfig()
@@ -166,21 +180,22 @@ var messageSendCmd = &cobra.Command{
 		}
 
 		// 1. Resolve my own profile URN.
-		myProfileURN, err := resolveMyProfileURN(cmd, li)
+		resolver, err := newResolver(li)
+		if err != nil {
+			return err
+		}
+		myProfileURN, err := resolveMyProfileURN(cmd, resolver)
 		if err != nil {
 			return err
 		}
 
 		// 2. Resolve target user.
-		username := auth.NormalizePublicIdentifier(args[0])
-		targetProfile, err := li.GetProfile(cmd.Context(), username)
+		target, err := resolver.Resolve(cmd.Context(), args[0])
 		if err != nil {
-			return fmt.Errorf("resolve profile %q: %w", username, err)
-		}
-		targetURN := targetProfile.MiniProfileEntityURN
-		if targetURN == "" {
-			return fmt.Errorf("could not determine profile URN for %q", username)
+			return fmt.Errorf("resolve profile %q: %w", args[0], err)
 		}
+		username := target.PublicIdentifier
+		targetURN := target.ProfileURN
 
 		text := strings.Join(args[1:], " ")
 
@@ -208,39 +223,23 @@ var messageSendCmd = &cobra.Command{
 	},
 }
 
-// resolveMyProfileURN fetches the current user's fsd_profile URN.
-// It first tries Me.ProfileURN (dashEntityUrn), then falls back to
-// fetching the full profile via the public identifier.
-func resolveMyProfileURN(cmd *cobra.Command, li *api.Bragnet) (string, error) {
-	me, err := li.GetMe(cmd.Context())
-	if err != nil {
-		return "", fmt.Errorf("get current user: %w", err)
-	}
-
-	if me.ProfileURN != "" {
-		return me.ProfileURN, nil
-	}
-
-	// Fallback: fetch full profile to get fsd_profile URN.
-	if me.PublicIdentifier == "" {
-		return "", fmt.Errorf("could not determine your profile URN (no publicIdentifier from /me)")
-	}
-	prof, err := li.GetProfile(cmd.Context(), me.PublicIdentifier)
+// resolveMyProfileURN returns the current user's fsd_profile URN.
+func resolveMyProfileURN(cmd *cobra.Command, resolver *api.Resolver) (string, error) {
+	me, err := resolver.Self(cmd.Context())
 	if err != nil {
-		return "", fmt.Errorf("get own profile: %w", err)
-	}
-	if prof.MiniProfileEntityURN == "" {
-		return "", fmt.Errorf("could not determine your fsd_profile URN")
+		return "", err
 	}
-	return prof.MiniProfileEntityURN, nil
+	return me.ProfileURN, nil
 }
 
-func formatTimestamp(ms int64) string {
+// formatTimestamp formats ms since the epoch in loc, more briefly the
+// more recent it is.
+func formatTimestamp(ms int64, loc *time.Location) string {
 	if ms <= 0 {
 		return ""
 	}
-	t := time.UnixMilli(ms)
-	now := time.Now()
+	t := time.UnixMilli(ms).In(loc)
+	now := time.Now().In(loc)
 	if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
 		return t.Format("15:04")
 	}
@@ -265,5 +264,6 @@ func init() {
 	messageCmd.AddCommand(messageReadCmd)
 	messageCmd.AddCommand(messageSendCmd)
 
-	messageListCmd.Flags().IntVar(&messageListLimit, "limit", 20, "Max conversations to show")
+	messageListCmd.Flags().IntVar(&messageListLimit, "limit", 20, "Conversations per page")
+	addPageFlags(messageListCmd, &messageListPages)
 }
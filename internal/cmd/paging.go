@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/janitrai/bragcli/internal/api"
+	"github.com/janitrai/bragcli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// pageFlags holds the --all/--page/--max flags shared by list commands.
+type pageFlags struct {
+	all  bool
+	page int
+	max  int
+}
+
+func addPageFlags(cmd *cobra.Command, pf *pageFlags) {
+	cmd.Flags().BoolVar(&pf.all, "all", false, "Fetch every page of results")
+	cmd.Flags().IntVar(&pf.page, "page", 1, "Page of results to start from (1-based)")
+	cmd.Flags().IntVar(&pf.max, "max", 0, "Stop after this many results in total, paging as needed")
+}
+
+// pageLimit returns limit, the value of cmd's --limit, unless the flag
+// wasn't given and cfg has a default_limit.
+func pageLimit(cmd *cobra.Command, cfg config.Config, limit int) int {
+	if !cmd.Flags().Changed("limit") && cfg.DefaultLimit > 0 {
+		return cfg.DefaultLimit
+	}
+	return limit
+}
+
+// fetchPages applies pf to p. Without --all or --max a single page is
+// returned, trimmed to limit.
+func fetchPages[T any](ctx context.Context, p *api.Pager[T], pf pageFlags, limit int) ([]T, error) {
+	if pf.page < 1 {
+		return nil, fmt.Errorf("--page must be at least 1")
+	}
+	if pf.max < 0 {
+		return nil, fmt.Errorf("--max must not be negative")
+	}
+	if err := p.Skip(ctx, pf.page-1); err != nil {
+		return nil, err
+	}
+	if !pf.all && pf.max == 0 {
+		items, err := p.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if limit > 0 && len(items) > limit {
+			items = items[:limit]
+		}
+		return items, nil
+	}
+	p.Max = pf.max
+	return p.All(ctx)
+}
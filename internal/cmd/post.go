@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,7 +17,9 @@ var postCmd = &cobra.Command{
 var postCreateCmd = &cobra.Command{
 	Use:   "create [text]",
 	Short: "Create a new post",
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Create a new post with the given text. Without text, the editor (config
+editor, else $VISUAL or $EDITOR) opens to write it; saving an empty file
+cancels the post.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, _, err := loadConfig()
 		if err != nil {
@@ -28,6 +31,14 @@ var postCreateCmd = &cobra.Command{
 		}
 
 		text := strings.Join(args, " ")
+		if len(args) == 0 {
+			if text, err = editText(cfg); err != nil {
+				return err
+			}
+			if text == "" {
+				return errors.New("empty post: nothing posted")
+			}
+		}
 		me, err := li.GetMe(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("get current user: %w", err)
@@ -46,7 +57,10 @@ var postCreateCmd = &cobra.Command{
 	},
 }
 
-var postListLimit int
+var (
+	postListLimit int
+	postListPages pageFlags
+)
 
 var postListCmd = &cobra.Command{
 	Use:   "list",
@@ -66,7 +80,9 @@ var postListCmd = &cobra.Command{
 			return err
 		}
 
-		updates, err := li.ListProfilePosts(cmd.Context(), me.MiniProfileEntityURN, 0, postListLimit)
+		limit := pageLimit(cmd, cfg, postListLimit)
+		pager := li.PostsPager(me.MiniProfileEntityURN, limit)
+		updates, err := fetchPages(cmd.Context(), pager, postListPages, limit)
 		if err != nil {
 			return err
 		}
@@ -76,6 +92,9 @@ var postListCmd = &cobra.Command{
 			if u.PublishedAt > 0 {
 				// Bragnet typically uses ms since epoch for these fields.
 				t := time.UnixMilli(u.PublishedAt).UTC()
+				if cfg.TimeZone != "" {
+					t = t.In(cfg.Location())
+				}
 				ts = t.Format(time.RFC3339)
 			}
 			line := u.Commentary
@@ -108,5 +127,6 @@ func init() {
 	postCmd.AddCommand(postCreateCmd)
 	postCmd.AddCommand(postListCmd)
 
-	postListCmd.Flags().IntVar(&postListLimit, "limit", 10, "Max posts to show")
+	postListCmd.Flags().IntVar(&postListLimit, "limit", 10, "Posts per page")
+	addPageFlags(postListCmd, &postListPages)
 }
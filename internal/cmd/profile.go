@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/janitrai/bragcli/internal/auth"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +15,7 @@ var profileCmd = &cobra.Command{
 var profileViewCmd = &cobra.Command{
 	Use:   "view [username]",
 	Short: "View a profile",
+	Long:  "View a profile; yours if no member is given.\n\n" + identifierHelp,
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, _, err := loadConfig()
@@ -27,28 +27,32 @@ var profileViewCmd = &cobra.Command{
 			return err
 		}
 
-		publicID := ""
+		resolver, err := newResolver(li)
+		if err != nil {
+			return err
+		}
+		who := ""
 		if len(args) == 0 {
 			me, err := li.GetMe(cmd.Context())
 			if err != nil {
 				return err
 			}
-			publicID = me.PublicIdentifier
+			who = me.PublicIdentifier
 		} else {
-			publicID = auth.NormalizePublicIdentifier(args[0])
+			who = args[0]
 		}
-		if strings.TrimSpace(publicID) == "" {
+		if strings.TrimSpace(who) == "" {
 			return fmt.Errorf("missing profile identifier")
 		}
 
-		p, err := li.GetProfile(cmd.Context(), publicID)
+		p, err := resolver.Profile(cmd.Context(), who)
 		if err != nil {
 			return err
 		}
 
 		name := strings.TrimSpace(p.FirstName + " " + p.LastName)
 		if name == "" {
-			name = publicID
+			name = who
 		}
 		fmt.Fprintf(cmd.OutOrStdout(), "Name: %s\n", name)
 		if p.Headline != "" {
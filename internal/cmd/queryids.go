@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/janitrai/bragcli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var queryIDsCmd = &cobra.Command{
+	Use:   "queryids",
+	Short: "Manage the GraphQL queryIds bragcli uses",
+}
+
+var queryIDsPage string
+
+var queryIDsRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Fetch current queryIds from Bragnet's web client and save them to the config",
+	Long: `Bragnet rotates the GraphQL queryIds behind search and messaging whenever
+it redeploys its web client, after which requests with the old ones fail
+with HTTP 400 or 500. refresh loads a page with your session, scans the
+JavaScript bundles it references for the current ids and saves them to the
+config, where they are tried before the built-in defaults.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		li, err := newBragnet(cfg)
+		if err != nil {
+			return err
+		}
+
+		found, err := li.DiscoverQueryIDs(cmd.Context(), queryIDsPage)
+		if err != nil {
+			return fmt.Errorf("discover queryIds: %w", err)
+		}
+
+		ids := make(map[string][]string)
+		for _, q := range api.KnownQueries {
+			if len(found[q]) == 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: no queryId found for %s\n", q)
+				continue
+			}
+			ids[string(q)] = found[q]
+			for _, id := range found[q] {
+				fmt.Fprintln(cmd.OutOrStdout(), id)
+			}
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("no known queryIds in the scripts of %s", queryIDsPage)
+		}
+
+		acct := cfg.Account()
+		if acct == nil {
+			return errNotLoggedIn
+		}
+		acct.QueryIDs = ids
+		acct.QueryIDsUpdatedAt = time.Now().UTC()
+		if err := saveConfig(path, cfg); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Saved queryIds for %d of %d operations to account %q in %s\n", len(ids), len(api.KnownQueries), cfg.Selected(), path)
+		return nil
+	},
+}
+
+func init() {
+	queryIDsCmd.AddCommand(queryIDsRefreshCmd)
+
+	queryIDsRefreshCmd.Flags().StringVar(&queryIDsPage, "page", api.DefaultDiscoveryPage, "Page whose scripts to scan for queryIds")
+}
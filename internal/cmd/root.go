@@ -5,8 +5,21 @@ import (
 )
 
 var (
-	cfgPath string
-	debug   bool
+	cfgPath     string
+	accountName string
+	debug       bool
+	recordDir   string
+	replayDir   string
+
+	harPath    string
+	harSecrets bool
+
+	strict bool
+
+	noCache      bool
+	refreshCache bool
+
+	cookiesFromStdin bool
 )
 
 var rootCmd = &cobra.Command{
@@ -15,13 +28,34 @@ var rootCmd = &cobra.Command{
 	Long:  `bragcli is a command-line interface for Bragnet, inspired by gh (GitHub CLI).`,
 }
 
-func Execute() error {
-	return rootCmd.Execute()
+// Execute runs the command line in os.Args and returns the process exit
+// status for it (see ExitCode). main passes it to os.Exit.
+func Execute() int {
+	return ExitCode(execute())
+}
+
+// execute runs the root command and prints a hint for any error.
+func execute() error {
+	err := rootCmd.Execute()
+	printSchemaDrift(rootCmd.ErrOrStderr())
+	if err != nil {
+		printHint(rootCmd.ErrOrStderr(), err)
+	}
+	return err
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&cfgPath, "config", "", "Path to config file (default: $XDG_CONFIG_HOME/li/config.json)")
+	rootCmd.PersistentFlags().StringVar(&cfgPath, "config", "", "Path to config file (default: $XDG_CONFIG_HOME/bragcli/config.json)")
+	rootCmd.PersistentFlags().StringVar(&accountName, "account", "", "Use the named account instead of the current one")
+	rootCmd.PersistentFlags().BoolVar(&cookiesFromStdin, "cookies-from-stdin", false, "Read the session (a Cookie header or cookies.txt) from stdin instead of the config; it is not saved")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug logging (prints HTTP method/url/status)")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Record HTTP traffic (cookies redacted) into a cassette directory")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Replay HTTP traffic from a cassette directory instead of the network")
+	rootCmd.PersistentFlags().StringVar(&harPath, "har", "", "Write an HTTP Archive (HAR) of all API traffic to FILE (cookies redacted)")
+	rootCmd.PersistentFlags().BoolVar(&harSecrets, "har-include-secrets", false, "Keep cookies and csrf-token in the --har file (do not share it)")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "Fail instead of warning when Bragnet responses are missing expected fields")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Neither read nor write the response cache")
+	rootCmd.PersistentFlags().BoolVar(&refreshCache, "refresh", false, "Ignore cached responses but store fresh ones")
 
 	// Add subcommands here
 	rootCmd.AddCommand(authCmd)
@@ -31,4 +65,7 @@ func init() {
 	rootCmd.AddCommand(connectCmd)
 	rootCmd.AddCommand(followCmd)
 	rootCmd.AddCommand(messageCmd)
+	rootCmd.AddCommand(queryIDsCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(configCmd)
 }
@@ -6,12 +6,15 @@ import (
 
 func TestRootCmd_HasExpectedSubcommands(t *testing.T) {
 	expected := map[string]bool{
-		"auth":    false,
-		"post":    false,
-		"profile": false,
-		"search":  false,
-		"connect": false,
-		"follow":  false,
+		"auth":     false,
+		"post":     false,
+		"profile":  false,
+		"search":   false,
+		"connect":  false,
+		"follow":   false,
+		"queryids": false,
+		"cache":    false,
+		"config":   false,
 	}
 
 	for _, sub := range rootCmd.Commands() {
@@ -29,7 +32,7 @@ func TestRootCmd_HasExpectedSubcommands(t *testing.T) {
 }
 
 func TestRootCmd_PersistentFlags(t *testing.T) {
-	flags := []string{"config", "debug"}
+	flags := []string{"config", "account", "debug", "record", "replay", "har", "har-include-secrets", "strict", "no-cache", "refresh"}
 	for _, name := range flags {
 		f := rootCmd.PersistentFlags().Lookup(name)
 		if f == nil {
@@ -12,7 +12,10 @@ var searchCmd = &cobra.Command{
 	Short: "Search Bragnet",
 }
 
-var searchLimit int
+var (
+	searchLimit int
+	searchPages pageFlags
+)
 
 var searchPeopleCmd = &cobra.Command{
 	Use:   "people [query]",
@@ -29,7 +32,7 @@ var searchPeopleCmd = &cobra.Command{
 		}
 
 		query := strings.Join(args, " ")
-		items, err := li.SearchPeople(cmd.Context(), query, 0, searchLimit)
+		items, err := fetchPages(cmd.Context(), li.SearchPeoplePager(query), searchPages, pageLimit(cmd, cfg, searchLimit))
 		if err != nil {
 			return err
 		}
@@ -69,7 +72,7 @@ var searchJobsCmd = &cobra.Command{
 		}
 
 		query := strings.Join(args, " ")
-		items, err := li.SearchJobs(cmd.Context(), query, 0, searchLimit)
+		items, err := fetchPages(cmd.Context(), li.SearchJobsPager(query), searchPages, pageLimit(cmd, cfg, searchLimit))
 		if err != nil {
 			return err
 		}
@@ -95,6 +98,8 @@ func init() {
 	searchCmd.AddCommand(searchPeopleCmd)
 	searchCmd.AddCommand(searchJobsCmd)
 
-	searchPeopleCmd.Flags().IntVar(&searchLimit, "limit", 10, "Max results to show")
-	searchJobsCmd.Flags().IntVar(&searchLimit, "limit", 10, "Max results to show")
+	searchPeopleCmd.Flags().IntVar(&searchLimit, "limit", 10, "Max results to show per page")
+	searchJobsCmd.Flags().IntVar(&searchLimit, "limit", 10, "Max results to show per page")
+	addPageFlags(searchPeopleCmd, &searchPages)
+	addPageFlags(searchJobsCmd, &searchPages)
 }
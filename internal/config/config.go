@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -13,36 +16,238 @@ const (
 	// EnvConfigPath overrides the default config path.
 	EnvConfigPath = "LI_CONFIG_PATH"
 
-	defaultDirName  = "li"
+	defaultDirName  = "bragcli"
 	defaultFileName = "config.json"
+	// legacyDirName is the config dir of releases before it was named
+	// after the binary.
+	legacyDirName = "li"
+
+	cacheDirName = "bragcli"
+	dataDirName  = "bragcli"
+
+	// DefaultAccount is the name given to the account of a config written
+	// before bragcli supported several.
+	DefaultAccount = "default"
 )
 
+// ErrUnknownAccount is returned by Select for a name with no saved account.
+var ErrUnknownAccount = errors.New("unknown account")
+
+// ErrInvalidAccountName is returned for an account name that
+// ValidateAccountName rejects.
+var ErrInvalidAccountName = errors.New("invalid account name")
+
 type Config struct {
-	Auth                 AuthConfig `json:"auth"`
-	SearchQueryID        string     `json:"search_query_id,omitempty"`
-	ConversationsQueryID string     `json:"conversations_query_id,omitempty"`
-	MessagesQueryID      string     `json:"messages_query_id,omitempty"`
+	// Version is the layout of the file (see CurrentVersion). Load
+	// upgrades older files; Save always writes the current version.
+	Version int `json:"version"`
+
+	// Auth is the session of the selected account (see Select). Save writes
+	// it back to that account; it is not stored at the top level.
+	Auth AuthConfig `json:"-"`
+
+	// Accounts holds each saved login by name. CurrentAccount is the one
+	// commands use when --account isn't given.
+	Accounts       map[string]*Account `json:"accounts,omitempty"`
+	CurrentAccount string              `json:"current_account,omitempty"`
+
+	SearchQueryID        string `json:"search_query_id,omitempty"`
+	ConversationsQueryID string `json:"conversations_query_id,omitempty"`
+	MessagesQueryID      string `json:"messages_query_id,omitempty"`
+
+	// RateLimits overrides the client-side request budget per endpoint
+	// class ("read", "search", "messaging", "invitation", "write").
+	RateLimits map[string]RateLimit `json:"rate_limits,omitempty"`
+
+	// MaxResponseMB caps the size of a decoded API response (default 32).
+	MaxResponseMB int `json:"max_response_mb,omitempty"`
+
+	// CacheTTLs overrides how long responses are cached per API path, as
+	// Go durations ("30m"); "0" turns caching off for that path.
+	CacheTTLs map[string]string `json:"cache_ttls,omitempty"`
+
+	// CredentialStore picks where session cookies are kept: "plaintext"
+	// (the default; in this file), "encrypted" or "helper". CredentialHelper
+	// names the external helper for "helper", as git's credential.helper.
+	CredentialStore  string `json:"credential_store,omitempty"`
+	CredentialHelper string `json:"credential_helper,omitempty"`
+
+	// OutputFormat is the default for commands with --json: "text" or
+	// "json". Editor and Pager are commands run through the shell (see
+	// Keys). TimeZone is an IANA zone name for printed times. DefaultLimit
+	// replaces the --limit default of list and search commands.
+	OutputFormat string `json:"output_format,omitempty"`
+	Editor       string `json:"editor,omitempty"`
+	Pager        string `json:"pager,omitempty"`
+	TimeZone     string `json:"time_zone,omitempty"`
+	DefaultLimit int    `json:"default_limit,omitempty"`
+
+	// selected names the account Auth belongs to, and selectedAuth is
+	// its session as of Select.
+	selected     string
+	selectedAuth AuthConfig
+}
+
+// Account is one named login.
+type Account struct {
+	Auth AuthConfig `json:"auth"`
+
+	// Domain is the site the session belongs to, e.g. "www.linkedin.com".
+	// Empty means auth.Domain().
+	Domain string `json:"domain,omitempty"`
+
+	// ChromeProfile is the Chrome user data dir kept by
+	// `auth login --keep-profile`, which `auth refresh` reuses.
+	ChromeProfile string `json:"chrome_profile,omitempty"`
+
+	// QueryIDs maps GraphQL operation names (e.g. "messengerMessages") to
+	// queryIds found by `bragcli queryids refresh`, newest first. They are
+	// tried after the single ids in Config and before the built-in
+	// defaults.
+	QueryIDs          map[string][]string `json:"query_ids,omitempty"`
+	QueryIDsUpdatedAt time.Time           `json:"query_ids_updated_at,omitempty"`
+}
+
+type RateLimit struct {
+	Burst     int     `json:"burst"`
+	PerMinute float64 `json:"per_minute"`
 }
 
 type AuthConfig struct {
-	LiAt       string    `json:"li_at"`
-	JSessionID string    `json:"jsessionid"`
-	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+	LiAt       string `json:"li_at"`
+	JSessionID string `json:"jsessionid"`
+
+	// UpdatedAt is when the session was saved. Save sets it when the
+	// cookies here change; for other credential stores the caller does.
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+
+	// ExpiresAt is when li_at expires, as set by the browser or the last
+	// rotation; zero when it isn't known (cookies pasted with --manual).
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	// UserAgent and AcceptLanguage are those of the browser the session
+	// was created in. Bragnet drops sessions whose requests come with
+	// another User-Agent, so these replace the built-in ones when set.
+	UserAgent      string `json:"user_agent,omitempty"`
+	AcceptLanguage string `json:"accept_language,omitempty"`
 }
 
 func (a AuthConfig) LoggedIn() bool {
 	return a.LiAt != "" && a.JSessionID != ""
 }
 
+// DefaultPath returns $LI_CONFIG_PATH, or config.json in the bragcli user
+// config dir ($XDG_CONFIG_HOME/bragcli on Linux). A config only found in
+// the "li" dir of older releases is read where it is; MoveLegacyDir moves
+// it.
 func DefaultPath() (string, error) {
 	if p := os.Getenv(EnvConfigPath); p != "" {
 		return p, nil
 	}
+	path, legacy, err := defaultPaths()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	return path, nil
+}
+
+// MoveLegacyDir moves the "li" config dir of older releases, with the
+// files kept beside the config, to the bragcli one. It leaves things alone
+// when $LI_CONFIG_PATH is set, when the old dir doesn't hold a bragcli
+// config, or when the new dir already exists: the old config then keeps
+// being read where it is rather than the two being half-merged.
+func MoveLegacyDir() error {
+	if os.Getenv(EnvConfigPath) != "" {
+		return nil
+	}
+	path, legacy, err := defaultPaths()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	b, err := os.ReadFile(legacy)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read old config: %w", err)
+	}
+	if !isConfig(b) {
+		return nil
+	}
+	if err := os.Rename(filepath.Dir(legacy), filepath.Dir(path)); err != nil {
+		return fmt.Errorf("move config dir: %w", err)
+	}
+	return nil
+}
+
+// defaultPaths returns the default config path and the one older releases
+// used.
+func defaultPaths() (path, legacy string, err error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
-		return "", fmt.Errorf("user config dir: %w", err)
+		return "", "", fmt.Errorf("user config dir: %w", err)
+	}
+	return filepath.Join(dir, defaultDirName, defaultFileName), filepath.Join(dir, legacyDirName, defaultFileName), nil
+}
+
+// isConfig reports whether b is a config file bragcli wrote: a JSON object
+// with a key only it uses. "li" is a common enough name for another tool's
+// dir.
+func isConfig(b []byte) bool {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return false
+	}
+	for _, k := range []string{"version", "accounts", "current_account", "auth"} {
+		if _, ok := m[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheDir returns the directory for cached data ($XDG_CACHE_HOME/bragcli
+// on Linux).
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("user cache dir: %w", err)
+	}
+	return filepath.Join(dir, cacheDirName), nil
+}
+
+// DataDir returns the directory for state worth keeping that isn't
+// config, such as browser profiles: $XDG_DATA_HOME/bragcli, by default
+// ~/.local/share/bragcli on Linux, ~/Library/Application Support/bragcli
+// on macOS and %LocalAppData%\bragcli on Windows.
+func DataDir() (string, error) {
+	if runtime.GOOS == "windows" {
+		dir := os.Getenv("LocalAppData")
+		if dir == "" {
+			return "", errors.New("user data dir: %LocalAppData% is not set")
+		}
+		return filepath.Join(dir, dataDirName), nil
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, dataDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("user data dir: %w", err)
+	}
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Application Support", dataDirName), nil
 	}
-	return filepath.Join(dir, defaultDirName, defaultFileName), nil
+	return filepath.Join(home, ".local", "share", dataDirName), nil
 }
 
 func Load(path string) (Config, error) {
@@ -62,13 +267,132 @@ func Load(path string) (Config, error) {
 		return Config{}, fmt.Errorf("read config: %w", err)
 	}
 
+	from, upgraded, err := migrate(b)
+	if err != nil {
+		return Config{}, err
+	}
+	data := b
+	if upgraded != nil {
+		data = upgraded
+	}
+
 	var cfg Config
-	if err := json.Unmarshal(b, &cfg); err != nil {
+	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, fmt.Errorf("parse config JSON: %w", err)
 	}
+	if upgraded != nil {
+		// Rewrite the file in the new layout, keeping the old one beside
+		// it. This is best-effort: the upgraded config is used either way,
+		// and the next Save writes it.
+		if err := writeBackup(path, from, b); err == nil {
+			_ = Save(path, cfg)
+		}
+	}
+	if _, ok := cfg.Accounts[cfg.CurrentAccount]; ok {
+		_ = cfg.Select(cfg.CurrentAccount)
+	}
 	return cfg, nil
 }
 
+// ---------------------------------------------------------------------------
+// Accounts
+// ---------------------------------------------------------------------------
+
+// Select makes name the account Auth and Account refer to, for this
+// process only; CurrentAccount is left alone. Changes to Auth are kept for
+// the account being switched away from.
+func (c *Config) Select(name string) error {
+	if err := ValidateAccountName(name); err != nil {
+		return err
+	}
+	a, ok := c.Accounts[name]
+	if !ok {
+		return fmt.Errorf("%w %q (have: %s)", ErrUnknownAccount, name, strings.Join(c.AccountNames(), ", "))
+	}
+	c.flushAuth()
+	c.selected = name
+	c.Auth = a.Auth
+	c.selectedAuth = a.Auth
+	return nil
+}
+
+// ValidateAccountName checks that name can name an account: letters,
+// digits, '.', '_' and '-', but not "." or "..". Names become parts of
+// file paths and of credential helper input.
+func ValidateAccountName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("%w %q", ErrInvalidAccountName, name)
+	}
+	for _, r := range name {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return fmt.Errorf("%w %q: use only letters, digits, '.', '_' and '-'", ErrInvalidAccountName, name)
+		}
+	}
+	return nil
+}
+
+// Selected returns the name of the selected account, or "".
+func (c Config) Selected() string {
+	return c.selected
+}
+
+// Account returns the selected account, or nil if none is.
+func (c Config) Account() *Account {
+	return c.Accounts[c.selected]
+}
+
+// AddAccount returns the account called name, creating an empty one if
+// needed. It does not select it.
+func (c *Config) AddAccount(name string) *Account {
+	if a, ok := c.Accounts[name]; ok {
+		return a
+	}
+	if c.Accounts == nil {
+		c.Accounts = make(map[string]*Account)
+	}
+	a := &Account{}
+	c.Accounts[name] = a
+	return a
+}
+
+// RemoveAccount deletes the account called name and reports whether it
+// existed. If it was the current account, the first remaining one (by
+// name) becomes current.
+func (c *Config) RemoveAccount(name string) bool {
+	if _, ok := c.Accounts[name]; !ok {
+		return false
+	}
+	delete(c.Accounts, name)
+	if c.selected == name {
+		c.selected = ""
+		c.Auth = AuthConfig{}
+	}
+	if c.CurrentAccount == name {
+		c.CurrentAccount = ""
+		if names := c.AccountNames(); len(names) > 0 {
+			c.CurrentAccount = names[0]
+		}
+	}
+	return true
+}
+
+// AccountNames returns the saved account names, sorted.
+func (c Config) AccountNames() []string {
+	names := make([]string, 0, len(c.Accounts))
+	for name := range c.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flushAuth copies Auth back into the selected account.
+func (c *Config) flushAuth() {
+	if a, ok := c.Accounts[c.selected]; ok {
+		a.Auth = c.Auth
+	}
+}
+
 func Save(path string, cfg Config) error {
 	if path == "" {
 		var err error
@@ -83,8 +407,27 @@ func Save(path string, cfg Config) error {
 		return fmt.Errorf("create config dir: %w", err)
 	}
 
-	cfg.Auth.UpdatedAt = time.Now().UTC()
+	if cfg.selected == "" && cfg.Auth != (AuthConfig{}) {
+		// A session set on a config with no account selected, such as
+		// a brand-new one, belongs to the current (or default) account.
+		name := cfg.CurrentAccount
+		if name == "" {
+			name = DefaultAccount
+		}
+		cfg.AddAccount(name)
+		cfg.selected = name
+	}
+	if cfg.selected != "" {
+		if cfg.Auth.LiAt != cfg.selectedAuth.LiAt || cfg.Auth.JSessionID != cfg.selectedAuth.JSessionID {
+			cfg.Auth.UpdatedAt = time.Now().UTC()
+		}
+		cfg.flushAuth()
+		if cfg.CurrentAccount == "" {
+			cfg.CurrentAccount = cfg.selected
+		}
+	}
 
+	cfg.Version = CurrentVersion
 	b, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal config: %w", err)
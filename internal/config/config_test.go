@@ -1,6 +1,9 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -171,6 +174,40 @@ func TestSave_SetsUpdatedAt(t *testing.T) {
 	}
 }
 
+func TestSave_KeepsUpdatedAtWithoutNewCookies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := Save(path, Config{Auth: AuthConfig{LiAt: "a", JSessionID: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := cfg.Auth.UpdatedAt
+
+	cfg.Pager = "less"
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Auth.UpdatedAt.Equal(saved) {
+		t.Errorf("UpdatedAt = %v after saving other settings, want %v", cfg.Auth.UpdatedAt, saved)
+	}
+
+	cfg.Auth.LiAt = "c"
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Auth.UpdatedAt.After(saved) {
+		t.Errorf("UpdatedAt = %v after new cookies, want later than %v", cfg.Auth.UpdatedAt, saved)
+	}
+}
+
 func TestDefaultPath_WithEnvVar(t *testing.T) {
 	customPath := "/tmp/li-test-custom/config.json"
 	t.Setenv(EnvConfigPath, customPath)
@@ -186,14 +223,16 @@ func TestDefaultPath_WithEnvVar(t *testing.T) {
 
 func TestDefaultPath_WithoutEnvVar(t *testing.T) {
 	t.Setenv(EnvConfigPath, "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
 
 	got, err := DefaultPath()
 	if err != nil {
 		t.Fatalf("DefaultPath() error: %v", err)
 	}
-	// Should end with /li/config.json (or equivalent)
-	if !strings.HasSuffix(got, filepath.Join("li", "config.json")) {
-		t.Errorf("DefaultPath() = %q, expected to end with li/config.json", got)
+	// Should end with /bragcli/config.json (or equivalent)
+	if !strings.HasSuffix(got, filepath.Join("bragcli", "config.json")) {
+		t.Errorf("DefaultPath() = %q, expected to end with bragcli/config.json", got)
 	}
 	// Should be an absolute path
 	if !filepath.IsAbs(got) {
@@ -201,6 +240,71 @@ func TestDefaultPath_WithoutEnvVar(t *testing.T) {
 	}
 }
 
+func TestMoveLegacyDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses $XDG_CONFIG_HOME")
+	}
+	dir := t.TempDir()
+	t.Setenv(EnvConfigPath, "")
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	legacy := filepath.Join(dir, "li")
+	if err := os.MkdirAll(legacy, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"config.json", "credentials.enc"} {
+		if err := os.WriteFile(filepath.Join(legacy, name), []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// DefaultPath only finds the old config.
+	if got, err := DefaultPath(); err != nil || got != filepath.Join(legacy, "config.json") {
+		t.Fatalf("DefaultPath() = %q, %v; want the old path", got, err)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Fatalf("DefaultPath moved the old dir: %v", err)
+	}
+
+	// Another tool's "li" dir is left alone.
+	if err := MoveLegacyDir(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Fatalf("old dir without a bragcli config was moved: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(legacy, "config.json"), []byte(`{"version":1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveLegacyDir(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DefaultPath(); err != nil || got != filepath.Join(dir, "bragcli", "config.json") {
+		t.Fatalf("DefaultPath() after the move = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bragcli", "credentials.enc")); err != nil {
+		t.Errorf("files beside the config were not moved: %v", err)
+	}
+	if _, err := os.Stat(legacy); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("old dir still there: %v", err)
+	}
+
+	// With the new dir already in use for something else, the old config
+	// is read where it is.
+	if err := os.Rename(filepath.Join(dir, "bragcli"), legacy); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "bragcli"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveLegacyDir(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DefaultPath(); err != nil || got != filepath.Join(legacy, "config.json") {
+		t.Errorf("DefaultPath() = %q, %v; want the old path", got, err)
+	}
+}
+
 func TestAuthConfig_LoggedIn(t *testing.T) {
 	tests := []struct {
 		name string
@@ -220,3 +324,197 @@ func TestAuthConfig_LoggedIn(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_MigratesSingleAccount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"auth":{"li_at":"tok","jsessionid":"sid"},"max_response_mb":8}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CurrentAccount != DefaultAccount || cfg.Selected() != DefaultAccount {
+		t.Fatalf("current = %q, selected = %q; want %q", cfg.CurrentAccount, cfg.Selected(), DefaultAccount)
+	}
+	if cfg.Auth.LiAt != "tok" || cfg.Account() == nil {
+		t.Errorf("migrated account = %+v, auth = %+v", cfg.Account(), cfg.Auth)
+	}
+	if cfg.MaxResponseMB != 8 {
+		t.Errorf("MaxResponseMB = %d", cfg.MaxResponseMB)
+	}
+
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(b, &top); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := top["auth"]; ok || top["accounts"] == nil {
+		t.Errorf("saved config still has the old layout:\n%s", b)
+	}
+	again, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Auth.LiAt != "tok" || len(again.Accounts) != 1 {
+		t.Errorf("reloaded = %+v", again)
+	}
+}
+
+func TestLoad_UpgradesOldVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"auth":{"li_at":"tok","jsessionid":"sid"},"editor":"vi"}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.LiAt != "tok" || cfg.Editor != "vi" {
+		t.Errorf("loaded = %+v", cfg)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil || string(backup) != data {
+		t.Errorf("backup = %q, %v; want the original file", backup, err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		Version  int                        `json:"version"`
+		Accounts map[string]json.RawMessage `json:"accounts"`
+	}
+	if err := json.Unmarshal(b, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != CurrentVersion || saved.Accounts[DefaultAccount] == nil {
+		t.Errorf("config was not rewritten at version %d:\n%s", CurrentVersion, b)
+	}
+
+	// A file at the current version is left as it is.
+	if err := os.Remove(path + ".v0.bak"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".v0.bak"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("current config was backed up again: %v", err)
+	}
+}
+
+func TestLoad_RejectsNewerVersion(t *testing.T) {
+	path := writeFile(t, fmt.Sprintf(`{"version":%d}`, CurrentVersion+1))
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "upgrade bragcli") {
+		t.Errorf("Load() error = %v, want one asking to upgrade", err)
+	}
+	if _, err := Load(writeFile(t, `{"version":"two"}`)); err == nil {
+		t.Error("Load() of a non-numeric version: no error")
+	}
+}
+
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := Config{Auth: AuthConfig{LiAt: "a", JSessionID: "a"}}
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.AddAccount("work").Domain = "www.example.com"
+	if err := cfg.Select("work"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Auth = AuthConfig{LiAt: "w", JSessionID: "w"}
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.AccountNames(); len(got) != 2 || got[0] != "default" || got[1] != "work" {
+		t.Fatalf("AccountNames = %v", got)
+	}
+	if cfg.Selected() != DefaultAccount || cfg.Auth.LiAt != "a" {
+		t.Errorf("selected %q with li_at %q, want the current account", cfg.Selected(), cfg.Auth.LiAt)
+	}
+	if err := cfg.Select("work"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.LiAt != "w" || cfg.Account().Domain != "www.example.com" {
+		t.Errorf("work account = %+v, auth = %+v", cfg.Account(), cfg.Auth)
+	}
+	if err := cfg.Select("nope"); !errors.Is(err, ErrUnknownAccount) {
+		t.Errorf("Select(nope) = %v", err)
+	}
+	if err := cfg.Select(".."); !errors.Is(err, ErrInvalidAccountName) {
+		t.Errorf("Select(..) = %v", err)
+	}
+
+	if !cfg.RemoveAccount(DefaultAccount) || cfg.RemoveAccount(DefaultAccount) {
+		t.Fatal("RemoveAccount should succeed once")
+	}
+	if cfg.CurrentAccount != "work" {
+		t.Errorf("CurrentAccount = %q after removing the current one", cfg.CurrentAccount)
+	}
+	if !cfg.RemoveAccount("work") || cfg.CurrentAccount != "" || cfg.Auth.LoggedIn() {
+		t.Errorf("after removing all: current %q, auth %+v", cfg.CurrentAccount, cfg.Auth)
+	}
+}
+
+func TestValidateAccountName(t *testing.T) {
+	for _, name := range []string{"default", "work-2", "a.b_c", "..x"} {
+		if err := ValidateAccountName(name); err != nil {
+			t.Errorf("ValidateAccountName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "a/../../x", `a\b`, "a\nb", "a b", "käse"} {
+		if err := ValidateAccountName(name); !errors.Is(err, ErrInvalidAccountName) {
+			t.Errorf("ValidateAccountName(%q) = %v, want ErrInvalidAccountName", name, err)
+		}
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses %LocalAppData%")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	got, err := DataDir()
+	if err != nil || got != filepath.Join(dir, "bragcli") {
+		t.Errorf("DataDir() = %q, %v; want it under $XDG_DATA_HOME", got, err)
+	}
+
+	t.Setenv("XDG_DATA_HOME", "relative/is/ignored")
+	t.Setenv("HOME", dir)
+	got, err = DataDir()
+	if err != nil || !strings.HasPrefix(got, dir) {
+		t.Errorf("DataDir() = %q, %v; want it under $HOME", got, err)
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownKey is returned for a setting name that isn't in Keys.
+var ErrUnknownKey = errors.New("unknown config key")
+
+// Output formats for OutputFormat.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Key is a setting that `bragcli config` can get, set and unset. Only
+// top-level scalar settings are keys; accounts and the rate limit and
+// cache TTL maps are edited by other commands or by hand.
+type Key struct {
+	Name string
+	Help string
+	// Default is shown when the key is unset; "" means there is none.
+	Default string
+	// Values lists the allowed values of an enumerated key.
+	Values []string
+
+	field    func(c *Config) any // *string or *int
+	validate func(v string) error
+	min      int
+}
+
+// Keys lists the settings `bragcli config` manages, in display order.
+var Keys = []Key{
+	{Name: "output_format", Help: "Output of commands that have --json", Default: OutputText, Values: []string{OutputText, OutputJSON},
+		field: func(c *Config) any { return &c.OutputFormat }},
+	{Name: "editor", Help: "Editor for composing posts (default: $VISUAL, then $EDITOR)",
+		field: func(c *Config) any { return &c.Editor }, validate: validateCommand},
+	{Name: "pager", Help: "Pager for long output such as message threads, e.g. \"less -FR\"",
+		field: func(c *Config) any { return &c.Pager }, validate: validateCommand},
+	{Name: "time_zone", Help: "IANA time zone for printed times, e.g. \"Europe/Berlin\" (default: the system's)",
+		field: func(c *Config) any { return &c.TimeZone }, validate: validateTimeZone},
+	{Name: "default_limit", Help: "Results per page of list and search commands when --limit isn't given",
+		field: func(c *Config) any { return &c.DefaultLimit }, min: 1},
+	{Name: "search_query_id", Help: "GraphQL queryId for search, tried before refreshed and built-in ones",
+		field: func(c *Config) any { return &c.SearchQueryID }, validate: queryIDFor("voyagerSearchDashClusters")},
+	{Name: "conversations_query_id", Help: "GraphQL queryId for listing conversations, tried before refreshed and built-in ones",
+		field: func(c *Config) any { return &c.ConversationsQueryID }, validate: queryIDFor("messengerConversations")},
+	{Name: "messages_query_id", Help: "GraphQL queryId for reading messages, tried before refreshed and built-in ones",
+		field: func(c *Config) any { return &c.MessagesQueryID }, validate: queryIDFor("messengerMessages")},
+	{Name: "max_response_mb", Help: "Largest API response to decode, in MB", Default: "32",
+		field: func(c *Config) any { return &c.MaxResponseMB }, min: 1},
+	{Name: "credential_store", Help: "Where session cookies are kept; config set moves saved sessions to the new store", Default: "plaintext",
+		Values: []string{"plaintext", "encrypted", "helper"},
+		field:  func(c *Config) any { return &c.CredentialStore }},
+	{Name: "credential_helper", Help: "git credential helper for the helper store, e.g. \"osxkeychain\"",
+		field: func(c *Config) any { return &c.CredentialHelper }},
+}
+
+// LookupKey returns the key called name.
+func LookupKey(name string) (Key, error) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+	return Key{}, fmt.Errorf("%w %q", ErrUnknownKey, name)
+}
+
+// Get returns the value of the setting name, or "" if it is unset.
+func (c *Config) Get(name string) (string, error) {
+	k, err := LookupKey(name)
+	if err != nil {
+		return "", err
+	}
+	switch p := k.field(c).(type) {
+	case *string:
+		return *p, nil
+	case *int:
+		if *p == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(*p), nil
+	}
+	panic("config: bad field for key " + name)
+}
+
+// Set validates value and stores it as the setting name.
+func (c *Config) Set(name, value string) error {
+	k, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+	if len(k.Values) > 0 && !slices.Contains(k.Values, value) {
+		return fmt.Errorf("%s: %q is not one of %s", name, value, strings.Join(k.Values, ", "))
+	}
+	if k.validate != nil {
+		if err := k.validate(value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	switch p := k.field(c).(type) {
+	case *string:
+		*p = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a whole number", name, value)
+		}
+		if n < k.min {
+			return fmt.Errorf("%s: must be at least %d", name, k.min)
+		}
+		*p = n
+	}
+	return nil
+}
+
+// Unset clears the setting name, so its default applies.
+func (c *Config) Unset(name string) error {
+	k, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+	switch p := k.field(c).(type) {
+	case *string:
+		*p = ""
+	case *int:
+		*p = 0
+	}
+	return nil
+}
+
+// Location returns the time zone to print times in: TimeZone, or the
+// system's if it is unset or invalid.
+func (c Config) Location() *time.Location {
+	if c.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func validateCommand(v string) error {
+	if strings.TrimSpace(v) == "" {
+		return errors.New("empty command; use config unset to clear it")
+	}
+	return nil
+}
+
+func validateTimeZone(v string) error {
+	if v == "" {
+		return errors.New("empty time zone")
+	}
+	_, err := time.LoadLocation(v)
+	return err
+}
+
+// queryIDFor checks that a queryId is for the GraphQL operation op: op, a
+// dot and a hex hash.
+func queryIDFor(op string) func(string) error {
+	return func(v string) error {
+		hash, ok := strings.CutPrefix(v, op+".")
+		if !ok || hash == "" || strings.Trim(hash, "0123456789abcdef") != "" {
+			return fmt.Errorf("%q is not a queryId for %s (want %s.<hex hash>)", v, op, op)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeys_SetGetUnset(t *testing.T) {
+	tests := []struct {
+		key, value string
+		wantErr    bool
+	}{
+		{"output_format", "json", false},
+		{"output_format", "yaml", true},
+		{"editor", "vim", false},
+		{"editor", "  ", true},
+		{"pager", "less -FR", false},
+		{"pager", "\t", true},
+		{"time_zone", "Europe/Berlin", false},
+		{"time_zone", "Mars/Olympus_Mons", true},
+		{"default_limit", "25", false},
+		{"default_limit", "0", true},
+		{"default_limit", "ten", true},
+		{"search_query_id", "voyagerSearchDashClusters.0123456789abcdef0123456789abcdef", false},
+		{"search_query_id", "messengerMessages.0123456789abcdef0123456789abcdef", true},
+		{"messages_query_id", "messengerMessages.", true},
+		{"credential_store", "encrypted", false},
+		{"credential_store", "keychain", true},
+	}
+	for _, tt := range tests {
+		var c Config
+		err := c.Set(tt.key, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%s, %q) = %v, want error %v", tt.key, tt.value, err, tt.wantErr)
+			continue
+		}
+		got, _ := c.Get(tt.key)
+		if want := map[bool]string{false: tt.value, true: ""}[tt.wantErr]; got != want {
+			t.Errorf("Get(%s) after Set(%q) = %q, want %q", tt.key, tt.value, got, want)
+		}
+		if err := c.Unset(tt.key); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := c.Get(tt.key); got != "" {
+			t.Errorf("Get(%s) after Unset = %q", tt.key, got)
+		}
+	}
+
+	var c Config
+	for _, err := range []error{c.Set("nope", "1"), c.Unset("nope")} {
+		if !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("unknown key: err = %v, want ErrUnknownKey", err)
+		}
+	}
+	if _, err := c.Get("nope"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Get(nope): err = %v", err)
+	}
+}
+
+func TestConfig_Location(t *testing.T) {
+	c := Config{TimeZone: "Asia/Tokyo"}
+	if got := c.Location().String(); got != "Asia/Tokyo" {
+		t.Errorf("Location() = %s", got)
+	}
+	c.TimeZone = ""
+	if c.Location() != time.Local {
+		t.Error("unset time zone: want time.Local")
+	}
+}
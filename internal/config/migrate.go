@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// A migration upgrades a config file from one version to the next. It
+// works on the decoded JSON object rather than on Config, so it still sees
+// the fields the old layout had. Files written before versioning are
+// version 0 and may already have some of the later layout, so the early
+// steps must leave an upgraded file alone.
+type migration struct {
+	name  string
+	apply func(m map[string]any) error
+}
+
+// migrations[i] upgrades version i to i+1. Append new steps; never edit or
+// reorder released ones.
+var migrations = []migration{
+	{"move the single session into the default account", migrateSingleAccount},
+}
+
+// CurrentVersion is the config version this build reads and writes.
+var CurrentVersion = len(migrations)
+
+// migrate upgrades the config file contents b to CurrentVersion. It
+// returns the version b was at and, if that is older, the upgraded JSON.
+func migrate(b []byte) (from int, out []byte, err error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return 0, nil, fmt.Errorf("parse config JSON: %w", err)
+	}
+	if v, ok := m["version"]; ok {
+		n, ok := v.(json.Number)
+		i, err := strconv.Atoi(n.String())
+		if !ok || err != nil || i < 0 {
+			return 0, nil, fmt.Errorf("config version %v is not a version number", v)
+		}
+		from = i
+	}
+	if from > CurrentVersion {
+		return from, nil, fmt.Errorf("config is version %d, but this bragcli only knows up to %d; upgrade bragcli", from, CurrentVersion)
+	}
+	if from == CurrentVersion {
+		return from, nil, nil
+	}
+	for v := from; v < CurrentVersion; v++ {
+		if err := migrations[v].apply(m); err != nil {
+			return from, nil, fmt.Errorf("migrate config to version %d (%s): %w", v+1, migrations[v].name, err)
+		}
+	}
+	m["version"] = CurrentVersion
+	out, err = json.Marshal(m)
+	return from, out, err
+}
+
+// backupPath is where Load keeps a copy of a config file it upgraded from
+// version v.
+func backupPath(path string, v int) string {
+	return fmt.Sprintf("%s.v%d.bak", path, v)
+}
+
+// writeBackup copies the original contents b of path aside before it is
+// rewritten. An existing backup is kept: it is the older of the two.
+func writeBackup(path string, v int, b []byte) error {
+	f, err := os.OpenFile(backupPath(path, v), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// migrateSingleAccount moves the top-level "auth" of a config from before
+// named accounts into DefaultAccount.
+func migrateSingleAccount(m map[string]any) error {
+	auth := m["auth"]
+	delete(m, "auth")
+	if accts, _ := m["accounts"].(map[string]any); len(accts) > 0 {
+		return nil
+	}
+
+	var old AuthConfig
+	if err := remarshal(auth, &old); err != nil {
+		return err
+	}
+	if old == (AuthConfig{}) {
+		return nil
+	}
+	m["accounts"] = map[string]any{DefaultAccount: map[string]any{"auth": auth}}
+	m["current_account"] = DefaultAccount
+	return nil
+}
+
+// remarshal converts v, decoded JSON, into out.
+func remarshal(v any, out any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,121 @@
+package fakebragnet
+
+// Person is a member known to the fake server.
+type Person struct {
+	PublicID  string
+	ProfileID string // the ACoAA… id used in fs_miniProfile / fsd_profile URNs
+	MemberID  string // numeric id used in urn:li:member
+	FirstName string
+	LastName  string
+	Headline  string
+	Summary   string
+	Location  string
+}
+
+func (p *Person) ProfileURN() string     { return "urn:li:fsd_profile:" + p.ProfileID }
+func (p *Person) MiniProfileURN() string { return "urn:li:fs_miniProfile:" + p.ProfileID }
+func (p *Person) MemberURN() string      { return "urn:li:member:" + p.MemberID }
+
+// Job is a job search result.
+type Job struct {
+	ID       string
+	Title    string
+	Company  string
+	Location string
+}
+
+// Post is a feed update authored by the logged-in member.
+type Post struct {
+	ActivityID  string
+	Text        string
+	PublishedAt int64 // ms since epoch
+}
+
+// Conversation is a messaging thread between Me and Others.
+type Conversation struct {
+	ThreadID string
+	Others   []*Person
+	Messages []*Message
+}
+
+// Message is one message in a conversation.
+type Message struct {
+	ID          string
+	From        *Person
+	Text        string
+	DeliveredAt int64 // ms since epoch
+}
+
+// Invitation is a connection request received by the fake server.
+type Invitation struct {
+	InviteeProfileURN string
+	Note              string
+}
+
+// baseTime is 2025-01-01T00:00:00Z in ms; seeded timestamps count from it.
+const baseTime int64 = 1735689600000
+
+const hour int64 = 60 * 60 * 1000
+
+// seed fills s with a small, stable data set.
+func (s *Server) seed() {
+	// The ids bragcli ships with as defaults.
+	s.QueryIDs = map[string]string{
+		"voyagerSearchDashClusters": "voyagerSearchDashClusters.ef3d0937fb65bd7812e32e5a85028e79",
+		"messengerConversations":    "messengerConversations.9501074288a12f3ae9e3c7ea243bccbf",
+		"messengerMessages":         "messengerMessages.5846eeb71c981f11e0134cb6626cc314",
+	}
+
+	s.Me = &Person{
+		PublicID: "john-doe", ProfileID: "ACoAAJOHN001", MemberID: "10001",
+		FirstName: "John", LastName: "Doe",
+		Headline: "Software Engineer at ACME Corp", Location: "Berlin, Germany",
+		Summary: "Builds things.",
+	}
+	jane := &Person{
+		PublicID: "jane-smith", ProfileID: "ACoAAJANE002", MemberID: "10002",
+		FirstName: "Jane", LastName: "Smith",
+		Headline: "VP of Engineering", Location: "San Francisco Bay Area",
+		Summary: "Building great teams.",
+	}
+	bob := &Person{
+		PublicID: "bob-williams", ProfileID: "ACoAABOB0003", MemberID: "10003",
+		FirstName: "Bob", LastName: "Williams",
+		Headline: "Backend Developer", Location: "New York, NY",
+	}
+	alice := &Person{
+		PublicID: "alice-johnson", ProfileID: "ACoAAALICE04", MemberID: "10004",
+		FirstName: "Alice", LastName: "Johnson",
+		Headline: "Software Engineer at Google", Location: "London, UK",
+	}
+	s.People = []*Person{s.Me, jane, bob, alice}
+
+	s.Jobs = []*Job{
+		{ID: "4000000001", Title: "Senior Go Engineer", Company: "ACME Corp", Location: "Remote"},
+		{ID: "4000000002", Title: "Platform Engineer", Company: "Globex", Location: "Berlin, Germany"},
+	}
+
+	s.Posts = []*Post{
+		{ActivityID: "7000000000000000003", Text: "Shipped the new release today.", PublishedAt: baseTime + 3*hour},
+		{ActivityID: "7000000000000000002", Text: "Great conference today!", PublishedAt: baseTime + 2*hour},
+		{ActivityID: "7000000000000000001", Text: "Excited to share my latest project!", PublishedAt: baseTime + hour},
+	}
+
+	s.Conversations = []*Conversation{
+		{
+			ThreadID: "2-thread-jane",
+			Others:   []*Person{jane},
+			Messages: []*Message{
+				{ID: "msg-1", From: jane, Text: "Hey John, how are you?", DeliveredAt: baseTime + 10*hour},
+				{ID: "msg-2", From: s.Me, Text: "Doing well, thanks!", DeliveredAt: baseTime + 11*hour},
+			},
+		},
+		{
+			ThreadID: "2-thread-bob",
+			Others:   []*Person{bob},
+			Messages: []*Message{
+				{ID: "msg-3", From: bob, Text: "Let's catch up soon!", DeliveredAt: baseTime + 5*hour},
+			},
+		},
+	}
+}
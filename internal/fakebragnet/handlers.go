@@ -0,0 +1,555 @@
+package fakebragnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/restli"
+)
+
+// Entity $types, as the real API spells them.
+const (
+	typeMiniProfile  = "com.linkedin.voyager.identity.shared.MiniProfile"
+	typeProfile      = "com.linkedin.voyager.dash.identity.profile.Profile"
+	typeEntityResult = "com.linkedin.voyager.dash.search.EntityResultViewModel"
+	typeCluster      = "com.linkedin.voyager.dash.search.SearchClusterViewModel"
+	typeUpdate       = "com.linkedin.voyager.feed.render.UpdateV2"
+	typeParticipant  = "com.linkedin.messenger.MessagingParticipant"
+	typeMessage      = "com.linkedin.messenger.Message"
+	typeConversation = "com.linkedin.messenger.Conversation"
+)
+
+// searchPageSize matches the real search endpoint, which ignores count.
+const searchPageSize = 10
+
+type obj = map[string]any
+
+func normalized(data obj, included ...obj) obj {
+	if data == nil {
+		data = obj{}
+	}
+	if included == nil {
+		included = []obj{}
+	}
+	return obj{"data": data, "included": included}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(obj{"status": status, "message": msg})
+}
+
+// ---------------------------------------------------------------------------
+// Identity
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) any {
+	me := s.Me
+	return normalized(
+		obj{"*miniProfile": me.MiniProfileURN(), "plainId": me.MemberID},
+		obj{
+			"$type":            typeMiniProfile,
+			"entityUrn":        me.MiniProfileURN(),
+			"dashEntityUrn":    me.ProfileURN(),
+			"objectUrn":        me.MemberURN(),
+			"publicIdentifier": me.PublicID,
+			"firstName":        me.FirstName,
+			"lastName":         me.LastName,
+			"occupation":       me.Headline,
+		},
+	)
+}
+
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) any {
+	q := r.URL.Query()
+	if q.Get("q") != "memberIdentity" {
+		writeError(w, http.StatusBadRequest, "unsupported finder")
+		return nil
+	}
+	// Like the real finder, this takes a public identifier or profile id,
+	// not a URN or numeric member id.
+	var p *Person
+	for _, c := range s.People {
+		if id := q.Get("memberIdentity"); id == c.PublicID || id == c.ProfileID {
+			p = c
+		}
+	}
+	if p == nil {
+		writeError(w, http.StatusNotFound, "profile not found")
+		return nil
+	}
+	return normalized(nil,
+		obj{
+			"$type":            typeProfile,
+			"entityUrn":        p.ProfileURN(),
+			"objectUrn":        p.MemberURN(),
+			"publicIdentifier": p.PublicID,
+			"firstName":        p.FirstName,
+			"lastName":         p.LastName,
+			"headline":         p.Headline,
+			"summary":          p.Summary,
+			"geoLocationName":  p.Location,
+		},
+		obj{"$type": "com.linkedin.voyager.common.Industry", "entityUrn": "urn:li:fs_industry:96", "name": "Information Technology"},
+	)
+}
+
+// ---------------------------------------------------------------------------
+// Search
+// ---------------------------------------------------------------------------
+
+// rawVariables decodes the Rest.li "variables" parameter of a GraphQL
+// call. It reads the raw query, since url.Values would unescape the values
+// inside the tuple along with the tuple itself.
+func rawVariables(r *http.Request, into any) error {
+	for _, kv := range strings.Split(r.URL.RawQuery, "&") {
+		if v, ok := strings.CutPrefix(kv, "variables="); ok {
+			return restli.Unmarshal(v, into)
+		}
+	}
+	return fmt.Errorf("missing variables")
+}
+
+type searchVariables struct {
+	Start int `restli:"start"`
+	Query struct {
+		Keywords        string `restli:"keywords"`
+		QueryParameters []struct {
+			Key   string   `restli:"key"`
+			Value []string `restli:"value"`
+		} `restli:"queryParameters"`
+	} `restli:"query"`
+}
+
+func (v *searchVariables) resultType() string {
+	for _, p := range v.Query.QueryParameters {
+		if p.Key == "resultType" && len(p.Value) > 0 {
+			return p.Value[0]
+		}
+	}
+	return ""
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) any {
+	q := r.URL.Query()
+	if !s.acceptsQueryID("voyagerSearchDashClusters", q.Get("queryId")) {
+		// Unknown or rotated queryIds fail the way the real API does.
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil
+	}
+	var vars searchVariables
+	if err := rawVariables(r, &vars); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	}
+	start := vars.Start
+	keywords := strings.ToLower(vars.Query.Keywords)
+
+	var results []obj
+	switch vars.resultType() {
+	case "PEOPLE":
+		for _, p := range s.People {
+			if p == s.Me || !matches(keywords, p.FirstName, p.LastName, p.Headline, p.PublicID) {
+				continue
+			}
+			results = append(results, obj{
+				"$type":             typeEntityResult,
+				"entityUrn":         p.ProfileURN(),
+				"title":             obj{"text": p.FirstName + " " + p.LastName},
+				"primarySubtitle":   obj{"text": p.Headline},
+				"secondarySubtitle": obj{"text": p.Location},
+				"navigationUrl":     auth.BaseURL() + "/in/" + p.PublicID,
+			})
+		}
+	case "JOBS":
+		for _, j := range s.Jobs {
+			if !matches(keywords, j.Title, j.Company) {
+				continue
+			}
+			results = append(results, obj{
+				"$type":             typeEntityResult,
+				"entityUrn":         "urn:li:fsd_jobPosting:" + j.ID,
+				"title":             obj{"text": j.Title},
+				"primarySubtitle":   obj{"text": j.Company},
+				"secondarySubtitle": obj{"text": j.Location},
+				"navigationUrl":     auth.BaseURL() + "/jobs/view/" + j.ID,
+			})
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported resultType")
+		return nil
+	}
+
+	page := pageOf(results, start, searchPageSize)
+	page = append(page, obj{"$type": typeCluster, "entityUrn": "urn:li:fsd_searchCluster:1", "title": "Results"})
+	return normalized(obj{"searchDashClustersByAll": obj{"paging": obj{"start": start, "count": searchPageSize, "total": len(results)}}}, page...)
+}
+
+// matches reports whether every keyword appears in one of fields.
+func matches(keywords string, fields ...string) bool {
+	hay := strings.ToLower(strings.Join(fields, " "))
+	for _, kw := range strings.Fields(keywords) {
+		if !strings.Contains(hay, kw) {
+			return false
+		}
+	}
+	return true
+}
+
+func pageOf[T any](items []T, start, count int) []T {
+	if start >= len(items) {
+		return nil
+	}
+	end := start + count
+	if end > len(items) {
+		end = len(items)
+	}
+	return append([]T(nil), items[start:end]...)
+}
+
+// ---------------------------------------------------------------------------
+// Feed
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleUpdates(w http.ResponseWriter, r *http.Request) any {
+	q := r.URL.Query()
+	if q.Get("q") != "memberShareFeed" {
+		writeError(w, http.StatusBadRequest, "unsupported finder")
+		return nil
+	}
+	owner := s.Person(q.Get("profileUrn"))
+	if owner == nil {
+		writeError(w, http.StatusNotFound, "profile not found")
+		return nil
+	}
+	start, _ := strconv.Atoi(q.Get("start"))
+	count, _ := strconv.Atoi(q.Get("count"))
+	if count <= 0 {
+		count = 10
+	}
+
+	var updates []obj
+	if owner == s.Me {
+		for _, p := range s.Posts {
+			updates = append(updates, obj{
+				"$type":       typeUpdate,
+				"entityUrn":   fmt.Sprintf("urn:li:fs_update:(urn:li:activity:%s,MEMBER_SHARE,EMPTY,DEFAULT,false)", p.ActivityID),
+				"updateType":  "MEMBER_SHARE",
+				"actor":       obj{"entityUrn": s.Me.MiniProfileURN()},
+				"publishedAt": p.PublishedAt,
+				"commentary":  obj{"text": obj{"text": p.Text}},
+			})
+		}
+	}
+	return normalized(obj{"paging": obj{"start": start, "count": count}}, pageOf(updates, start, count)...)
+}
+
+func (s *Server) handleCreatePost(w http.ResponseWriter, r *http.Request) any {
+	var payload struct {
+		CommentaryV2 struct {
+			Text string `json:"text"`
+		} `json:"commentaryV2"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.CommentaryV2.Text == "" {
+		writeError(w, http.StatusUnprocessableEntity, "missing commentary")
+		return nil
+	}
+	post := &Post{ActivityID: "70000000000000" + s.newID(), Text: payload.CommentaryV2.Text}
+	if len(s.Posts) > 0 {
+		post.PublishedAt = s.Posts[0].PublishedAt + hour
+	} else {
+		post.PublishedAt = baseTime
+	}
+	s.Posts = append([]*Post{post}, s.Posts...)
+	return obj{"data": obj{"entityUrn": "urn:li:share:" + post.ActivityID, "status": obj{"urn": "urn:li:activity:" + post.ActivityID}}}
+}
+
+// ---------------------------------------------------------------------------
+// Relationships
+// ---------------------------------------------------------------------------
+
+func (s *Server) handleFollow(w http.ResponseWriter, r *http.Request) any {
+	if r.URL.Query().Get("action") != "followByEntityUrn" {
+		writeError(w, http.StatusBadRequest, "unsupported action")
+		return nil
+	}
+	var payload struct {
+		URN string `json:"urn"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || !strings.HasPrefix(payload.URN, "urn:li:fs_followingInfo:") {
+		writeError(w, http.StatusUnprocessableEntity, "invalid urn")
+		return nil
+	}
+	s.Follows = append(s.Follows, payload.URN)
+	return obj{}
+}
+
+func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) any {
+	if r.URL.Query().Get("action") != "verifyQuotaAndCreate" {
+		writeError(w, http.StatusBadRequest, "unsupported action")
+		return nil
+	}
+	var payload struct {
+		InviteeProfileURN string `json:"inviteeProfileUrn"`
+		CustomMessage     string `json:"customMessage"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid payload")
+		return nil
+	}
+	if s.Person(payload.InviteeProfileURN) == nil {
+		writeError(w, http.StatusNotFound, "invitee not found")
+		return nil
+	}
+	s.Invitations = append(s.Invitations, Invitation{InviteeProfileURN: payload.InviteeProfileURN, Note: payload.CustomMessage})
+	return obj{"value": obj{"invitationUrn": "urn:li:fsd_invitation:" + s.newID()}}
+}
+
+// ---------------------------------------------------------------------------
+// Messaging
+// ---------------------------------------------------------------------------
+
+func (s *Server) conversationURN(c *Conversation) string {
+	return fmt.Sprintf("urn:li:msg_conversation:(%s,%s)", s.Me.ProfileURN(), c.ThreadID)
+}
+
+func (s *Server) participantURN(p *Person) string {
+	return fmt.Sprintf("urn:li:msg_participant:(%s,%s)", s.Me.ProfileURN(), p.ProfileURN())
+}
+
+func (s *Server) messageURN(m *Message) string {
+	return fmt.Sprintf("urn:li:msg_message:(%s,%s)", s.Me.ProfileURN(), m.ID)
+}
+
+func (s *Server) participantEntity(p *Person) obj {
+	return obj{
+		"$type":           typeParticipant,
+		"entityUrn":       s.participantURN(p),
+		"hostIdentityUrn": p.ProfileURN(),
+		"participantType": obj{"member": obj{
+			"firstName":  obj{"text": p.FirstName},
+			"lastName":   obj{"text": p.LastName},
+			"profileUrl": auth.BaseURL() + "/in/" + p.PublicID,
+		}},
+	}
+}
+
+func (s *Server) messageEntity(m *Message) obj {
+	return obj{
+		"$type":       typeMessage,
+		"entityUrn":   s.messageURN(m),
+		"body":        obj{"text": m.Text},
+		"*sender":     s.participantURN(m.From),
+		"deliveredAt": m.DeliveredAt,
+	}
+}
+
+func lastDelivered(c *Conversation) int64 {
+	if len(c.Messages) == 0 {
+		return 0
+	}
+	return c.Messages[len(c.Messages)-1].DeliveredAt
+}
+
+type conversationsVariables struct {
+	Count      int    `restli:"count"`
+	MailboxURN string `restli:"mailboxUrn"`
+	NextCursor string `restli:"nextCursor"`
+}
+
+type messagesVariables struct {
+	ConversationURN string `restli:"conversationUrn"`
+}
+
+func (s *Server) handleMessagingGraphQL(w http.ResponseWriter, r *http.Request) any {
+	queryID := r.URL.Query().Get("queryId")
+	switch {
+	case s.acceptsQueryID("messengerConversations", queryID):
+		var vars conversationsVariables
+		if err := rawVariables(r, &vars); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return nil
+		}
+		return s.conversations(w, vars)
+	case s.acceptsQueryID("messengerMessages", queryID):
+		var vars messagesVariables
+		if err := rawVariables(r, &vars); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return nil
+		}
+		return s.messages(w, vars)
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+	return nil
+}
+
+func (s *Server) conversations(w http.ResponseWriter, vars conversationsVariables) any {
+	if vars.MailboxURN != s.Me.ProfileURN() {
+		writeError(w, http.StatusForbidden, "mailbox does not belong to member")
+		return nil
+	}
+	count := vars.Count
+	if count <= 0 {
+		count = 20
+	}
+	start := 0
+	if c := vars.NextCursor; c != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(c, "cursor-"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad cursor")
+			return nil
+		}
+		start = n
+	}
+
+	convos := append([]*Conversation(nil), s.Conversations...)
+	sort.SliceStable(convos, func(i, j int) bool { return lastDelivered(convos[i]) > lastDelivered(convos[j]) })
+	page := pageOf(convos, start, count)
+
+	var included []obj
+	seen := map[*Person]bool{}
+	addParticipant := func(p *Person) {
+		if !seen[p] {
+			seen[p] = true
+			included = append(included, s.participantEntity(p))
+		}
+	}
+	var elements []string
+	for _, c := range page {
+		parts := []string{s.participantURN(s.Me)}
+		addParticipant(s.Me)
+		for _, p := range c.Others {
+			addParticipant(p)
+			parts = append(parts, s.participantURN(p))
+		}
+		entity := obj{
+			"$type":                     typeConversation,
+			"entityUrn":                 s.conversationURN(c),
+			"*conversationParticipants": parts,
+		}
+		if n := len(c.Messages); n > 0 {
+			last := c.Messages[n-1]
+			included = append(included, s.messageEntity(last))
+			entity["*lastMessage"] = s.messageURN(last)
+		}
+		included = append(included, entity)
+		elements = append(elements, s.conversationURN(c))
+	}
+
+	next := ""
+	if start+len(page) < len(convos) {
+		next = fmt.Sprintf("cursor-%d", start+len(page))
+	}
+	return normalized(obj{"messengerConversationsByCategoryQuery": obj{
+		"*elements": elements,
+		"metadata":  obj{"nextCursor": next},
+	}}, included...)
+}
+
+func (s *Server) conversationByURN(urn string) *Conversation {
+	for _, c := range s.Conversations {
+		if s.conversationURN(c) == urn {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) messages(w http.ResponseWriter, vars messagesVariables) any {
+	if vars.ConversationURN == "" {
+		writeError(w, http.StatusBadRequest, "missing conversationUrn")
+		return nil
+	}
+	c := s.conversationByURN(vars.ConversationURN)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "conversation not found")
+		return nil
+	}
+
+	included := []obj{s.participantEntity(s.Me)}
+	for _, p := range c.Others {
+		included = append(included, s.participantEntity(p))
+	}
+	var elements []string
+	for _, msg := range c.Messages {
+		included = append(included, s.messageEntity(msg))
+		elements = append(elements, s.messageURN(msg))
+	}
+	return normalized(obj{"messengerMessagesByConversation": obj{"*elements": elements}}, included...)
+}
+
+func (s *Server) handleCreateMessage(w http.ResponseWriter, r *http.Request) any {
+	if r.URL.Query().Get("action") != "createMessage" {
+		writeError(w, http.StatusBadRequest, "unsupported action")
+		return nil
+	}
+	// The web client posts JSON as text/plain to skip CORS preflight.
+	b, _ := io.ReadAll(r.Body)
+	var payload struct {
+		Message struct {
+			Body struct {
+				Text string `json:"text"`
+			} `json:"body"`
+			ConversationURN string `json:"conversationUrn"`
+		} `json:"message"`
+		MailboxURN        string   `json:"mailboxUrn"`
+		HostRecipientURNs []string `json:"hostRecipientUrns"`
+		TrackingID        string   `json:"trackingId"`
+	}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid payload")
+		return nil
+	}
+	if payload.TrackingID == "" || payload.Message.Body.Text == "" {
+		writeError(w, http.StatusBadRequest, "missing trackingId or body")
+		return nil
+	}
+	if payload.MailboxURN != s.Me.ProfileURN() {
+		writeError(w, http.StatusForbidden, "mailbox does not belong to member")
+		return nil
+	}
+
+	var c *Conversation
+	switch {
+	case payload.Message.ConversationURN != "":
+		if c = s.conversationByURN(payload.Message.ConversationURN); c == nil {
+			writeError(w, http.StatusNotFound, "conversation not found")
+			return nil
+		}
+	case len(payload.HostRecipientURNs) > 0:
+		c = &Conversation{ThreadID: "2-thread-" + s.newID()}
+		for _, urn := range payload.HostRecipientURNs {
+			p := s.Person(urn)
+			if p == nil {
+				writeError(w, http.StatusNotFound, "recipient not found")
+				return nil
+			}
+			c.Others = append(c.Others, p)
+		}
+		s.Conversations = append(s.Conversations, c)
+	default:
+		writeError(w, http.StatusBadRequest, "no conversationUrn or hostRecipientUrns")
+		return nil
+	}
+
+	var at int64 = baseTime
+	for _, cc := range s.Conversations {
+		if t := lastDelivered(cc); t >= at {
+			at = t + 1000
+		}
+	}
+	msg := &Message{ID: "msg-" + s.newID(), From: s.Me, Text: payload.Message.Body.Text, DeliveredAt: at}
+	c.Messages = append(c.Messages, msg)
+	return obj{"value": obj{
+		"entityUrn":       s.messageURN(msg),
+		"conversationUrn": s.conversationURN(c),
+		"deliveredAt":     msg.DeliveredAt,
+	}}
+}
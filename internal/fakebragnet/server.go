@@ -0,0 +1,328 @@
+// Package fakebragnet is an in-memory stand-in for the Voyager API, for
+// end-to-end tests of the api client and the cobra commands. It serves the
+// endpoints bragcli uses with normalized ({data, included}) payloads shaped
+// like the real ones, keeps state (posts, follows, invitations, messages)
+// between requests, and can inject failures: throttling, expired sessions,
+// login challenges and schema drift.
+//
+// Point an api.Client at it with api.WithBaseURL(srv.BaseURL()) and the
+// cookies from srv.Cookies().
+package fakebragnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// LiAt and JSessionID are the session cookies the server accepts.
+	LiAt       = "fake-li-at"
+	JSessionID = "ajax:fake-csrf"
+
+	apiPrefix = "/voyager/api"
+)
+
+// Failure is a fault the server can inject instead of a normal response.
+type Failure int
+
+const (
+	// FailRateLimit answers 429 with Retry-After.
+	FailRateLimit Failure = iota + 1
+	// FailSessionExpired answers 401 and deletes li_at, like an expired
+	// or revoked session.
+	FailSessionExpired
+	// FailChallenge redirects to a /checkpoint/ security challenge.
+	FailChallenge
+	// FailServerError answers 500, which is what stale queryIds produce.
+	FailServerError
+	// FailSchemaDrift serves the normal payload with renamed fields.
+	FailSchemaDrift
+)
+
+type injection struct {
+	failure Failure
+	path    string
+	left    int // remaining hits; <= 0 means until cleared
+}
+
+// Server is a fake Bragnet. Its exported fields are the data set; tests may
+// edit them directly before (or between) requests, under no lock, as long
+// as no request is in flight.
+type Server struct {
+	*httptest.Server
+
+	Me            *Person
+	People        []*Person
+	Jobs          []*Job
+	Posts         []*Post
+	Conversations []*Conversation
+
+	// Follows and Invitations record write requests, in order.
+	Follows     []string // urn:li:fs_followingInfo:… URNs
+	Invitations []Invitation
+
+	// QueryIDs maps GraphQL operation names to the one queryId currently
+	// accepted for each; requests with any other id get a 500. Changing an
+	// entry simulates Bragnet rotating it. The web client bundles served
+	// for discovery advertise these ids.
+	QueryIDs map[string]string
+
+	// RetryAfter is sent with injected 429s (default one hour, so clients
+	// give up instead of sleeping).
+	RetryAfter time.Duration
+
+	mu         sync.Mutex
+	liAt       string
+	rotateTo   string
+	injections []*injection
+	requests   []string
+	nextID     int
+}
+
+// New starts a fake server seeded with a small data set: the logged-in
+// member john-doe, a few other people, jobs, posts and two conversations.
+// It is closed when the test ends.
+func New(t testing.TB) *Server {
+	s := &Server{liAt: LiAt, RetryAfter: time.Hour, nextID: 100}
+	s.seed()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// BaseURL is the Voyager API root to pass to api.WithBaseURL.
+func (s *Server) BaseURL() string { return s.URL + apiPrefix }
+
+// Cookies returns the li_at and JSESSIONID values currently accepted.
+func (s *Server) Cookies() (liAt, jsessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.liAt, JSessionID
+}
+
+// Inject makes the next n requests whose path contains path fail with f.
+// An empty path matches every request; n <= 0 keeps failing until
+// ClearFailures.
+func (s *Server) Inject(f Failure, path string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injections = append(s.injections, &injection{failure: f, path: path, left: n})
+}
+
+// ClearFailures removes all injected failures.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injections = nil
+}
+
+// RotateSession makes the next response set a new li_at via Set-Cookie.
+// From then on only the new value is accepted.
+func (s *Server) RotateSession(newLiAt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotateTo = newLiAt
+}
+
+// Requests returns "METHOD /path" for every request received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Person returns the person with the given public identifier, profile id
+// or any of their URNs.
+func (s *Server) Person(id string) *Person {
+	for _, p := range s.People {
+		switch id {
+		case p.PublicID, p.ProfileID, p.MemberID, p.ProfileURN(), p.MiniProfileURN(), p.MemberURN():
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+// ---------------------------------------------------------------------------
+// Dispatch
+// ---------------------------------------------------------------------------
+
+type route struct {
+	method  string
+	path    string
+	handler func(s *Server, w http.ResponseWriter, r *http.Request) any
+}
+
+var routes = []route{
+	{http.MethodGet, "/me", (*Server).handleMe},
+	{http.MethodGet, "/identity/dash/profiles", (*Server).handleProfile},
+	{http.MethodGet, "/graphql", (*Server).handleSearch},
+	{http.MethodGet, "/feed/dash/updates", (*Server).handleUpdates},
+	{http.MethodPost, "/contentcreation/normShares", (*Server).handleCreatePost},
+	{http.MethodPost, "/feed/dash/follows", (*Server).handleFollow},
+	{http.MethodPost, "/voyagerRelationshipsDashMemberRelationships", (*Server).handleConnect},
+	{http.MethodGet, "/voyagerMessagingGraphQL/graphql", (*Server).handleMessagingGraphQL},
+	{http.MethodPost, "/voyagerMessagingDashMessengerMessages", (*Server).handleCreateMessage},
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if strings.HasPrefix(r.URL.Path, "/checkpoint/") {
+		w.Header().Set("content-type", "text/html")
+		fmt.Fprint(w, "<html><body>Let's do a quick security check</body></html>")
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, apiPrefix+"/") {
+		s.serveWeb(w, r)
+		return
+	}
+
+	drift := false
+	if inj := s.takeInjection(r.URL.Path); inj != nil {
+		switch inj.failure {
+		case FailRateLimit:
+			w.Header().Set("retry-after", strconv.Itoa(int(s.RetryAfter/time.Second)))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		case FailSessionExpired:
+			http.SetCookie(w, &http.Cookie{Name: "li_at", Value: "delete me", Expires: time.Unix(0, 0)})
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case FailChallenge:
+			http.Redirect(w, r, "/checkpoint/challenge/fake", http.StatusFound)
+			return
+		case FailServerError:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		case FailSchemaDrift:
+			drift = true
+		}
+	}
+
+	if status, msg := s.checkAuth(r); status != 0 {
+		w.WriteHeader(status)
+		fmt.Fprint(w, msg)
+		return
+	}
+	if s.rotateTo != "" {
+		s.liAt, s.rotateTo = s.rotateTo, ""
+		http.SetCookie(w, &http.Cookie{Name: "li_at", Value: s.liAt, Path: "/"})
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	for _, rt := range routes {
+		if path != rt.path {
+			continue
+		}
+		if r.Method != rt.method {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body := rt.handler(s, w, r)
+		if body == nil {
+			return // handler wrote an error status
+		}
+		if drift {
+			body = driftFields(body)
+		}
+		w.Header().Set("content-type", "application/vnd.linkedin.normalized+json+2.1")
+		_ = json.NewEncoder(w).Encode(body)
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (s *Server) takeInjection(path string) *injection {
+	for i, inj := range s.injections {
+		if inj.path != "" && !strings.Contains(path, inj.path) {
+			continue
+		}
+		if inj.left > 0 {
+			inj.left--
+			if inj.left == 0 {
+				s.injections = append(s.injections[:i], s.injections[i+1:]...)
+			}
+		}
+		return inj
+	}
+	return nil
+}
+
+// checkAuth mirrors how Voyager rejects requests: 401 without a valid
+// li_at, 403 "CSRF check failed" when csrf-token doesn't match JSESSIONID.
+func (s *Server) checkAuth(r *http.Request) (int, string) {
+	var liAt, jsession string
+	for _, c := range r.Cookies() {
+		switch c.Name {
+		case "li_at":
+			liAt = c.Value
+		case "JSESSIONID":
+			jsession = strings.Trim(c.Value, `"`)
+		}
+	}
+	if liAt != s.liAt {
+		return http.StatusUnauthorized, ""
+	}
+	if jsession != JSessionID || r.Header.Get("csrf-token") != JSessionID {
+		return http.StatusForbidden, "CSRF check failed."
+	}
+	return 0, ""
+}
+
+// ---------------------------------------------------------------------------
+// Schema drift
+// ---------------------------------------------------------------------------
+
+// driftedNames are the renames FailSchemaDrift applies to every entity, the
+// kind of change Bragnet ships without notice.
+var driftedNames = map[string]string{
+	"publicIdentifier": "vanityName",
+	"firstName":        "givenName",
+	"lastName":         "familyName",
+	"title":            "titleV2",
+	"body":             "messageBody",
+	"commentary":       "commentaryV2",
+}
+
+func driftFields(v any) any {
+	// Round-trip through JSON so handlers can return any shape.
+	b, _ := json.Marshal(v)
+	var generic any
+	_ = json.Unmarshal(b, &generic)
+	return renameKeys(generic)
+}
+
+func renameKeys(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, vv := range t {
+			if nk, ok := driftedNames[k]; ok {
+				k = nk
+			}
+			out[k] = renameKeys(vv)
+		}
+		return out
+	case []any:
+		for i := range t {
+			t[i] = renameKeys(t[i])
+		}
+	}
+	return v
+}
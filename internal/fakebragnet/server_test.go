@@ -0,0 +1,169 @@
+package fakebragnet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/janitrai/bragcli/internal/api"
+	"github.com/janitrai/bragcli/internal/auth"
+	"github.com/janitrai/bragcli/internal/fakebragnet"
+)
+
+func newBragnet(t *testing.T, srv *fakebragnet.Server) *api.Bragnet {
+	t.Helper()
+	liAt, jsession := srv.Cookies()
+	c, err := api.NewClient(auth.Cookies{LiAt: liAt, JSessionID: jsession},
+		api.WithBaseURL(srv.BaseURL()), api.WithRetryPolicy(api.NoRetry))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return api.NewBragnet(c)
+}
+
+func TestServer_ReadEndpoints(t *testing.T) {
+	srv := fakebragnet.New(t)
+	bn := newBragnet(t, srv)
+	ctx := context.Background()
+
+	me, err := bn.GetMe(ctx)
+	if err != nil {
+		t.Fatalf("GetMe: %v", err)
+	}
+	if me.PublicIdentifier != "john-doe" || me.ProfileURN != srv.Me.ProfileURN() {
+		t.Fatalf("me = %+v", me)
+	}
+
+	prof, err := bn.GetProfile(ctx, "jane-smith")
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if prof.Headline != "VP of Engineering" || prof.LocationName != "San Francisco Bay Area" {
+		t.Fatalf("profile = %+v", prof)
+	}
+
+	people, err := bn.SearchPeople(ctx, "engineer", 0, 10)
+	if err != nil {
+		t.Fatalf("SearchPeople: %v", err)
+	}
+	if len(people) != 2 || people[0].PublicIdentifier != "jane-smith" {
+		t.Fatalf("people = %+v", people)
+	}
+
+	posts, err := bn.ListProfilePosts(ctx, me.MiniProfileEntityURN, 0, 2)
+	if err != nil {
+		t.Fatalf("ListProfilePosts: %v", err)
+	}
+	if len(posts) != 2 || posts[0].Commentary != "Shipped the new release today." {
+		t.Fatalf("posts = %+v", posts)
+	}
+
+	convos, err := bn.ListConversations(ctx, me.ProfileURN, 20)
+	if err != nil {
+		t.Fatalf("ListConversations: %v", err)
+	}
+	if len(convos) != 2 || convos[0].LastMessage == nil || convos[0].LastMessage.BodyText != "Doing well, thanks!" {
+		t.Fatalf("convos = %+v", convos)
+	}
+
+	msgs, err := bn.GetMessages(ctx, convos[0].EntityURN, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].SenderName != "Jane Smith" {
+		t.Fatalf("msgs = %+v", msgs)
+	}
+}
+
+func TestServer_ConversationCursor(t *testing.T) {
+	srv := fakebragnet.New(t)
+	bn := newBragnet(t, srv)
+
+	got, err := bn.ConversationsPager(srv.Me.ProfileURN(), 1).All(context.Background())
+	if err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d conversations over two pages, want 2", len(got))
+	}
+}
+
+func TestServer_WritesChangeState(t *testing.T) {
+	srv := fakebragnet.New(t)
+	bn := newBragnet(t, srv)
+	ctx := context.Background()
+	me := srv.Me
+
+	if _, err := bn.CreatePost(ctx, me.MemberURN(), "Hello fake world"); err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	if srv.Posts[0].Text != "Hello fake world" {
+		t.Fatalf("newest post = %+v", srv.Posts[0])
+	}
+
+	bob := srv.Person("bob-williams")
+	if err := bn.Connect(ctx, bob.ProfileURN(), "hi"); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if len(srv.Invitations) != 1 || srv.Invitations[0].Note != "hi" {
+		t.Fatalf("invitations = %+v", srv.Invitations)
+	}
+
+	alice := srv.Person("alice-johnson")
+	if err := bn.CreateConversationWithMessage(ctx, me.ProfileURN(), []string{alice.ProfileURN()}, "New thread"); err != nil {
+		t.Fatalf("CreateConversationWithMessage: %v", err)
+	}
+	convos, err := bn.ListConversations(ctx, me.ProfileURN(), 20)
+	if err != nil {
+		t.Fatalf("ListConversations: %v", err)
+	}
+	if len(convos) != 3 || convos[0].LastMessage.BodyText != "New thread" {
+		t.Fatalf("newest conversation = %+v", convos[0])
+	}
+}
+
+func TestServer_InjectedFailures(t *testing.T) {
+	tests := []struct {
+		failure fakebragnet.Failure
+		want    error
+	}{
+		{fakebragnet.FailRateLimit, api.ErrRateLimited},
+		{fakebragnet.FailSessionExpired, api.ErrSessionExpired},
+		{fakebragnet.FailChallenge, api.ErrChallenge},
+	}
+	for _, tt := range tests {
+		srv := fakebragnet.New(t)
+		bn := newBragnet(t, srv)
+		srv.Inject(tt.failure, "/me", 1)
+
+		if _, err := bn.GetMe(context.Background()); !errors.Is(err, tt.want) {
+			t.Errorf("failure %d: err = %v, want %v", tt.failure, err, tt.want)
+		}
+		// The injection was for one request only.
+		if _, err := bn.GetMe(context.Background()); err != nil {
+			t.Errorf("failure %d: second GetMe: %v", tt.failure, err)
+		}
+	}
+}
+
+func TestServer_SchemaDrift(t *testing.T) {
+	srv := fakebragnet.New(t)
+	bn := newBragnet(t, srv)
+	srv.Inject(fakebragnet.FailSchemaDrift, "/me", 0)
+
+	_, err := bn.GetMe(context.Background())
+	var missing *api.MissingFieldsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("err = %v, want *MissingFieldsError", err)
+	}
+}
+
+func TestServer_RejectsBadCookies(t *testing.T) {
+	srv := fakebragnet.New(t)
+	c, _ := api.NewClient(auth.Cookies{LiAt: fakebragnet.LiAt, JSessionID: "ajax:wrong"},
+		api.WithBaseURL(srv.BaseURL()), api.WithRetryPolicy(api.NoRetry))
+
+	if _, err := api.NewBragnet(c).GetMe(context.Background()); !errors.Is(err, api.ErrCSRFMismatch) {
+		t.Fatalf("err = %v, want ErrCSRFMismatch", err)
+	}
+}
@@ -0,0 +1,69 @@
+package fakebragnet
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FeedPage is the web page the fake serves for queryId discovery. Its
+// scripts are split like the real web client's: a runtime bundle with no
+// queryIds, the search bundle, and the messaging bundle loaded as a module
+// preload.
+const FeedPage = "/feed/"
+
+const bundleDir = "/static/js/"
+
+func (s *Server) acceptsQueryID(name, id string) bool {
+	return id != "" && s.QueryIDs[name] == id
+}
+
+// serveWeb serves the non-API part of the site: the feed page, its
+// JavaScript bundles and the login page signed-out visitors are sent to.
+func (s *Server) serveWeb(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == FeedPage:
+		if c, err := r.Cookie("li_at"); err != nil || c.Value != s.liAt {
+			http.Redirect(w, r, "/login?session_redirect=%2Ffeed%2F", http.StatusSeeOther)
+			return
+		}
+		w.Header().Set("content-type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<!DOCTYPE html>
+<html><head>
+<link rel="modulepreload" href="%[1]smessaging.js">
+<script src="%[1]sruntime.js?v=1&amp;lang=en" defer></script>
+</head><body>
+<script src="%[1]ssearch.js" defer></script>
+<script src="%[1]sruntime.js?v=1&amp;lang=en" defer></script>
+</body></html>
+`, bundleDir)
+	case r.URL.Path == "/login":
+		w.Header().Set("content-type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><body>Sign in</body></html>")
+	case strings.HasPrefix(r.URL.Path, bundleDir):
+		js, ok := s.bundle(strings.TrimPrefix(r.URL.Path, bundleDir))
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("content-type", "application/javascript")
+		fmt.Fprint(w, js)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *Server) bundle(name string) (string, bool) {
+	switch name {
+	case "runtime.js":
+		return `(()=>{"use strict";var e={};e.p="/static/js/";})();`, true
+	case "search.js":
+		return fmt.Sprintf(`define("search/routes",["exports"],function(e){e.searchClusters={queryId:%q,`+
+			`type:"GET"};e.typeahead={queryId:"voyagerSearchDashTypeahead.0123456789abcdef0123456789abcdef"}});`,
+			s.QueryIDs["voyagerSearchDashClusters"]), true
+	case "messaging.js":
+		return fmt.Sprintf(`const c={conversations:"%s",messages:"%s"};export{c as default};`,
+			s.QueryIDs["messengerConversations"], s.QueryIDs["messengerMessages"]), true
+	}
+	return "", false
+}
@@ -0,0 +1,273 @@
+package restli
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// Unmarshal parses Rest.li 2.0 syntax in its escaped (raw query) form and
+// stores the result in v, which must be a non-nil pointer. Decoding into
+// an interface yields map[string]any for records, []any for lists and
+// string for scalars; typed targets convert scalars with strconv. Record
+// keys with no matching struct field are ignored.
+func Unmarshal(s string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("restli: Unmarshal needs a non-nil pointer, got %T", v)
+	}
+	p := &parser{s: s}
+	val, err := p.value()
+	if err != nil {
+		return err
+	}
+	if p.pos != len(p.s) {
+		return p.errorf("unexpected %q after value", p.s[p.pos])
+	}
+	return assign(rv.Elem(), val)
+}
+
+// Unescape reverses Escape for a single scalar.
+func Unescape(s string) (string, error) {
+	if s == "''" {
+		return "", nil
+	}
+	return url.PathUnescape(s)
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("restli: offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) expect(c byte) error {
+	if p.peek() != c {
+		if p.pos >= len(p.s) {
+			return p.errorf("expected %q, got end of input", c)
+		}
+		return p.errorf("expected %q, got %q", c, p.s[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// value parses a record, list or scalar into map[string]any, []any or
+// string.
+func (p *parser) value() (any, error) {
+	switch {
+	case p.peek() == '(':
+		return p.record()
+	case len(p.s)-p.pos >= 5 && p.s[p.pos:p.pos+5] == "List(":
+		p.pos += 4
+		return p.list()
+	}
+	return p.scalar()
+}
+
+func (p *parser) record() (map[string]any, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if p.peek() == ')' {
+		p.pos++
+		return out, nil
+	}
+	for {
+		key, err := p.scalar()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		val, err := p.value()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		out[key] = val
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return out, nil
+		default:
+			return nil, p.errorf("unterminated record")
+		}
+	}
+}
+
+func (p *parser) list() ([]any, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	out := []any{}
+	if p.peek() == ')' {
+		p.pos++
+		return out, nil
+	}
+	for {
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, val)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return out, nil
+		default:
+			return nil, p.errorf("unterminated list")
+		}
+	}
+}
+
+func (p *parser) scalar() (string, error) {
+	start := p.pos
+	for p.pos < len(p.s) && !isDelim(p.s[p.pos]) {
+		p.pos++
+	}
+	raw := p.s[start:p.pos]
+	if raw == "" {
+		return "", p.errorf("expected a value")
+	}
+	s, err := Unescape(raw)
+	if err != nil {
+		return "", p.errorf("bad escape in %q", raw)
+	}
+	return s, nil
+}
+
+func isDelim(c byte) bool {
+	return c == '(' || c == ')' || c == ',' || c == ':'
+}
+
+// assign stores a parsed value (map[string]any, []any or string) in dst.
+func assign(dst reflect.Value, val any) error {
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(dst.Elem(), val)
+	}
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		dst.Set(reflect.ValueOf(val))
+		return nil
+	}
+
+	switch v := val.(type) {
+	case map[string]any:
+		return assignRecord(dst, v)
+	case []any:
+		return assignList(dst, v)
+	case string:
+		return assignScalar(dst, v)
+	}
+	return fmt.Errorf("restli: unexpected value %T", val)
+}
+
+func assignRecord(dst reflect.Value, rec map[string]any) error {
+	switch dst.Kind() {
+	case reflect.Struct:
+		for _, f := range structFields(dst.Type()) {
+			val, ok := rec[f.name]
+			if !ok {
+				continue
+			}
+			if err := assign(dst.Field(f.index), val); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		if dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("restli: map key must be a string, got %s", dst.Type().Key())
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for k, val := range rec {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(elem, val); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			dst.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), elem)
+		}
+		return nil
+	}
+	return fmt.Errorf("restli: cannot decode record into %s", dst.Type())
+}
+
+func assignList(dst reflect.Value, list []any) error {
+	switch dst.Kind() {
+	case reflect.Slice:
+		out := reflect.MakeSlice(dst.Type(), len(list), len(list))
+		for i, val := range list {
+			if err := assign(out.Index(i), val); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+		return nil
+	case reflect.Array:
+		if len(list) != dst.Len() {
+			return fmt.Errorf("restli: list has %d items, array %s needs %d", len(list), dst.Type(), dst.Len())
+		}
+		for i, val := range list {
+			if err := assign(dst.Index(i), val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("restli: cannot decode list into %s", dst.Type())
+}
+
+func assignScalar(dst reflect.Value, s string) error {
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("restli: %q is not a bool", s)
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("restli: %q is not an integer", s)
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("restli: %q is not an unsigned integer", s)
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("restli: %q is not a number", s)
+		}
+		dst.SetFloat(f)
+	default:
+		return fmt.Errorf("restli: cannot decode scalar into %s", dst.Type())
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// Package restli encodes and decodes Rest.li 2.0 protocol syntax, the
+// tuple format Voyager uses for GraphQL variables and complex query
+// parameters:
+//
+//	(start:0,query:(keywords:go%20developer,filters:List(PEOPLE,JOBS)))
+//
+// Records are (key:value,…), lists are List(…), and every scalar is a
+// string. Structural characters stay literal; inside keys and values
+// anything outside the URL unreserved set is percent-encoded, so the
+// output can go straight into a raw query string with no further
+// escaping (and must not be escaped again).
+//
+// Go values map as follows: structs (fields tagged `restli:"name"`, with
+// ",omitempty") and map[string]T become records, slices and arrays become
+// lists, and strings, bools and numbers become scalars. Struct fields are
+// written in declaration order, map keys in sorted order.
+package restli
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Marshal encodes v in Rest.li 2.0 syntax, escaped for a URL query.
+func Marshal(v any) (string, error) {
+	var b strings.Builder
+	if err := encode(&b, reflect.ValueOf(v)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Escape percent-encodes a single scalar (for example a URN) so it can be
+// embedded in hand-written tuple syntax.
+func Escape(s string) string {
+	if s == "" {
+		return "''"
+	}
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xF])
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func encode(b *strings.Builder, v reflect.Value) error {
+	if !v.IsValid() {
+		return fmt.Errorf("restli: cannot encode nil")
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("restli: cannot encode nil %s", v.Type())
+		}
+		return encode(b, v.Elem())
+	case reflect.String:
+		b.WriteString(Escape(v.String()))
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(Escape(strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())))
+	case reflect.Slice, reflect.Array:
+		b.WriteString("List(")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := encode(b, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(')')
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("restli: map key must be a string, got %s", v.Type().Key())
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		b.WriteByte('(')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(Escape(k.String()))
+			b.WriteByte(':')
+			if err := encode(b, v.MapIndex(k)); err != nil {
+				return fmt.Errorf("%s: %w", k.String(), err)
+			}
+		}
+		b.WriteByte(')')
+	case reflect.Struct:
+		b.WriteByte('(')
+		first := true
+		for _, f := range structFields(v.Type()) {
+			fv := v.Field(f.index)
+			if f.omitEmpty && fv.IsZero() {
+				continue
+			}
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			b.WriteString(Escape(f.name))
+			b.WriteByte(':')
+			if err := encode(b, fv); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+		b.WriteByte(')')
+	default:
+		return fmt.Errorf("restli: cannot encode %s", v.Type())
+	}
+	return nil
+}
+
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+func structFields(t reflect.Type) []field {
+	var out []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("restli")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		out = append(out, field{name: name, index: i, omitEmpty: opts == "omitempty"})
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+package restli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			"simple fsd_profile URN",
+			"urn:li:fsd_profile:ACoAAGQ0PMsBs6zsDe1dkmea",
+			"urn%3Ali%3Afsd_profile%3AACoAAGQ0PMsBs6zsDe1dkmea",
+		},
+		{
+			"conversation URN with nested parens and comma",
+			"urn:li:msg_conversation:(urn:li:fsd_profile:AAA,2-MjkzM)",
+			"urn%3Ali%3Amsg_conversation%3A%28urn%3Ali%3Afsd_profile%3AAAA%2C2-MjkzM%29",
+		},
+		{"empty string", "", "''"},
+		{"no special chars", "plaintext", "plaintext"},
+		{"spaces and quotes", "go dev's", "go%20dev%27s"},
+		{"cursor padding", "abc==", "abc%3D%3D"},
+		{"non-ascii", "zürich", "z%C3%BCrich"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Escape(tt.in)
+			if got != tt.want {
+				t.Errorf("Escape(%q)\n  got:  %q\n  want: %q", tt.in, got, tt.want)
+			}
+			back, err := Unescape(got)
+			if err != nil || back != tt.in {
+				t.Errorf("Unescape(%q) = %q, %v; want %q", got, back, err, tt.in)
+			}
+		})
+	}
+}
+
+type param struct {
+	Key   string   `restli:"key"`
+	Value []string `restli:"value"`
+}
+
+type query struct {
+	Keywords string  `restli:"keywords"`
+	Params   []param `restli:"queryParameters"`
+	Filters  bool    `restli:"includeFiltersInResponse"`
+}
+
+type vars struct {
+	Start  int    `restli:"start"`
+	Query  query  `restli:"query"`
+	Cursor string `restli:"nextCursor,omitempty"`
+	Skip   string `restli:"-"`
+}
+
+func TestMarshal_Struct(t *testing.T) {
+	v := vars{
+		Start: 10,
+		Query: query{
+			Keywords: "go developer",
+			Params:   []param{{Key: "resultType", Value: []string{"PEOPLE"}}},
+		},
+		Skip: "ignored",
+	}
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "(start:10,query:(keywords:go%20developer,queryParameters:List((key:resultType,value:List(PEOPLE))),includeFiltersInResponse:false))"
+	if got != want {
+		t.Errorf("Marshal\n  got:  %s\n  want: %s", got, want)
+	}
+}
+
+func TestMarshal_MapsAndScalars(t *testing.T) {
+	got, err := Marshal(map[string]any{
+		"urn":   "urn:li:fsd_profile:AAA",
+		"count": 20,
+		"ratio": 0.5,
+		"empty": "",
+		"list":  []any{},
+		"ptr":   &param{Key: "k"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "(count:20,empty:'',list:List(),ptr:(key:k,value:List()),ratio:0.5,urn:urn%3Ali%3Afsd_profile%3AAAA)"
+	if got != want {
+		t.Errorf("Marshal\n  got:  %s\n  want: %s", got, want)
+	}
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	for _, v := range []any{nil, map[int]string{1: "x"}, func() {}, (*vars)(nil)} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%T) succeeded, want error", v)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := vars{
+		Start: 3,
+		Query: query{
+			Keywords: "a,b:(c) d's",
+			Params:   []param{{Key: "x", Value: []string{"1", "2"}}, {Key: "y"}},
+			Filters:  true,
+		},
+		Cursor: "abc==",
+	}
+	s, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out vars
+	if err := Unmarshal(s, &out); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", s, err)
+	}
+	in.Query.Params[1].Value = []string{} // List() decodes to an empty, non-nil slice
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip\n  in:  %+v\n  out: %+v", in, out)
+	}
+}
+
+func TestUnmarshal_Generic(t *testing.T) {
+	var v any
+	err := Unmarshal("(query:(predicateUnions:List((conversationCategoryPredicate:(category:INBOX)))),count:20,mailboxUrn:urn%3Ali%3Afsd_profile%3AAAA,empty:'')", &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"query": map[string]any{
+			"predicateUnions": []any{
+				map[string]any{"conversationCategoryPredicate": map[string]any{"category": "INBOX"}},
+			},
+		},
+		"count":      "20",
+		"mailboxUrn": "urn:li:fsd_profile:AAA",
+		"empty":      "",
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("got %#v", v)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		in      string
+		into    any
+		wantErr string
+	}{
+		{"(a:1", new(any), "unterminated record"},
+		{"List(1,2", new(any), "unterminated list"},
+		{"(a:)", new(any), "expected a value"},
+		{"(a:1)x", new(any), "after value"},
+		{"(a%zz:1)", new(any), "bad escape"},
+		{"(start:abc)", new(vars), "not an integer"},
+		{"List(1)", new(vars), "cannot decode list"},
+	}
+	for _, tt := range tests {
+		err := Unmarshal(tt.in, tt.into)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Unmarshal(%q) err = %v, want %q", tt.in, err, tt.wantErr)
+		}
+	}
+	if err := Unmarshal("x", vars{}); err == nil {
+		t.Error("Unmarshal into non-pointer succeeded")
+	}
+}
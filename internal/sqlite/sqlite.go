@@ -0,0 +1,584 @@
+// Package sqlite reads rows from SQLite 3 database files without cgo or a
+// SQL engine. It exists to pull cookies out of browser profiles, so it
+// only does what that needs: walk a table's b-tree and decode its records,
+// including overflow pages and frames still in a write-ahead log
+// (browsers keep their cookie databases in WAL mode, and recent writes
+// often haven't been checkpointed yet).
+//
+// There is no query language and no index use; Scan visits every row of
+// a table. Databases in UTF-16 are rejected.
+//
+// The file format is described at https://www.sqlite.org/fileformat.html.
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// ErrFormat means the file is not a database this package can read.
+var ErrFormat = errors.New("sqlite: malformed or unsupported database")
+
+const headerMagic = "SQLite format 3\x00"
+
+// IsDatabase reports whether b starts with the SQLite file header.
+func IsDatabase(b []byte) bool {
+	return bytes.HasPrefix(b, []byte(headerMagic))
+}
+
+// DB is a database read into memory.
+type DB struct {
+	data     []byte
+	pageSize int
+	usable   int
+	// wal maps page numbers to their newest committed image in the
+	// write-ahead log, if any.
+	wal    map[uint32][]byte
+	tables map[string]table
+}
+
+type table struct {
+	root    uint32
+	columns []string
+	// rowidCol is the index of the INTEGER PRIMARY KEY column, whose
+	// value is the rowid rather than stored in the record, or -1.
+	rowidCol int
+	// real marks columns with REAL affinity. SQLite may store their
+	// integral values as integers; they read back as float64.
+	real []bool
+}
+
+// Open reads the database at path, along with path+"-wal" if it exists.
+// The files are read once; later changes to them are not seen.
+func Open(path string) (*DB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	wal, err := os.ReadFile(path + "-wal")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return Load(data, wal)
+}
+
+// Load parses a database held in memory. wal is the content of its
+// write-ahead log, or nil.
+func Load(data, wal []byte) (*DB, error) {
+	if len(data) < 100 || !IsDatabase(data) {
+		return nil, fmt.Errorf("%w: bad header", ErrFormat)
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("%w: page size %d", ErrFormat, pageSize)
+	}
+	if enc := binary.BigEndian.Uint32(data[56:60]); enc > 1 {
+		return nil, fmt.Errorf("%w: text encoding %d is not UTF-8", ErrFormat, enc)
+	}
+	db := &DB{
+		data:     data,
+		pageSize: pageSize,
+		usable:   pageSize - int(data[20]),
+	}
+	if len(wal) > 0 {
+		frames, err := readWAL(wal, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		db.wal = frames
+	}
+	if err := db.readSchema(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// HasTable reports whether the database has a table called name.
+func (db *DB) HasTable(name string) bool {
+	_, ok := db.tables[strings.ToLower(name)]
+	return ok
+}
+
+// Row is one row of a table, keyed by column name. Values are int64,
+// float64, string, []byte or nil.
+type Row map[string]any
+
+// String returns the column as text, or "" if it is NULL or not text.
+func (r Row) String(col string) string {
+	switch v := r[col].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// Int returns the column as an integer, or 0 if it is NULL or not a number.
+func (r Row) Int(col string) int64 {
+	switch v := r[col].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// Scan calls fn with every row of the named table, in rowid order, and
+// stops at the first error fn returns.
+func (db *DB) Scan(name string, fn func(Row) error) error {
+	t, ok := db.tables[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("sqlite: no such table: %s", name)
+	}
+	return db.walk(t.root, 0, func(rowid int64, rec []any) error {
+		row := make(Row, len(t.columns))
+		for i, col := range t.columns {
+			switch {
+			case i == t.rowidCol:
+				row[col] = rowid
+			case i < len(rec):
+				row[col] = rec[i]
+				if n, ok := rec[i].(int64); ok && t.real[i] {
+					row[col] = float64(n)
+				}
+			default:
+				// Added by ALTER TABLE after this row was written.
+				row[col] = nil
+			}
+		}
+		return fn(row)
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Schema
+// ---------------------------------------------------------------------------
+
+// readSchema loads table definitions from sqlite_schema, the table rooted
+// at page 1.
+func (db *DB) readSchema() error {
+	db.tables = make(map[string]table)
+	return db.walk(1, 0, func(_ int64, rec []any) error {
+		if len(rec) < 5 {
+			return fmt.Errorf("%w: short schema record", ErrFormat)
+		}
+		typ, _ := rec[0].(string)
+		name, _ := rec[1].(string)
+		root, _ := rec[3].(int64)
+		sql, _ := rec[4].(string)
+		if typ != "table" || root <= 0 {
+			return nil
+		}
+		t := parseTable(sql)
+		t.root = uint32(root)
+		db.tables[strings.ToLower(name)] = t
+		return nil
+	})
+}
+
+// parseTable pulls the columns out of a CREATE TABLE statement. It
+// understands just enough SQL for that: the parenthesized definition list,
+// quoted identifiers, declared types and table constraints.
+func parseTable(sql string) table {
+	t := table{rowidCol: -1}
+	open := strings.IndexByte(sql, '(')
+	closing := strings.LastIndexByte(sql, ')')
+	if open < 0 || closing < open {
+		return t
+	}
+	for _, def := range splitTopLevel(sql[open+1 : closing]) {
+		def = strings.TrimSpace(def)
+		name, rest := identifier(def)
+		if quoted := len(def) > 0 && strings.ContainsRune("\"`[", rune(def[0])); !quoted {
+			switch strings.ToUpper(name) {
+			case "", "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+				continue
+			}
+		}
+		rest = strings.ToUpper(strings.Join(strings.Fields(rest), " "))
+		if strings.HasPrefix(rest, "INTEGER PRIMARY KEY") {
+			t.rowidCol = len(t.columns)
+		}
+		t.columns = append(t.columns, name)
+		t.real = append(t.real, realAffinity(rest))
+	}
+	return t
+}
+
+// realAffinity applies SQLite's affinity rules to a column definition
+// (upper-cased, without the name) and reports whether it is REAL.
+func realAffinity(def string) bool {
+	typ := def
+	for _, kw := range []string{" CONSTRAINT", " PRIMARY", " NOT", " NULL", " UNIQUE", " CHECK", " DEFAULT", " COLLATE", " REFERENCES", " GENERATED", " AS"} {
+		if i := strings.Index(" "+typ, kw); i >= 0 {
+			typ = typ[:max(i-1, 0)]
+		}
+	}
+	if strings.Contains(typ, "INT") || strings.Contains(typ, "CHAR") || strings.Contains(typ, "CLOB") || strings.Contains(typ, "TEXT") {
+		return false
+	}
+	return strings.Contains(typ, "REAL") || strings.Contains(typ, "FLOA") || strings.Contains(typ, "DOUB")
+}
+
+// splitTopLevel splits s at commas outside parentheses and quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// identifier splits the leading, possibly quoted, identifier off def.
+func identifier(def string) (name, rest string) {
+	if def == "" {
+		return "", ""
+	}
+	if end, ok := map[byte]byte{'"': '"', '`': '`', '[': ']'}[def[0]]; ok {
+		if i := strings.IndexByte(def[1:], end); i >= 0 {
+			return def[1 : i+1], def[i+2:]
+		}
+	}
+	if i := strings.IndexAny(def, " \t\r\n"); i >= 0 {
+		return def[:i], def[i:]
+	}
+	return def, ""
+}
+
+// ---------------------------------------------------------------------------
+// B-trees
+// ---------------------------------------------------------------------------
+
+const (
+	pageTableInterior = 0x05
+	pageTableLeaf     = 0x0d
+
+	// maxDepth bounds the walk so a corrupt file with a page cycle fails
+	// instead of recursing forever.
+	maxDepth = 64
+)
+
+// page returns page n (1-based), preferring its image in the WAL.
+func (db *DB) page(n uint32) ([]byte, error) {
+	if p, ok := db.wal[n]; ok {
+		return p, nil
+	}
+	off := int64(n-1) * int64(db.pageSize)
+	if n == 0 || off+int64(db.pageSize) > int64(len(db.data)) {
+		return nil, fmt.Errorf("%w: page %d out of range", ErrFormat, n)
+	}
+	return db.data[off : off+int64(db.pageSize)], nil
+}
+
+// walk visits the records of the table b-tree rooted at page n.
+func (db *DB) walk(n uint32, depth int, fn func(rowid int64, rec []any) error) error {
+	if depth > maxDepth {
+		return fmt.Errorf("%w: b-tree too deep", ErrFormat)
+	}
+	p, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if n == 1 {
+		hdr = 100 // the file header comes first
+	}
+	if len(p) < hdr+12 {
+		return fmt.Errorf("%w: short page %d", ErrFormat, n)
+	}
+	kind := p[hdr]
+	cells := int(binary.BigEndian.Uint16(p[hdr+3:]))
+	ptrs := hdr + 8
+	if kind == pageTableInterior {
+		ptrs = hdr + 12
+	}
+	if ptrs+2*cells > len(p) {
+		return fmt.Errorf("%w: page %d cell count %d", ErrFormat, n, cells)
+	}
+
+	switch kind {
+	case pageTableInterior:
+		for i := 0; i < cells; i++ {
+			off := int(binary.BigEndian.Uint16(p[ptrs+2*i:]))
+			if off+4 > len(p) {
+				return fmt.Errorf("%w: page %d cell %d", ErrFormat, n, i)
+			}
+			if err := db.walk(binary.BigEndian.Uint32(p[off:]), depth+1, fn); err != nil {
+				return err
+			}
+		}
+		return db.walk(binary.BigEndian.Uint32(p[hdr+8:]), depth+1, fn)
+
+	case pageTableLeaf:
+		for i := 0; i < cells; i++ {
+			off := int(binary.BigEndian.Uint16(p[ptrs+2*i:]))
+			rowid, payload, err := db.leafCell(p, off)
+			if err != nil {
+				return fmt.Errorf("page %d cell %d: %w", n, i, err)
+			}
+			rec, err := decodeRecord(payload)
+			if err != nil {
+				return fmt.Errorf("page %d cell %d: %w", n, i, err)
+			}
+			if err := fn(rowid, rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: page %d has type %#x, want a table b-tree page", ErrFormat, n, kind)
+}
+
+// leafCell reads the rowid and full payload of the table leaf cell at off,
+// following overflow pages.
+func (db *DB) leafCell(p []byte, off int) (int64, []byte, error) {
+	if off >= len(p) {
+		return 0, nil, ErrFormat
+	}
+	size, k := uvarint(p[off:])
+	if k == 0 {
+		return 0, nil, ErrFormat
+	}
+	off += k
+	rowid, k := uvarint(p[off:])
+	if k == 0 {
+		return 0, nil, ErrFormat
+	}
+	off += k
+
+	// A payload can't be bigger than the database holding it.
+	if size > uint64(len(db.data)+len(db.wal)*db.pageSize) {
+		return 0, nil, fmt.Errorf("%w: payload of %d bytes", ErrFormat, size)
+	}
+	total := int(size)
+	local := db.localPayload(total)
+	if off+local > len(p) {
+		return 0, nil, ErrFormat
+	}
+	payload := make([]byte, 0, total)
+	payload = append(payload, p[off:off+local]...)
+	if local == total {
+		return int64(rowid), payload, nil
+	}
+
+	if off+local+4 > len(p) {
+		return 0, nil, ErrFormat
+	}
+	next := binary.BigEndian.Uint32(p[off+local:])
+	for hops := 0; len(payload) < total; hops++ {
+		if next == 0 || hops > len(db.data)/db.pageSize+len(db.wal) {
+			return 0, nil, fmt.Errorf("%w: broken overflow chain", ErrFormat)
+		}
+		op, err := db.page(next)
+		if err != nil {
+			return 0, nil, err
+		}
+		n := min(total-len(payload), db.usable-4)
+		payload = append(payload, op[4:4+n]...)
+		next = binary.BigEndian.Uint32(op)
+	}
+	return int64(rowid), payload, nil
+}
+
+// localPayload is how much of a table leaf payload of size total is kept
+// on the b-tree page itself; the rest spills to overflow pages.
+func (db *DB) localPayload(total int) int {
+	u := db.usable
+	x := u - 35
+	if total <= x {
+		return total
+	}
+	m := (u-12)*32/255 - 23
+	k := m + (total-m)%(u-4)
+	if k <= x {
+		return k
+	}
+	return m
+}
+
+// ---------------------------------------------------------------------------
+// Records
+// ---------------------------------------------------------------------------
+
+// decodeRecord splits a record into its column values.
+func decodeRecord(b []byte) ([]any, error) {
+	hdrLen, k := uvarint(b)
+	if k == 0 || int(hdrLen) > len(b) || int(hdrLen) < k {
+		return nil, fmt.Errorf("%w: bad record header", ErrFormat)
+	}
+	types := b[k:hdrLen]
+	body := b[hdrLen:]
+
+	var vals []any
+	for len(types) > 0 {
+		st, k := uvarint(types)
+		if k == 0 {
+			return nil, fmt.Errorf("%w: bad serial type", ErrFormat)
+		}
+		types = types[k:]
+		n := serialSize(st)
+		if n > len(body) {
+			return nil, fmt.Errorf("%w: record shorter than its header", ErrFormat)
+		}
+		vals = append(vals, serialValue(st, body[:n]))
+		body = body[n:]
+	}
+	return vals, nil
+}
+
+func serialSize(st uint64) int {
+	switch {
+	case st <= 4:
+		return [...]int{0, 1, 2, 3, 4}[st]
+	case st == 5:
+		return 6
+	case st == 6, st == 7:
+		return 8
+	case st >= 12:
+		return int((st - 12) / 2)
+	}
+	return 0
+}
+
+func serialValue(st uint64, b []byte) any {
+	switch {
+	case st == 0:
+		return nil
+	case st >= 1 && st <= 6:
+		// Big-endian two's complement of 1, 2, 3, 4, 6 or 8 bytes.
+		v := int64(int8(b[0]))
+		for _, c := range b[1:] {
+			v = v<<8 | int64(c)
+		}
+		return v
+	case st == 7:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	case st == 8:
+		return int64(0)
+	case st == 9:
+		return int64(1)
+	case st >= 12 && st%2 == 0:
+		return append([]byte(nil), b...)
+	case st >= 13:
+		return string(b)
+	}
+	return nil // 10 and 11 are reserved
+}
+
+// uvarint decodes SQLite's big-endian varint, returning the value and its
+// length, or 0 length if b is too short.
+func uvarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
+
+// ---------------------------------------------------------------------------
+// Write-ahead log
+// ---------------------------------------------------------------------------
+
+const (
+	walHeaderSize      = 32
+	walFrameHeaderSize = 24
+	walMagicLE         = 0x377f0682
+	walMagicBE         = 0x377f0683
+)
+
+// readWAL returns the page images of every transaction committed to the
+// log. Frames after the last commit, or whose salt or checksum doesn't
+// match (left over from before the log was restarted), are ignored.
+func readWAL(b []byte, pageSize int) (map[uint32][]byte, error) {
+	if len(b) < walHeaderSize {
+		return nil, nil // an empty or truncated log has nothing committed
+	}
+	var order binary.ByteOrder
+	switch binary.BigEndian.Uint32(b) {
+	case walMagicLE:
+		order = binary.LittleEndian
+	case walMagicBE:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("%w: bad WAL header", ErrFormat)
+	}
+	if int(binary.BigEndian.Uint32(b[8:])) != pageSize {
+		return nil, fmt.Errorf("%w: WAL page size differs from the database's", ErrFormat)
+	}
+	s0, s1 := walChecksum(order, b[:24], 0, 0)
+	if s0 != binary.BigEndian.Uint32(b[24:]) || s1 != binary.BigEndian.Uint32(b[28:]) {
+		return nil, nil
+	}
+	salt := b[16:24]
+
+	committed := make(map[uint32][]byte)
+	pending := make(map[uint32][]byte)
+	for off := walHeaderSize; off+walFrameHeaderSize+pageSize <= len(b); off += walFrameHeaderSize + pageSize {
+		fh := b[off : off+walFrameHeaderSize]
+		data := b[off+walFrameHeaderSize : off+walFrameHeaderSize+pageSize]
+		if !bytes.Equal(fh[8:16], salt) {
+			break
+		}
+		s0, s1 = walChecksum(order, fh[:8], s0, s1)
+		s0, s1 = walChecksum(order, data, s0, s1)
+		if s0 != binary.BigEndian.Uint32(fh[16:]) || s1 != binary.BigEndian.Uint32(fh[20:]) {
+			break
+		}
+		pending[binary.BigEndian.Uint32(fh)] = data
+		if binary.BigEndian.Uint32(fh[4:]) != 0 { // commit frame
+			for n, p := range pending {
+				committed[n] = p
+			}
+			clear(pending)
+		}
+	}
+	return committed, nil
+}
+
+// walChecksum continues the WAL's running checksum over b, whose length
+// is a multiple of 8.
+func walChecksum(order binary.ByteOrder, b []byte, s0, s1 uint32) (uint32, uint32) {
+	for i := 0; i+8 <= len(b); i += 8 {
+		s0 += order.Uint32(b[i:]) + s1
+		s1 += order.Uint32(b[i+4:]) + s0
+	}
+	return s0, s1
+}
@@ -0,0 +1,205 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testdata/basic.sqlite and updated.sqlite are written by the sqlite3 CLI
+// from the .sql files beside them.
+
+func scanAll(t *testing.T, db *DB, table string) map[int64]Row {
+	t.Helper()
+	rows := make(map[int64]Row)
+	var last int64 = -1
+	err := db.Scan(table, func(r Row) error {
+		id := r.Int("id")
+		if id <= last {
+			t.Errorf("rows out of rowid order: %d after %d", id, last)
+		}
+		last = id
+		rows[id] = r
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan(%s): %v", table, err)
+	}
+	return rows
+}
+
+func TestScan(t *testing.T) {
+	db, err := Open(filepath.Join("testdata", "basic.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !db.HasTable("items") || !db.HasTable("OTHER") || db.HasTable("items_n") {
+		t.Error("HasTable: want items and other, not the index")
+	}
+
+	rows := scanAll(t, db, "items")
+	if len(rows) != 203 {
+		t.Fatalf("got %d rows, want 203", len(rows))
+	}
+	for i := int64(1); i <= 200; i++ {
+		r := rows[i]
+		wantN := (i - 100) * (1 << (i % 48))
+		if r.String("name") != fmt.Sprintf("item-%03d", i) || r.Int("n") != wantN || r["f"] != float64(i)*1.5 {
+			t.Fatalf("row %d = %v", i, r)
+		}
+		if !bytes.Equal(r["b"].([]byte), []byte{0x00, 0xff}) || r.String("quoted, col") != "q"+strconv.FormatInt(i, 10) {
+			t.Fatalf("row %d = %v", i, r)
+		}
+		if r["added"] != nil {
+			t.Fatalf("row %d: column added later = %v, want nil", i, r["added"])
+		}
+	}
+
+	long := rows[1000].String("name")
+	if len(long) != 3005 || long != "long-"+strings.Repeat("ab", 1500) {
+		t.Errorf("overflowing row: len %d, prefix %q", len(long), long[:min(20, len(long))])
+	}
+	if r := rows[1001]; r["n"] != nil || r["b"] != nil {
+		t.Errorf("NULL columns = %v", r)
+	}
+	if got := rows[1002].String("added"); got != "yes" {
+		t.Errorf("added = %q, want yes", got)
+	}
+
+	if err := db.Scan("missing", func(Row) error { return nil }); err == nil {
+		t.Error("Scan of a missing table: no error")
+	}
+	stop := errors.New("stop")
+	n := 0
+	if err := db.Scan("items", func(Row) error { n++; return stop }); !errors.Is(err, stop) || n != 1 {
+		t.Errorf("Scan did not stop at fn's error: err = %v after %d rows", err, n)
+	}
+}
+
+func TestLoad_WAL(t *testing.T) {
+	before, err := os.ReadFile(filepath.Join("testdata", "basic.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.ReadFile(filepath.Join("testdata", "updated.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const pageSize = 512
+	var changed []uint32
+	for n := 0; n*pageSize < len(after); n++ {
+		if !bytes.Equal(before[n*pageSize:(n+1)*pageSize], after[n*pageSize:(n+1)*pageSize]) {
+			changed = append(changed, uint32(n+1))
+		}
+	}
+
+	w := newTestWAL(pageSize)
+	for i, n := range changed {
+		w.frame(n, after[(n-1)*pageSize:n*pageSize], i == len(changed)-1)
+	}
+	// An uncommitted transaction after the last commit must be ignored.
+	w.frame(changed[0], make([]byte, pageSize), false)
+
+	db, err := Load(before, w.buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scanAll(t, db, "items")[150].String("quoted, col"); got != "changed" {
+		t.Errorf("items[150] = %q, want the WAL's value", got)
+	}
+	var v string
+	_ = db.Scan("other", func(r Row) error { v = r.String("v"); return nil })
+	if v != "c" {
+		t.Errorf("other.v = %q, want c", v)
+	}
+
+	// A log from before a checkpoint reset has a different salt and
+	// checksum chain; nothing in it applies.
+	stale := w.buf.Bytes()
+	stale[16] ^= 0xff
+	db, err = Load(before, stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scanAll(t, db, "items")[150].String("quoted, col"); got != "q150" {
+		t.Errorf("items[150] with a stale WAL = %q, want q150", got)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	if _, err := Load([]byte("not a database at all, just some text that is long enough to pass the length check of one hundred bytes"), nil); !errors.Is(err, ErrFormat) {
+		t.Errorf("text: err = %v, want ErrFormat", err)
+	}
+	b, err := os.ReadFile(filepath.Join("testdata", "basic.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := Load(b[:1024], nil)
+	if err != nil {
+		t.Fatal(err) // the schema is on page 1
+	}
+	if err := db.Scan("items", func(Row) error { return nil }); !errors.Is(err, ErrFormat) {
+		t.Errorf("truncated: err = %v, want ErrFormat", err)
+	}
+
+	// A cell claiming a payload larger than the file, here 2^64-1 bytes.
+	cell := append(bytes.Repeat([]byte{0xff}, 9), 0x01)
+	if _, _, err := db.leafCell(cell, 0); !errors.Is(err, ErrFormat) {
+		t.Errorf("oversized payload: err = %v, want ErrFormat", err)
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	tbl := parseTable("CREATE TABLE t (a INTEGER PRIMARY KEY, [b c] TEXT DEFAULT (x, y), `d` REAL NOT NULL, e DOUBLE PRECISION, f, PRIMARY KEY (a), CHECK (a > 0))")
+	if strings.Join(tbl.columns, "|") != "a|b c|d|e|f" || tbl.rowidCol != 0 {
+		t.Errorf("columns %q, rowid %d", tbl.columns, tbl.rowidCol)
+	}
+	if want := []bool{false, false, true, true, false}; fmt.Sprint(tbl.real) != fmt.Sprint(want) {
+		t.Errorf("real = %v, want %v", tbl.real, want)
+	}
+	if tbl := parseTable("CREATE TABLE t (a INTEGER NOT NULL, b)"); tbl.rowidCol != -1 {
+		t.Errorf("rowid = %d, want -1", tbl.rowidCol)
+	}
+}
+
+// testWAL writes a write-ahead log the way SQLite does.
+type testWAL struct {
+	buf    bytes.Buffer
+	s0, s1 uint32
+	salt   [8]byte
+}
+
+func newTestWAL(pageSize int) *testWAL {
+	w := &testWAL{salt: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}
+	hdr := make([]byte, walHeaderSize)
+	binary.BigEndian.PutUint32(hdr, walMagicBE)
+	binary.BigEndian.PutUint32(hdr[4:], 3007000)
+	binary.BigEndian.PutUint32(hdr[8:], uint32(pageSize))
+	copy(hdr[16:], w.salt[:])
+	w.s0, w.s1 = walChecksum(binary.BigEndian, hdr[:24], 0, 0)
+	binary.BigEndian.PutUint32(hdr[24:], w.s0)
+	binary.BigEndian.PutUint32(hdr[28:], w.s1)
+	w.buf.Write(hdr)
+	return w
+}
+
+func (w *testWAL) frame(page uint32, data []byte, commit bool) {
+	fh := make([]byte, walFrameHeaderSize)
+	binary.BigEndian.PutUint32(fh, page)
+	if commit {
+		binary.BigEndian.PutUint32(fh[4:], 44)
+	}
+	copy(fh[8:], w.salt[:])
+	w.s0, w.s1 = walChecksum(binary.BigEndian, fh[:8], w.s0, w.s1)
+	w.s0, w.s1 = walChecksum(binary.BigEndian, data, w.s0, w.s1)
+	binary.BigEndian.PutUint32(fh[16:], w.s0)
+	binary.BigEndian.PutUint32(fh[20:], w.s1)
+	w.buf.Write(fh)
+	w.buf.Write(data)
+}
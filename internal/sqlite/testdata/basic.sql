@@ -0,0 +1,23 @@
+-- Regenerate with: rm -f basic.sqlite && sqlite3 basic.sqlite < basic.sql
+-- Small pages so the table needs interior pages and overflow chains.
+PRAGMA page_size = 512;
+PRAGMA journal_mode = DELETE;
+CREATE TABLE items (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL DEFAULT '',
+  n INTEGER,
+  f REAL,
+  b BLOB,
+  "quoted, col" TEXT,
+  CONSTRAINT items_name UNIQUE (name)
+);
+WITH RECURSIVE seq(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM seq WHERE i < 200)
+INSERT INTO items (id, name, n, f, b, "quoted, col")
+SELECT i, printf('item-%03d', i), (i - 100) * (1 << (i % 48)), i * 1.5, x'00ff', 'q' || i FROM seq;
+INSERT INTO items (id, name, n) VALUES (1000, 'long-' || replace(hex(zeroblob(1500)), '00', 'ab'), 0);
+INSERT INTO items (id, name) VALUES (1001, 'nulls');
+ALTER TABLE items ADD COLUMN added TEXT;
+INSERT INTO items (id, name, added) VALUES (1002, 'after-alter', 'yes');
+CREATE INDEX items_n ON items (n);
+CREATE TABLE other (k TEXT, v TEXT);
+INSERT INTO other VALUES ('a', 'b');
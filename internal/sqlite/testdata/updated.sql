@@ -0,0 +1,4 @@
+-- basic.sqlite after one more transaction, for building a WAL in tests.
+-- Regenerate with: cp basic.sqlite updated.sqlite && sqlite3 updated.sqlite < updated.sql
+UPDATE other SET v = 'c' WHERE k = 'a';
+UPDATE items SET "quoted, col" = 'changed' WHERE id = 150;
@@ -0,0 +1,379 @@
+// Package urn parses, validates and builds the URNs Voyager uses to name
+// entities:
+//
+//	urn:li:fsd_profile:ACoAAB1xyz
+//	urn:li:msg_conversation:(urn:li:fsd_profile:ACoAAB1xyz,2-MjkzM2E=)
+//
+// A URN is "urn:", a namespace, an entity type and an id. The id is either
+// a plain string or a tuple of comma-separated parts in parentheses, and a
+// part may itself be a URN (with its own tuples). Formatting is the
+// inverse of parsing, so String round-trips what Parse accepted. URNs are
+// written raw here; escape them with restli.Escape to embed them in tuple
+// syntax query parameters.
+package urn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Namespace is the namespace of every Bragnet URN.
+const Namespace = "li"
+
+// Type is an entity type, the third component of a URN.
+type Type string
+
+const (
+	TypeMember        Type = "member"
+	TypeProfile       Type = "fsd_profile"
+	TypeMiniProfile   Type = "fs_miniProfile"
+	TypeFollowingInfo Type = "fs_followingInfo"
+	TypeConversation  Type = "msg_conversation"
+	TypeMessage       Type = "msg_message"
+	TypeParticipant   Type = "msg_participant"
+	TypeActivity      Type = "activity"
+	TypeUGCPost       Type = "ugcPost"
+	TypeCompany       Type = "company"
+	TypeJobPosting    Type = "jobPosting"
+)
+
+// ErrInvalid is wrapped by every parse and validation error.
+var ErrInvalid = errors.New("invalid urn")
+
+// URN is a parsed URN. Parts holds the id: one element for a plain id, or
+// the tuple elements, which may be URNs themselves (see Part).
+type URN struct {
+	Namespace string
+	Type      Type
+	Parts     []string
+}
+
+// Parse parses s without checking its type-specific shape; see ParseAs.
+func Parse(s string) (URN, error) {
+	rest, ok := strings.CutPrefix(s, "urn:")
+	if !ok {
+		return URN{}, fmt.Errorf("%w %q: missing urn: prefix", ErrInvalid, s)
+	}
+	ns, rest, ok := strings.Cut(rest, ":")
+	if !ok || ns == "" {
+		return URN{}, fmt.Errorf("%w %q: missing namespace", ErrInvalid, s)
+	}
+	typ, id, ok := strings.Cut(rest, ":")
+	if !ok || typ == "" || strings.ContainsAny(typ, "(),") {
+		return URN{}, fmt.Errorf("%w %q: missing entity type", ErrInvalid, s)
+	}
+	parts, err := splitID(id)
+	if err != nil {
+		return URN{}, fmt.Errorf("%w %q: %s", ErrInvalid, s, err)
+	}
+	return URN{Namespace: ns, Type: Type(typ), Parts: parts}, nil
+}
+
+// ParseAs parses s and checks that it is a well-formed URN of type t.
+func ParseAs(s string, t Type) (URN, error) {
+	u, err := Parse(s)
+	if err != nil {
+		return URN{}, err
+	}
+	if u.Type != t {
+		return URN{}, fmt.Errorf("%w %q: want a %s URN", ErrInvalid, s, t)
+	}
+	if err := u.Validate(); err != nil {
+		return URN{}, err
+	}
+	return u, nil
+}
+
+// splitID splits an id into its tuple parts, or returns it whole if it is
+// not a tuple. Commas only separate parts at the top level of the tuple.
+func splitID(id string) ([]string, error) {
+	if id == "" {
+		return nil, errors.New("empty id")
+	}
+	if id[0] != '(' {
+		if err := checkBalanced(id); err != nil {
+			return nil, err
+		}
+		return []string{id}, nil
+	}
+	if id[len(id)-1] != ')' {
+		return nil, errors.New("unterminated tuple")
+	}
+	inner := id[1 : len(id)-1]
+	if err := checkBalanced(inner); err != nil {
+		return nil, err
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(inner); i++ {
+		switch inner[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, inner[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, inner[start:])
+	for _, p := range parts {
+		if p == "" {
+			return nil, errors.New("empty tuple part")
+		}
+	}
+	return parts, nil
+}
+
+func checkBalanced(s string) error {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return errors.New("unbalanced parentheses")
+			}
+		}
+	}
+	if depth != 0 {
+		return errors.New("unbalanced parentheses")
+	}
+	return nil
+}
+
+// String formats u. A URN with more than one part is written as a tuple.
+func (u URN) String() string {
+	if u.Type == "" {
+		return ""
+	}
+	ns := u.Namespace
+	if ns == "" {
+		ns = Namespace
+	}
+	return "urn:" + ns + ":" + string(u.Type) + ":" + u.ID()
+}
+
+// ID returns the id as written in the URN: the plain id, or the whole
+// tuple including its parentheses.
+func (u URN) ID() string {
+	if len(u.Parts) == 1 {
+		return u.Parts[0]
+	}
+	return "(" + strings.Join(u.Parts, ",") + ")"
+}
+
+// IsZero reports whether u is the zero URN.
+func (u URN) IsZero() bool {
+	return u.Type == "" && len(u.Parts) == 0
+}
+
+// Part parses part i of u as a URN.
+func (u URN) Part(i int) (URN, error) {
+	if i < 0 || i >= len(u.Parts) {
+		return URN{}, fmt.Errorf("%w %q: no part %d", ErrInvalid, u, i)
+	}
+	return Parse(u.Parts[i])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u URN) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *URN) UnmarshalText(b []byte) error {
+	p, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*u = p
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Validation
+// ---------------------------------------------------------------------------
+
+// idKind constrains a plain id.
+type idKind int
+
+const (
+	anyID     idKind = iota // any id without tuple syntax
+	numericID               // decimal digits only
+)
+
+// shape describes the id of one entity type: either a single plain id of
+// kind id, or a tuple whose parts are URNs of the given types ("" for a
+// plain, non-URN part).
+type shape struct {
+	id    idKind
+	tuple []Type
+}
+
+var shapes = map[Type]shape{
+	TypeMember:        {id: anyID},
+	TypeProfile:       {id: anyID},
+	TypeMiniProfile:   {id: anyID},
+	TypeFollowingInfo: {id: anyID},
+	TypeConversation:  {tuple: []Type{TypeProfile, ""}},
+	TypeMessage:       {tuple: []Type{TypeProfile, ""}},
+	TypeParticipant:   {tuple: []Type{TypeProfile, TypeProfile}},
+	TypeActivity:      {id: numericID},
+	TypeUGCPost:       {id: numericID},
+	TypeCompany:       {id: numericID},
+	TypeJobPosting:    {id: numericID},
+}
+
+// Validate checks u against the shape of its type. Types this package
+// doesn't know only need a namespace and an id.
+func (u URN) Validate() error {
+	if u.Namespace == "" || u.Type == "" || len(u.Parts) == 0 {
+		return fmt.Errorf("%w %q: incomplete", ErrInvalid, u)
+	}
+	sh, ok := shapes[u.Type]
+	if !ok {
+		return nil
+	}
+	if sh.tuple == nil {
+		if len(u.Parts) != 1 || strings.ContainsAny(u.Parts[0], "(),:") {
+			return fmt.Errorf("%w %q: a %s URN has a plain id", ErrInvalid, u, u.Type)
+		}
+		if sh.id == numericID && !isNumeric(u.Parts[0]) {
+			return fmt.Errorf("%w %q: a %s id is numeric", ErrInvalid, u, u.Type)
+		}
+		return nil
+	}
+	if len(u.Parts) != len(sh.tuple) {
+		return fmt.Errorf("%w %q: a %s URN has %d parts", ErrInvalid, u, u.Type, len(sh.tuple))
+	}
+	for i, want := range sh.tuple {
+		if want == "" {
+			continue
+		}
+		if _, err := ParseAs(u.Parts[i], want); err != nil {
+			return fmt.Errorf("%q: part %d: %w", u, i, err)
+		}
+	}
+	return nil
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ---------------------------------------------------------------------------
+// Constructors
+// ---------------------------------------------------------------------------
+
+func simple(t Type, id string) URN {
+	return URN{Namespace: Namespace, Type: t, Parts: []string{id}}
+}
+
+// Member returns urn:li:member:<id>.
+func Member(id string) URN { return simple(TypeMember, id) }
+
+// Profile returns urn:li:fsd_profile:<id>.
+func Profile(id string) URN { return simple(TypeProfile, id) }
+
+// MiniProfile returns urn:li:fs_miniProfile:<id>.
+func MiniProfile(id string) URN { return simple(TypeMiniProfile, id) }
+
+// FollowingInfo returns urn:li:fs_followingInfo:<id>.
+func FollowingInfo(id string) URN { return simple(TypeFollowingInfo, id) }
+
+// Activity returns urn:li:activity:<id>.
+func Activity(id string) URN { return simple(TypeActivity, id) }
+
+// UGCPost returns urn:li:ugcPost:<id>.
+func UGCPost(id string) URN { return simple(TypeUGCPost, id) }
+
+// Company returns urn:li:company:<id>.
+func Company(id string) URN { return simple(TypeCompany, id) }
+
+// JobPosting returns urn:li:jobPosting:<id>.
+func JobPosting(id string) URN { return simple(TypeJobPosting, id) }
+
+// Conversation returns urn:li:msg_conversation:(<mailbox>,<thread>).
+func Conversation(mailbox URN, thread string) URN {
+	return URN{Namespace: Namespace, Type: TypeConversation, Parts: []string{mailbox.String(), thread}}
+}
+
+// Message returns urn:li:msg_message:(<mailbox>,<id>).
+func Message(mailbox URN, id string) URN {
+	return URN{Namespace: Namespace, Type: TypeMessage, Parts: []string{mailbox.String(), id}}
+}
+
+// ---------------------------------------------------------------------------
+// Conversions
+// ---------------------------------------------------------------------------
+
+// ToProfile returns the fsd_profile URN u refers to. fsd_profile and
+// fs_miniProfile URNs share their id; participant URNs carry the profile as
+// the last fsd_profile part (the member, after the mailbox owner's). Member
+// URNs only convert when their id is a profile id rather than a numeric
+// one, which needs a lookup.
+func ToProfile(u URN) (URN, error) {
+	switch u.Type {
+	case TypeProfile, TypeMiniProfile, TypeMember:
+		if err := u.Validate(); err != nil {
+			return URN{}, err
+		}
+		if u.Type == TypeMember && isNumeric(u.ID()) {
+			return URN{}, fmt.Errorf("%w %q: a numeric member id needs a profile lookup", ErrInvalid, u)
+		}
+		return Profile(u.ID()), nil
+	case TypeConversation, TypeMessage:
+		// Their only profile is the mailbox owner's; see Mailbox.
+		return URN{}, fmt.Errorf("%w %q: a %s names no member", ErrInvalid, u, u.Type)
+	}
+	for i := len(u.Parts) - 1; i >= 0; i-- {
+		p, err := Parse(u.Parts[i])
+		if err != nil {
+			continue
+		}
+		if p, err := ToProfile(p); err == nil {
+			return p, nil
+		}
+	}
+	return URN{}, fmt.Errorf("%w %q: no fsd_profile in a %s URN", ErrInvalid, u, u.Type)
+}
+
+// ToFollowingInfo returns the fs_followingInfo URN used to follow the
+// member named by a member, fsd_profile or fs_miniProfile URN.
+func ToFollowingInfo(u URN) (URN, error) {
+	switch u.Type {
+	case TypeMember, TypeProfile, TypeMiniProfile:
+		if err := u.Validate(); err != nil {
+			return URN{}, err
+		}
+		return FollowingInfo(u.ID()), nil
+	}
+	return URN{}, fmt.Errorf("%w %q: cannot follow a %s", ErrInvalid, u, u.Type)
+}
+
+// Mailbox returns the mailbox (the owner's fsd_profile) of a conversation,
+// message or participant URN.
+func Mailbox(u URN) (URN, error) {
+	switch u.Type {
+	case TypeConversation, TypeMessage, TypeParticipant:
+		if err := u.Validate(); err != nil {
+			return URN{}, err
+		}
+		return u.Part(0)
+	}
+	return URN{}, fmt.Errorf("%w %q: a %s has no mailbox", ErrInvalid, u, u.Type)
+}
@@ -0,0 +1,208 @@
+package urn
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in    string
+		typ   Type
+		parts []string
+	}{
+		{"urn:li:fsd_profile:ACoAAB1xyz", TypeProfile, []string{"ACoAAB1xyz"}},
+		{"urn:li:member:12345", TypeMember, []string{"12345"}},
+		{
+			"urn:li:msg_conversation:(urn:li:fsd_profile:AAA,2-MjkzM2E=)",
+			TypeConversation,
+			[]string{"urn:li:fsd_profile:AAA", "2-MjkzM2E="},
+		},
+		{
+			"urn:li:fs_update:(urn:li:activity:7001,MEMBER_SHARE,EMPTY,DEFAULT,false)",
+			"fs_update",
+			[]string{"urn:li:activity:7001", "MEMBER_SHARE", "EMPTY", "DEFAULT", "false"},
+		},
+		{
+			// Nested tuples only split at the top level.
+			"urn:li:fsd_x:(urn:li:msg_message:(urn:li:fsd_profile:AAA,m1),b)",
+			"fsd_x",
+			[]string{"urn:li:msg_message:(urn:li:fsd_profile:AAA,m1)", "b"},
+		},
+		{
+			// An untupled id may itself be a URN.
+			"urn:li:msg_messagingParticipant:urn:li:fsd_profile:AAA",
+			"msg_messagingParticipant",
+			[]string{"urn:li:fsd_profile:AAA"},
+		},
+	}
+	for _, tt := range tests {
+		u, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if u.Namespace != "li" || u.Type != tt.typ || !reflect.DeepEqual(u.Parts, tt.parts) {
+			t.Errorf("Parse(%q) = %+v", tt.in, u)
+		}
+		if got := u.String(); got != tt.in {
+			t.Errorf("String() = %q, want %q", got, tt.in)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"fsd_profile:AAA",
+		"urn:li",
+		"urn::fsd_profile:AAA",
+		"urn:li:fsd_profile:",
+		"urn:li:fsd_profile",
+		"urn:li:msg_conversation:(urn:li:fsd_profile:AAA,t1",
+		"urn:li:msg_conversation:(urn:li:fsd_profile:AAA,t1))",
+		"urn:li:msg_conversation:(urn:li:fsd_profile:AAA,)",
+		"urn:li:activity:1)",
+	} {
+		if _, err := Parse(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q): err = %v, want ErrInvalid", in, err)
+		}
+	}
+}
+
+func TestParseAs(t *testing.T) {
+	valid := []struct {
+		in  string
+		typ Type
+	}{
+		{"urn:li:fsd_profile:ACoAAB1xyz", TypeProfile},
+		{"urn:li:member:ACoAAB1xyz", TypeMember},
+		{"urn:li:activity:7123456789", TypeActivity},
+		{"urn:li:ugcPost:7123456789", TypeUGCPost},
+		{"urn:li:company:1441", TypeCompany},
+		{"urn:li:jobPosting:3912345678", TypeJobPosting},
+		{"urn:li:msg_conversation:(urn:li:fsd_profile:AAA,2-abc)", TypeConversation},
+		{"urn:li:msg_message:(urn:li:fsd_profile:AAA,2-xyz)", TypeMessage},
+		{"urn:li:msg_participant:(urn:li:fsd_profile:AAA,urn:li:fsd_profile:BBB)", TypeParticipant},
+	}
+	for _, tt := range valid {
+		if _, err := ParseAs(tt.in, tt.typ); err != nil {
+			t.Errorf("ParseAs(%q, %s): %v", tt.in, tt.typ, err)
+		}
+	}
+
+	invalid := []struct {
+		in  string
+		typ Type
+	}{
+		{"urn:li:member:123", TypeProfile},
+		{"urn:li:activity:abc", TypeActivity},
+		{"urn:li:company:(1,2)", TypeCompany},
+		{"urn:li:fsd_profile:(AAA,BBB)", TypeProfile},
+		{"urn:li:msg_conversation:thread1", TypeConversation},
+		{"urn:li:msg_conversation:(urn:li:member:1,thread1)", TypeConversation},
+		{"urn:li:msg_message:(urn:li:fsd_profile:AAA,b,c)", TypeMessage},
+	}
+	for _, tt := range invalid {
+		if _, err := ParseAs(tt.in, tt.typ); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ParseAs(%q, %s): err = %v, want ErrInvalid", tt.in, tt.typ, err)
+		}
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	mailbox := Profile("AAA")
+	tests := []struct {
+		got  URN
+		want string
+	}{
+		{Member("1"), "urn:li:member:1"},
+		{mailbox, "urn:li:fsd_profile:AAA"},
+		{MiniProfile("AAA"), "urn:li:fs_miniProfile:AAA"},
+		{FollowingInfo("AAA"), "urn:li:fs_followingInfo:AAA"},
+		{Activity("7"), "urn:li:activity:7"},
+		{UGCPost("7"), "urn:li:ugcPost:7"},
+		{Company("7"), "urn:li:company:7"},
+		{JobPosting("7"), "urn:li:jobPosting:7"},
+		{Conversation(mailbox, "2-abc"), "urn:li:msg_conversation:(urn:li:fsd_profile:AAA,2-abc)"},
+		{Message(mailbox, "2-xyz"), "urn:li:msg_message:(urn:li:fsd_profile:AAA,2-xyz)"},
+	}
+	for _, tt := range tests {
+		if got := tt.got.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+		if err := tt.got.Validate(); err != nil {
+			t.Errorf("%s: Validate: %v", tt.want, err)
+		}
+	}
+	if s := (URN{}).String(); s != "" || !(URN{}).IsZero() {
+		t.Errorf("zero URN = %q", s)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	conv := []struct {
+		fn   func(URN) (URN, error)
+		in   string
+		want string
+	}{
+		{ToProfile, "urn:li:fsd_profile:AAA", "urn:li:fsd_profile:AAA"},
+		{ToProfile, "urn:li:fs_miniProfile:AAA", "urn:li:fsd_profile:AAA"},
+		{ToProfile, "urn:li:member:ACoAAB", "urn:li:fsd_profile:ACoAAB"},
+		{ToProfile, "urn:li:msg_participant:(urn:li:fsd_profile:ME,urn:li:fsd_profile:BBB)", "urn:li:fsd_profile:BBB"},
+		{ToProfile, "urn:li:msg_messagingParticipant:urn:li:fsd_profile:BBB", "urn:li:fsd_profile:BBB"},
+		{ToFollowingInfo, "urn:li:member:ACoAAB", "urn:li:fs_followingInfo:ACoAAB"},
+		{ToFollowingInfo, "urn:li:fsd_profile:ACoAAB", "urn:li:fs_followingInfo:ACoAAB"},
+		{Mailbox, "urn:li:msg_conversation:(urn:li:fsd_profile:ME,2-abc)", "urn:li:fsd_profile:ME"},
+		{Mailbox, "urn:li:msg_message:(urn:li:fsd_profile:ME,2-xyz)", "urn:li:fsd_profile:ME"},
+	}
+	for _, tt := range conv {
+		u, err := Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tt.fn(u)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("convert %q = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	bad := []struct {
+		fn func(URN) (URN, error)
+		in string
+	}{
+		{ToProfile, "urn:li:member:12345"}, // numeric ids need a lookup
+		{ToProfile, "urn:li:msg_conversation:(urn:li:fsd_profile:ME,2-abc)"},
+		{ToProfile, "urn:li:company:1441"},
+		{ToFollowingInfo, "urn:li:company:1441"},
+		{Mailbox, "urn:li:fsd_profile:ME"},
+	}
+	for _, tt := range bad {
+		u, _ := Parse(tt.in)
+		if _, err := tt.fn(u); !errors.Is(err, ErrInvalid) {
+			t.Errorf("convert %q: err = %v, want ErrInvalid", tt.in, err)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	var v struct {
+		URN URN `json:"urn"`
+	}
+	in := `{"urn":"urn:li:msg_conversation:(urn:li:fsd_profile:AAA,2-abc)"}`
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.URN.Type != TypeConversation {
+		t.Errorf("Type = %q", v.URN.Type)
+	}
+	out, err := json.Marshal(v)
+	if err != nil || string(out) != in {
+		t.Errorf("Marshal = %s, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"urn":"nope"}`), &v); !errors.Is(err, ErrInvalid) {
+		t.Errorf("bad URN: err = %v", err)
+	}
+}